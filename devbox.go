@@ -9,9 +9,12 @@ import (
 	"io"
 
 	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envsnapshot"
 	"go.jetpack.io/devbox/internal/impl"
 	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/projects"
 	"go.jetpack.io/devbox/internal/services"
+	"go.jetpack.io/devbox/internal/trust"
 )
 
 // Devbox provides an isolated development environment.
@@ -21,6 +24,9 @@ type Devbox interface {
 	// Adding duplicate packages is a no-op.
 	Add(ctx context.Context, pkgs ...string) error
 	Config() *devconfig.Config
+	// EnvSnapshot returns the computed environment of the project, with the
+	// versions and store paths of its packages.
+	EnvSnapshot(ctx context.Context) (*envsnapshot.Snapshot, error)
 	ExportHook(shellName string) (string, error)
 	ProjectDir() string
 	// Generate creates the directory of Nix files and the Dockerfile that define
@@ -29,19 +35,26 @@ type Devbox interface {
 	GenerateDevcontainer(ctx context.Context, force bool) error
 	GenerateDockerfile(ctx context.Context, force bool) error
 	GenerateEnvrcFile(ctx context.Context, force bool) error
+	GenerateGithubActions(ctx context.Context, script string, force bool) error
+	GenerateGitlabCI(ctx context.Context, script string, force bool) error
+	GenerateNix(ctx context.Context, force bool) error
 	Info(ctx context.Context, pkg string, markdown bool) error
 	Install(ctx context.Context) error
 	IsEnvEnabled() bool
 	ListScripts() []string
+	// Outdated lists packages that have newer versions available.
+	Outdated(ctx context.Context) error
 	PrintEnv(ctx context.Context, includeHooks bool) (string, error)
 	PrintEnvVars(ctx context.Context) ([]string, error)
 	PrintGlobalList() error
-	Pull(ctx context.Context, overwrite bool, path string) error
+	Pull(ctx context.Context, opts devopt.PullboxOpts) error
 	Push(ctx context.Context, url string) error
 	// Remove removes Nix packages from the config so that it no longer exists in
 	// the devbox environment.
 	Remove(ctx context.Context, pkgs ...string) error
 	RestartServices(ctx context.Context, services ...string) error
+	// Rollback undoes the last successful change to the project's packages.
+	Rollback(ctx context.Context) error
 	RunScript(ctx context.Context, scriptName string, scriptArgs []string) error
 	Services() (services.Services, error)
 	// Shell generates the devbox environment and launches nix-shell as a child process.
@@ -49,9 +62,17 @@ type Devbox interface {
 	StartProcessManager(ctx context.Context, requestedServices []string, background bool, processComposeFileOrDir string) error
 	StartServices(ctx context.Context, services ...string) error
 	StopServices(ctx context.Context, allProjects bool, services ...string) error
+	// Sync merges the global config with the one stored at a remote, and
+	// updates both. It reports whether the local config changed.
+	Sync(ctx context.Context, opts devopt.SyncOpts) (bool, error)
 	ListServices(ctx context.Context) error
+	// Trust allows devbox to activate the project's current config.
+	Trust(ctx context.Context) error
+	TrustStatus() (trust.Status, error)
+	// Untrust revokes trust in the project.
+	Untrust(ctx context.Context) error
 
-	Update(ctx context.Context, pkgs ...string) error
+	Update(ctx context.Context, opts devopt.UpdateOpts) error
 }
 
 // Open opens a devbox by reading the config file in dir.
@@ -64,12 +85,65 @@ func InitConfig(dir string, writer io.Writer) (bool, error) {
 	return devconfig.Init(dir, writer)
 }
 
+// InitConfigWithFormat creates a default devbox config file in format, which is
+// json, yaml or toml, if one doesn't already exist.
+func InitConfigWithFormat(dir, format string, writer io.Writer) (bool, error) {
+	return devconfig.InitWithFormat(dir, format, writer)
+}
+
+// InitConfigWithPackages creates a devbox config file in format with packages,
+// if one doesn't already exist.
+func InitConfigWithPackages(ctx context.Context, dir, format string, packages []string) (bool, error) {
+	created, err := devconfig.InitWithPackages(dir, format, packages)
+	if !created || err != nil {
+		return created, err
+	}
+	// The plugins of the packages are trusted along with the config, so
+	// trust the project again now that it has them.
+	box, err := impl.Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+	if err != nil {
+		return true, err
+	}
+	return true, box.Trust(ctx)
+}
+
+// ValidateConfig checks the config of the project in dir, and returns the path
+// of the config file.
+func ValidateConfig(dir string) (string, error) {
+	return impl.ValidateConfig(dir)
+}
+
 func GlobalDataPath() (string, error) {
 	return impl.GlobalDataPath()
 }
 
-func PrintEnvrcContent(w io.Writer) error {
-	return impl.PrintEnvrcContent(w)
+// ActiveGlobalProfile returns the name of the active global profile.
+func ActiveGlobalProfile() (string, error) {
+	return impl.ActiveGlobalProfile()
+}
+
+// ListGlobalProfiles returns the names of the global profiles.
+func ListGlobalProfiles() ([]string, error) {
+	return impl.ListGlobalProfiles()
+}
+
+// CreateGlobalProfile creates an empty global profile.
+func CreateGlobalProfile(name string, writer io.Writer) error {
+	return impl.CreateGlobalProfile(name, writer)
+}
+
+// SwitchGlobalProfile makes name the active global profile.
+func SwitchGlobalProfile(name string) error {
+	return impl.SwitchGlobalProfile(name)
+}
+
+// DeleteGlobalProfile deletes a global profile that isn't active.
+func DeleteGlobalProfile(name string) error {
+	return impl.DeleteGlobalProfile(name)
+}
+
+func PrintEnvrcContent(w io.Writer, dir string) error {
+	return impl.PrintEnvrcContent(w, dir)
 }
 
 // ExportifySystemPathWithoutWrappers reads $PATH, removes `virtenv/.wrappers/bin` paths,
@@ -80,3 +154,27 @@ func PrintEnvrcContent(w io.Writer) error {
 func ExportifySystemPathWithoutWrappers() string {
 	return impl.ExportifySystemPathWithoutWrappers()
 }
+
+// GC removes old profile generations and the GC roots of deleted projects,
+// and optionally runs `nix store gc`.
+func GC(ctx context.Context, opts devopt.GCOpts, w io.Writer) error {
+	return impl.GC(ctx, opts, w)
+}
+
+// ListProjects returns the projects that devbox has opened, most recently
+// used first.
+func ListProjects() ([]*projects.Project, error) {
+	return impl.ListProjects()
+}
+
+// PruneProjects removes the projects that no longer exist from the list of
+// projects, and returns them.
+func PruneProjects() ([]*projects.Project, error) {
+	return impl.PruneProjects()
+}
+
+// FindProject returns the directory of the project with the given path or
+// name.
+func FindProject(name string) (string, error) {
+	return impl.FindProject(name)
+}
@@ -14,12 +14,12 @@ type configFlags struct {
 
 func (flags *configFlags) register(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(
-		&flags.path, "config", "c", "", "path to directory containing a devbox.json config file",
+		&flags.path, "config", "c", "", "path to directory containing a devbox config file",
 	)
 }
 
 func (flags *configFlags) registerPersistent(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVarP(
-		&flags.path, "config", "c", "", "path to directory containing a devbox.json config file",
+		&flags.path, "config", "c", "", "path to directory containing a devbox config file",
 	)
 }
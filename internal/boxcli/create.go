@@ -17,6 +17,7 @@ import (
 type createCmdFlags struct {
 	showAll  bool
 	template string
+	vars     map[string]string
 }
 
 func createCmd() *cobra.Command {
@@ -24,7 +25,15 @@ func createCmd() *cobra.Command {
 	command := &cobra.Command{
 		Use:   "create [dir] --template <template>",
 		Short: "Initialize a directory as a devbox project using a template",
-		Args:  cobra.MaximumNArgs(1),
+		Long: "Initialize a directory as a devbox project using a template.\n\n" +
+			"The template can be the name of a built-in template, a local directory, " +
+			"a .tar.gz file or URL, or a git repository URL. Use `<url>//<subdir>` " +
+			"to select a directory within a repository or tarball, and `?ref=<ref>` " +
+			"to select a git branch, tag, or commit. For example:\n\n" +
+			"  devbox create --template git@github.com:org/templates.git//go?ref=v1\n\n" +
+			"Templates may contain placeholders such as {{ devbox.project_name }}, " +
+			"which are filled in with --var or prompted for.",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if flags.template == "" {
 				fmt.Fprintf(
@@ -52,6 +61,10 @@ func createCmd() *cobra.Command {
 		&flags.showAll, "show-all", false,
 		"show all available templates",
 	)
+	command.Flags().StringToStringVar(
+		&flags.vars, "var", nil,
+		"set a template variable, e.g. --var port=8080",
+	)
 
 	return command
 }
@@ -60,10 +73,10 @@ func runCreateCmd(cmd *cobra.Command, args []string, flags *createCmdFlags) erro
 	path := pathArg(args)
 	if path == "" {
 		wd, _ := os.Getwd()
-		path = filepath.Join(wd, flags.template)
+		path = filepath.Join(wd, templates.DirName(flags.template))
 	}
 
-	err := templates.Init(cmd.ErrOrStderr(), flags.template, path)
+	err := templates.Init(cmd.Context(), cmd.ErrOrStderr(), flags.template, path, flags.vars)
 	if err != nil {
 		return err
 	}
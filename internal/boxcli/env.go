@@ -0,0 +1,113 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/envsnapshot"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+type envCmdFlags struct {
+	config configFlags
+	impure bool
+}
+
+func envCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "env",
+		Short: "Snapshot and compare devbox environments",
+	}
+	command.AddCommand(envSnapshotCmd())
+	command.AddCommand(envDiffCmd())
+	return command
+}
+
+func envSnapshotCmd() *cobra.Command {
+	flags := &envCmdFlags{}
+	command := &cobra.Command{
+		Use:   "snapshot [<file>]",
+		Short: "Write the computed environment to a file",
+		Long: "Write the computed devbox environment, with the versions and store paths " +
+			"of its packages, to a JSON file that `devbox env diff` can compare. " +
+			"If no file is given, the snapshot is printed. Variables of the current " +
+			"environment, which may be secrets, are only included with --impure.",
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: ensureNixInstalled,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot, err := currentEnvSnapshot(cmd, flags)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return snapshot.Write(cmd.OutOrStdout())
+			}
+			if err := snapshot.WriteFile(args[0]); err != nil {
+				return err
+			}
+			ux.Fsuccess(cmd.ErrOrStderr(), "Wrote environment snapshot to %s\n", args[0])
+			return nil
+		},
+	}
+	flags.register(command)
+	return command
+}
+
+func envDiffCmd() *cobra.Command {
+	flags := &envCmdFlags{}
+	command := &cobra.Command{
+		Use:   "diff <a> [<b>]",
+		Short: "Show the differences between two environment snapshots",
+		Long: "Show the packages, variables and PATH entries that were added, removed " +
+			"or changed from snapshot <a> to snapshot <b>. If <b> isn't given, <a> is " +
+			"compared with the current environment.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			old, err := envsnapshot.Read(args[0])
+			if err != nil {
+				return err
+			}
+			var new *envsnapshot.Snapshot
+			if len(args) == 2 {
+				new, err = envsnapshot.Read(args[1])
+			} else {
+				if err := ensureNixInstalled(cmd, args); err != nil {
+					return err
+				}
+				new, err = currentEnvSnapshot(cmd, flags)
+			}
+			if err != nil {
+				return err
+			}
+			return envsnapshot.Compare(old, new).Write(cmd.OutOrStdout())
+		},
+	}
+	flags.register(command)
+	return command
+}
+
+func (f *envCmdFlags) register(cmd *cobra.Command) {
+	f.config.register(cmd)
+	cmd.Flags().BoolVar(
+		&f.impure, "impure", false,
+		"inherit variables from the current environment, which may include secrets")
+}
+
+func currentEnvSnapshot(cmd *cobra.Command, flags *envCmdFlags) (*envsnapshot.Snapshot, error) {
+	// Snapshots are pure unless --impure is set, so that they don't record
+	// secrets from the current environment.
+	box, err := devbox.Open(&devopt.Opts{
+		Dir:    flags.config.path,
+		Pure:   !flags.impure,
+		Writer: cmd.ErrOrStderr(),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return box.EnvSnapshot(cmd.Context())
+}
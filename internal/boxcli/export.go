@@ -9,7 +9,7 @@ import (
 // exportCmd is an alias of shellenv, but is also hidden and hence we cannot define it
 // simply using `Aliases: []string{"export"}` in the shellEnvCmd definition.
 func exportCmd() *cobra.Command {
-	flags := shellEnvCmdFlags{}
+	flags := shellEnvCmdFlags{checkTrust: true}
 	cmd := &cobra.Command{
 		Use:    "export [shell]",
 		Hidden: true,
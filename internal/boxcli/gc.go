@@ -0,0 +1,58 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+type gcCmdFlags struct {
+	config configFlags
+	keep   int
+	store  bool
+	dryRun bool
+}
+
+func gcCmd() *cobra.Command {
+	flags := &gcCmdFlags{}
+
+	command := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove old profile generations and the GC roots of deleted projects",
+		Long: "Remove the profile generations of your devbox projects that are no longer " +
+			"needed, and the nix GC roots of projects that were deleted, so that the " +
+			"packages they use can be garbage collected. With --store, also run " +
+			"`nix store gc` and report the space freed.\n\n" +
+			"Devbox finds projects from their nix GC roots, the global profiles, the " +
+			"projects listed by `devbox projects ls` and the current project. The " +
+			"current generation of each profile and the one that `devbox rollback` " +
+			"restores are always kept.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.store {
+				if err := ensureNixInstalled(cmd, args); err != nil {
+					return err
+				}
+			}
+			return devbox.GC(cmd.Context(), devopt.GCOpts{
+				Dir:    flags.config.path,
+				Keep:   flags.keep,
+				Store:  flags.store,
+				DryRun: flags.dryRun,
+			}, cmd.ErrOrStderr())
+		},
+	}
+
+	flags.config.register(command)
+	command.Flags().IntVar(
+		&flags.keep, "keep", 0, "number of most recent profile generations to keep in each project")
+	command.Flags().BoolVar(
+		&flags.store, "store", false, "run nix store gc to delete the packages that are no longer used")
+	command.Flags().BoolVar(
+		&flags.dryRun, "dry-run", false, "show what would be removed without removing it")
+	return command
+}
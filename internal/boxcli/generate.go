@@ -17,6 +17,7 @@ type generateCmdFlags struct {
 	force             bool
 	printEnvrcContent bool
 	githubUsername    string
+	script            string
 }
 
 func generateCmd() *cobra.Command {
@@ -31,6 +32,9 @@ func generateCmd() *cobra.Command {
 	command.AddCommand(dockerfileCmd())
 	command.AddCommand(debugCmd())
 	command.AddCommand(direnvCmd())
+	command.AddCommand(githubActionsCmd())
+	command.AddCommand(gitlabCICmd())
+	command.AddCommand(nixCmd())
 	command.AddCommand(sshConfigCmd())
 	flags.config.register(command)
 
@@ -55,14 +59,15 @@ func devcontainerCmd() *cobra.Command {
 	command := &cobra.Command{
 		Use:   "devcontainer",
 		Short: "Generate Dockerfile and devcontainer.json files under .devcontainer/ directory",
-		Long:  "Generate Dockerfile and devcontainer.json files necessary to run VSCode in remote container environments.",
-		Args:  cobra.MaximumNArgs(0),
+		Long: "Generate Dockerfile and devcontainer.json files necessary to run VSCode in remote container environments. " +
+			"If devcontainer.json already exists, devbox's ports, mounts and extensions are added to it in place.",
+		Args: cobra.MaximumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runGenerateCmd(cmd, flags)
 		},
 	}
 	command.Flags().BoolVarP(
-		&flags.force, "force", "f", false, "force overwrite on existing files")
+		&flags.force, "force", "f", false, "force overwrite on existing files instead of updating them")
 	return command
 }
 
@@ -107,6 +112,67 @@ func direnvCmd() *cobra.Command {
 	return command
 }
 
+func githubActionsCmd() *cobra.Command {
+	flags := &generateCmdFlags{}
+	command := &cobra.Command{
+		Use:   "github-actions",
+		Short: "Generate a GitHub Actions workflow that runs a devbox script",
+		Long: "Generate .github/workflows/devbox.yml, a GitHub Actions workflow that " +
+			"installs devbox, caches /nix/store keyed on devbox.lock and runs a script " +
+			"from the devbox config.",
+		Args: cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateCmd(cmd, flags)
+		},
+	}
+	flags.registerCIFlags(command)
+	return command
+}
+
+func gitlabCICmd() *cobra.Command {
+	flags := &generateCmdFlags{}
+	command := &cobra.Command{
+		Use:   "gitlab-ci",
+		Short: "Generate a GitLab CI pipeline that runs a devbox script",
+		Long: "Generate .gitlab-ci.yml, a GitLab CI pipeline that installs devbox, " +
+			"caches /nix/store keyed on devbox.lock and runs a script from the devbox config.",
+		Args: cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateCmd(cmd, flags)
+		},
+	}
+	flags.registerCIFlags(command)
+	return command
+}
+
+func (flags *generateCmdFlags) registerCIFlags(command *cobra.Command) {
+	command.Flags().StringVarP(
+		&flags.script, "script", "s", "",
+		"script from the devbox config to run (defaults to test, or the first script)")
+	command.Flags().BoolVarP(
+		&flags.force, "force", "f", false, "force overwrite existing files")
+	flags.config.register(command)
+}
+
+func nixCmd() *cobra.Command {
+	flags := &generateCmdFlags{}
+	command := &cobra.Command{
+		Use:   "nix",
+		Short: "Generate a flake.nix and shell.nix that don't require devbox",
+		Long: "Generate a flake.nix and shell.nix with the same packages, env and " +
+			"init_hook as devbox shell, so people without devbox can enter the " +
+			"environment with nix develop or nix-shell.",
+		Args: cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateCmd(cmd, flags)
+		},
+	}
+	command.Flags().BoolVarP(
+		&flags.force, "force", "f", false, "force overwrite existing files")
+	flags.config.register(command)
+	return command
+}
+
 func sshConfigCmd() *cobra.Command {
 	flags := &generateCmdFlags{}
 	command := &cobra.Command{
@@ -144,13 +210,19 @@ func runGenerateCmd(cmd *cobra.Command, flags *generateCmdFlags) error {
 		return box.GenerateDevcontainer(cmd.Context(), flags.force)
 	case "dockerfile":
 		return box.GenerateDockerfile(cmd.Context(), flags.force)
+	case "github-actions":
+		return box.GenerateGithubActions(cmd.Context(), flags.script, flags.force)
+	case "gitlab-ci":
+		return box.GenerateGitlabCI(cmd.Context(), flags.script, flags.force)
+	case "nix":
+		return box.GenerateNix(cmd.Context(), flags.force)
 	}
 	return nil
 }
 
 func runGenerateDirenvCmd(cmd *cobra.Command, flags *generateCmdFlags) error {
 	if flags.printEnvrcContent {
-		return devbox.PrintEnvrcContent(cmd.OutOrStdout())
+		return devbox.PrintEnvrcContent(cmd.OutOrStdout(), flags.config.path)
 	}
 
 	box, err := devbox.Open(&devopt.Opts{
@@ -0,0 +1,68 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/pullbox/git"
+)
+
+// gitFlags are options for global configs stored in git repositories. They
+// are added to the URL as the query parameters that git URLs accept, such as
+// git@github.com:user/dotfiles.git?ref=main&dir=devbox.
+type gitFlags struct {
+	branch string
+	dir    string
+	rev    string
+	sshKey string
+}
+
+// register adds the flags to cmd. Only pulls can be pinned to a commit, so
+// --rev is added if withRev is true.
+func (flags *gitFlags) register(cmd *cobra.Command, withRev bool) {
+	cmd.Flags().StringVar(
+		&flags.branch, "branch", "", "git branch that stores the config",
+	)
+	cmd.Flags().StringVar(
+		&flags.dir, "dir", "", "subdirectory of the git repository that stores the config",
+	)
+	if withRev {
+		cmd.Flags().StringVar(
+			&flags.rev, "rev", "", "git commit to pull the config from",
+		)
+	}
+	cmd.Flags().StringVar(
+		&flags.sshKey, "ssh-key", "", "path of the private key to authenticate to the git repository with",
+	)
+}
+
+// apply returns rawURL with the options set by the flags.
+func (flags *gitFlags) apply(rawURL string) (string, error) {
+	if flags.branch == "" && flags.dir == "" && flags.rev == "" && flags.sshKey == "" {
+		return rawURL, nil
+	}
+	if !git.IsRepoURL(rawURL) {
+		return "", usererr.New(
+			"--branch, --dir, --rev and --ssh-key can only be used with git repositories. " +
+				"Use a git@host:repo URL, an https://...git URL, or prefix the URL with git+.",
+		)
+	}
+	repo, err := git.ParseURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	for _, opt := range []struct{ flag, field *string }{
+		{&flags.branch, &repo.Ref},
+		{&flags.dir, &repo.Dir},
+		{&flags.rev, &repo.Rev},
+		{&flags.sshKey, &repo.SSHKey},
+	} {
+		if *opt.flag != "" {
+			*opt.field = *opt.flag
+		}
+	}
+	return repo.String(), nil
+}
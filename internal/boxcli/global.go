@@ -28,17 +28,22 @@ func globalCmd() *cobra.Command {
 	addCommandAndHideConfigFlag(globalCmd, addCmd())
 	addCommandAndHideConfigFlag(globalCmd, hookCmd())
 	addCommandAndHideConfigFlag(globalCmd, installCmd())
+	addCommandAndHideConfigFlag(globalCmd, outdatedCmd())
 	addCommandAndHideConfigFlag(globalCmd, pathCmd())
 	addCommandAndHideConfigFlag(globalCmd, pullCmd())
 	addCommandAndHideConfigFlag(globalCmd, pushCmd())
 	addCommandAndHideConfigFlag(globalCmd, removeCmd())
+	addCommandAndHideConfigFlag(globalCmd, rollbackCmd())
 	addCommandAndHideConfigFlag(globalCmd, runCmd())
 	addCommandAndHideConfigFlag(globalCmd, servicesCmd())
 	addCommandAndHideConfigFlag(globalCmd, shellEnvCmd())
+	addCommandAndHideConfigFlag(globalCmd, syncCmd())
 	addCommandAndHideConfigFlag(globalCmd, updateCmd())
 
 	// Create list for non-global? Mike: I want it :)
+	globalCmd.AddCommand(globalKeyCmd())
 	globalCmd.AddCommand(globalListCmd())
+	globalCmd.AddCommand(globalProfileCmd())
 
 	return globalCmd
 }
@@ -119,6 +124,13 @@ func ensureGlobalEnvEnabled(cmd *cobra.Command, args []string) error {
 	if cmd.Name() == "shellenv" || cmd.Name() == "hook" {
 		return nil
 	}
+	// Profile commands may switch to a profile that the user's shell hasn't
+	// activated yet, and tell the user how to activate it. Key commands
+	// don't use the global environment.
+	if cmd.Parent() != nil &&
+		(cmd.Parent().Name() == "profile" || cmd.Parent().Name() == "key") {
+		return nil
+	}
 	path, err := ensureGlobalConfig(cmd)
 	if err != nil {
 		return errors.WithStack(err)
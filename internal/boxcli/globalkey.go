@@ -0,0 +1,129 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/pullbox/sign"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+func globalKeyCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "key",
+		Short: "Manage the keys that sign and verify global configs",
+		Long: "Manage the keys that sign and verify global configs. Pushed configs are " +
+			"signed with your signing key, if you have one. Once you trust a key, pulled " +
+			"configs must be signed by a trusted key.",
+	}
+	command.AddCommand(globalKeyGenerateCmd())
+	command.AddCommand(globalKeyListCmd())
+	command.AddCommand(globalKeyTrustCmd())
+	command.AddCommand(globalKeyUntrustCmd())
+	return command
+}
+
+func globalKeyGenerateCmd() *cobra.Command {
+	force := false
+	command := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the key that signs the global configs you push",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := sign.GenerateSigningKey(force)
+			if err != nil {
+				return err
+			}
+			ux.Fsuccess(
+				cmd.ErrOrStderr(),
+				"Generated signing key %s. Its public key is in %s. To verify the configs "+
+					"you push on other machines, run:\n\n",
+				key.ID, sign.PublicKeyPath(),
+			)
+			fmt.Fprintf(cmd.OutOrStdout(), "devbox global key trust %s\n", key)
+			return nil
+		},
+	}
+	command.Flags().BoolVarP(&force, "force", "f", false, "replace the existing signing key")
+	return command
+}
+
+func globalKeyListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List your signing key and the trusted keys",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := sign.SigningKey()
+			if err != nil {
+				return err
+			}
+			trusted, err := sign.TrustedKeys()
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			if key != nil {
+				fmt.Fprintf(w, "Signing key:\n  %s %s\n", key.ID, key.Public())
+			} else {
+				fmt.Fprintln(w, "No signing key. Run `devbox global key generate` to create one.")
+			}
+			if len(trusted) == 0 {
+				fmt.Fprintln(w, "No trusted keys. Pulled configs are not verified.")
+				return nil
+			}
+			fmt.Fprintln(w, "Trusted keys:")
+			for _, k := range trusted {
+				fmt.Fprintf(w, "  %s %s\n", k.ID, k)
+			}
+			return nil
+		},
+	}
+}
+
+func globalKeyTrustCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "trust <public-key>",
+		Short: "Trust a key to sign the global configs you pull",
+		Long: "Trust a key to sign the global configs you pull. Once a key is trusted, " +
+			"`devbox global pull` and `devbox global sync` refuse configs that aren't " +
+			"signed by a trusted key, unless --allow-unsigned is set.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := sign.ParsePublicKey(args[0])
+			if err != nil {
+				return err
+			}
+			if err := sign.Trust(key); err != nil {
+				return err
+			}
+			ux.Fsuccess(cmd.ErrOrStderr(), "Trusted key %s\n", key.ID)
+			return nil
+		},
+	}
+}
+
+func globalKeyUntrustCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "untrust <key-id>",
+		Short: "Stop trusting a key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := sign.Untrust(args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return usererr.New("Key %s is not trusted", args[0])
+			}
+			ux.Fsuccess(cmd.ErrOrStderr(), "Key %s is no longer trusted\n", args[0])
+			return nil
+		},
+	}
+}
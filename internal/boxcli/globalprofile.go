@@ -0,0 +1,111 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+func globalProfileCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named global profiles",
+		Long: "Manage named global profiles, such as work and personal. Each profile has its " +
+			"own devbox.json and packages, and `devbox global` commands act on the active " +
+			"profile.",
+	}
+	command.AddCommand(globalProfileCreateCmd())
+	command.AddCommand(globalProfileDeleteCmd())
+	command.AddCommand(globalProfileListCmd())
+	command.AddCommand(globalProfileSwitchCmd())
+	return command
+}
+
+func globalProfileCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an empty global profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := devbox.CreateGlobalProfile(args[0], cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			ux.Fsuccess(
+				cmd.ErrOrStderr(),
+				"Created global profile %s. Run `devbox global profile switch %[1]s` to use it.\n",
+				args[0],
+			)
+			return nil
+		},
+	}
+}
+
+func globalProfileDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete <name>",
+		Aliases: []string{"rm"},
+		Short:   "Delete a global profile and its packages",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := devbox.DeleteGlobalProfile(args[0]); err != nil {
+				return err
+			}
+			ux.Fsuccess(cmd.ErrOrStderr(), "Deleted global profile %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func globalProfileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List global profiles",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := devbox.ListGlobalProfiles()
+			if err != nil {
+				return err
+			}
+			active, err := devbox.ActiveGlobalProfile()
+			if err != nil {
+				return err
+			}
+			for _, name := range profiles {
+				if name == active {
+					fmt.Fprintf(cmd.OutOrStdout(), "* %s\n", name)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", name)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func globalProfileSwitchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "switch <name>",
+		Aliases: []string{"use"},
+		Short:   "Make a global profile the active one",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := devbox.SwitchGlobalProfile(args[0]); err != nil {
+				return err
+			}
+			ux.Fsuccess(
+				cmd.ErrOrStderr(),
+				"Switched to global profile %s. Run `eval \"$(devbox global shellenv)\"` "+
+					"or start a new shell to use its packages.\n",
+				args[0],
+			)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox/internal/importer"
+	"go.jetpack.io/devbox/internal/initrec"
+	"go.jetpack.io/devbox/internal/searcher"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+type importCmdFlags struct {
+	config configFlags
+}
+
+func importCmd() *cobra.Command {
+	flags := &importCmdFlags{}
+
+	command := &cobra.Command{
+		Use:   "import <source>",
+		Short: "Import packages and services from another tool into the devbox config",
+		Long: "Import packages and services from another tool into the devbox config, " +
+			"creating it if needed. <source> is one of .tool-versions, " +
+			".mise.toml, shell.nix, flake.nix or docker-compose.yml, or a " +
+			"directory containing them. Services in docker-compose.yml that " +
+			"run a command are written to process-compose.yaml. Anything that " +
+			"can't be translated is listed so you can migrate it by hand.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportCmd(cmd, args[0], flags)
+		},
+	}
+
+	flags.config.register(command)
+	return command
+}
+
+func runImportCmd(cmd *cobra.Command, source string, flags *importCmdFlags) error {
+	result, err := importer.Import(source)
+	if err != nil {
+		return err
+	}
+	result.Packages = initrec.Resolve(result.Packages, searcher.Client())
+
+	dir := flags.config.path
+	if dir == "" {
+		dir = "."
+	}
+	if err := importer.Apply(dir, result); err != nil {
+		return err
+	}
+
+	w := cmd.ErrOrStderr()
+	if len(result.Packages) > 0 {
+		ux.Fsuccess(w, "Imported packages: %s\n", strings.Join(result.Packages, ", "))
+	}
+	if len(result.Env) > 0 {
+		ux.Fsuccess(w, "Imported %d environment variables\n", len(result.Env))
+	}
+	if len(result.Processes) > 0 {
+		ux.Fsuccess(w, "Imported %d services into process-compose.yaml\n", len(result.Processes))
+	}
+	if len(result.Untranslated) > 0 {
+		ux.Fwarning(w, "The following could not be translated:\n")
+		for _, u := range result.Untranslated {
+			fmt.Fprintf(w, "  - %s\n", u)
+		}
+	}
+	ux.Finfo(w, "Run `devbox install` to install the imported packages.\n")
+	return nil
+}
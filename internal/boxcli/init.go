@@ -4,31 +4,93 @@
 package boxcli
 
 import (
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/mattn/go-isatty"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/initrec"
+	"go.jetpack.io/devbox/internal/searcher"
 )
 
+type initCmdFlags struct {
+	format      string
+	interactive bool
+}
+
 func initCmd() *cobra.Command {
+	flags := initCmdFlags{}
 	command := &cobra.Command{
 		Use:   "init [<dir>]",
 		Short: "Initialize a directory as a devbox project",
 		Long: "Initialize a directory as a devbox project. " +
-			"This will create an empty devbox.json in the current directory. " +
-			"You can then add packages using `devbox add`",
+			"This will create an empty devbox.json in the current directory, or " +
+			"a devbox.yaml or devbox.toml with --format. " +
+			"You can then add packages using `devbox add`. " +
+			"Devbox recommends packages, pinned to the versions your project " +
+			"declares, based on the files in the directory. With --interactive, " +
+			"devbox looks up the recommended versions and lets you choose which " +
+			"packages to add.",
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInitCmd(cmd, args)
+			return runInitCmd(cmd, args, flags)
 		},
 	}
 
+	command.Flags().StringVar(
+		&flags.format, "format", "json",
+		"format of the config file to create: "+strings.Join(devconfig.Formats, ", "))
+	command.Flags().BoolVarP(
+		&flags.interactive, "interactive", "i", false,
+		"choose which of the recommended packages to add")
 	return command
 }
 
-func runInitCmd(cmd *cobra.Command, args []string) error {
+func runInitCmd(cmd *cobra.Command, args []string, flags initCmdFlags) error {
 	path := pathArg(args)
 
-	_, err := devbox.InitConfig(path, cmd.ErrOrStderr())
+	if !flags.interactive {
+		_, err := devbox.InitConfigWithFormat(path, flags.format, cmd.ErrOrStderr())
+		return errors.WithStack(err)
+	}
+
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return usererr.New("--interactive requires a terminal.")
+	}
+	dir := path
+	if dir == "" {
+		dir = "."
+	}
+	packages, err := initrec.Get(dir)
+	if err != nil {
+		return err
+	}
+	if len(packages) > 0 {
+		packages = initrec.Resolve(packages, searcher.Client())
+		if packages, err = selectPackages(packages); err != nil {
+			return err
+		}
+	}
+	_, err = devbox.InitConfigWithPackages(cmd.Context(), path, flags.format, packages)
 	return errors.WithStack(err)
 }
+
+// selectPackages lets the user accept or reject each recommended package.
+func selectPackages(pkgs []string) ([]string, error) {
+	selected := []string{}
+	prompt := &survey.MultiSelect{
+		Message: "We detected packages your project may need. Select the ones to add:",
+		Options: pkgs,
+		Default: pkgs,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return selected, nil
+}
@@ -17,7 +17,7 @@ func installCmd() *cobra.Command {
 	flags := runCmdFlags{}
 	command := &cobra.Command{
 		Use:     "install",
-		Short:   "Install all packages mentioned in devbox.json",
+		Short:   "Install all packages mentioned in the devbox config",
 		Args:    cobra.MaximumNArgs(0),
 		PreRunE: ensureNixInstalled,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,43 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+type outdatedCmdFlags struct {
+	config configFlags
+}
+
+func outdatedCmd() *cobra.Command {
+	flags := &outdatedCmdFlags{}
+
+	command := &cobra.Command{
+		Use:   "outdated",
+		Short: "List packages that have newer versions available",
+		Long: "List versioned packages whose locked version is behind the newest " +
+			"version allowed by their version constraint (wanted) or the newest " +
+			"version available (latest).",
+		Args:    cobra.NoArgs,
+		PreRunE: ensureNixInstalled,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			box, err := devbox.Open(&devopt.Opts{
+				Dir:    flags.config.path,
+				Writer: cmd.OutOrStdout(),
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return box.Outdated(cmd.Context())
+		},
+	}
+
+	flags.config.register(command)
+	return command
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+func projectsCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "projects",
+		Short: "List and manage the devbox projects on this machine",
+		Long: "List and manage the devbox projects on this machine. Devbox records a " +
+			"project every time it opens it.",
+	}
+	command.AddCommand(projectsLsCmd())
+	command.AddCommand(projectsOpenCmd())
+	command.AddCommand(projectsPruneCmd())
+	return command
+}
+
+func projectsLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the devbox projects on this machine",
+		Long: "List the devbox projects on this machine, most recently used first, " +
+			"with whether their services are running. Projects that no longer exist " +
+			"are marked as missing, and `devbox projects prune` removes them.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := devbox.ListProjects()
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				ux.Finfo(cmd.ErrOrStderr(), "No projects yet. Devbox lists a project once it opens it.\n")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 3, 2, 8, ' ', tabwriter.TabIndent)
+			fmt.Fprintln(w, "PROJECT\tLAST USED\tSERVICES")
+			for _, p := range list {
+				services := "-"
+				switch {
+				case p.Missing:
+					services = "(missing)"
+				case p.ServicesRunning:
+					services = "running"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n",
+					p.Dir, p.LastUsed.Local().Format("2006-01-02 15:04"), services)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func projectsPruneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the projects that no longer exist from the list of projects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := devbox.PruneProjects()
+			if err != nil {
+				return err
+			}
+			if len(removed) == 0 {
+				ux.Finfo(cmd.ErrOrStderr(), "No projects to remove\n")
+				return nil
+			}
+			ux.Fsuccess(cmd.ErrOrStderr(), "Removed %d projects that no longer exist:\n", len(removed))
+			for _, p := range removed {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", p.Dir)
+			}
+			return nil
+		},
+	}
+}
+
+func projectsOpenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "open <name-or-path>",
+		Short: "Start a devbox shell in a project",
+		Long: "Start a devbox shell in the directory of a project from " +
+			"`devbox projects ls`. The project can be given by its path, or by the " +
+			"name of its directory if no other project has the same name.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: ensureNixInstalled,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if envir.IsDevboxShellEnabled() {
+				return shellInceptionErrorMsg("devbox shell")
+			}
+			dir, err := devbox.FindProject(args[0])
+			if err != nil {
+				return err
+			}
+			// The shell starts in the current directory.
+			if err := os.Chdir(dir); err != nil {
+				return errors.WithStack(err)
+			}
+			box, err := devbox.Open(&devopt.Opts{
+				Dir:    dir,
+				Writer: cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return box.Shell(cmd.Context())
+		},
+	}
+}
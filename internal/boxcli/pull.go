@@ -20,8 +20,10 @@ import (
 )
 
 type pullCmdFlags struct {
-	config configFlags
-	force  bool
+	config        configFlags
+	git           gitFlags
+	force         bool
+	allowUnsigned bool
 }
 
 func pullCmd() *cobra.Command {
@@ -41,7 +43,12 @@ func pullCmd() *cobra.Command {
 		&flags.force, "force", "f", false,
 		"Force overwrite of existing [global] config files",
 	)
+	cmd.Flags().BoolVar(
+		&flags.allowUnsigned, "allow-unsigned", false,
+		"pull configs that aren't signed by a trusted key",
+	)
 
+	flags.git.register(cmd, true)
 	flags.config.register(cmd)
 
 	return cmd
@@ -60,8 +67,16 @@ func pullCmdFunc(cmd *cobra.Command, url string, flags *pullCmdFlags) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if pullPath, err = flags.git.apply(pullPath); err != nil {
+		return err
+	}
 
-	err = box.Pull(cmd.Context(), flags.force, pullPath)
+	opts := devopt.PullboxOpts{
+		URL:           pullPath,
+		Overwrite:     flags.force,
+		AllowUnsigned: flags.allowUnsigned,
+	}
+	err = box.Pull(cmd.Context(), opts)
 	if prompt := pullErrorPrompt(err); prompt != "" {
 		prompt := &survey.Confirm{Message: prompt}
 		if err = survey.AskOne(prompt, &flags.force); err != nil {
@@ -70,7 +85,8 @@ func pullCmdFunc(cmd *cobra.Command, url string, flags *pullCmdFlags) error {
 		if !flags.force {
 			return nil
 		}
-		err = box.Pull(cmd.Context(), flags.force, pullPath)
+		opts.Overwrite = true
+		err = box.Pull(cmd.Context(), opts)
 	}
 	if errors.Is(err, s3.ErrProfileNotFound) {
 		return usererr.New(
@@ -90,7 +106,8 @@ func pullCmdFunc(cmd *cobra.Command, url string, flags *pullCmdFlags) error {
 func pullErrorPrompt(err error) string {
 	switch {
 	case errors.Is(err, fs.ErrExist):
-		return "Global profile already exists. Overwrite?"
+		return "Global profile already exists. Overwrite? " +
+			"(Run `devbox global sync` to merge instead.)"
 	default:
 		return ""
 	}
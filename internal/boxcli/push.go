@@ -14,20 +14,22 @@ import (
 
 type pushCmdFlags struct {
 	config configFlags
+	git    gitFlags
 }
 
 func pushCmd() *cobra.Command {
 	flags := pushCmdFlags{}
 	cmd := &cobra.Command{
-		Use: "push <git-repo>",
+		Use: "push <url>",
 		Short: "Push a [global] config. Leave empty to use jetpack cloud. Can " +
-			"be a git repo for self storage.",
+			"be a git repo, s3:// URL, HTTP server or local path for self storage.",
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return pushCmdFunc(cmd, goutil.GetDefaulted(args, 0), flags)
 		},
 	}
 
+	flags.git.register(cmd, false)
 	flags.config.register(cmd)
 
 	return cmd
@@ -41,5 +43,8 @@ func pushCmdFunc(cmd *cobra.Command, url string, flags pushCmdFlags) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if url, err = flags.git.apply(url); err != nil {
+		return err
+	}
 	return box.Push(cmd.Context(), url)
 }
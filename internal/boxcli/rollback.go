@@ -0,0 +1,42 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+type rollbackCmdFlags struct {
+	config configFlags
+}
+
+func rollbackCmd() *cobra.Command {
+	flags := &rollbackCmdFlags{}
+
+	command := &cobra.Command{
+		Use:   "rollback",
+		Short: "Undo the last change to your devbox packages",
+		Long: "Undo the last successful add, rm or update by restoring the devbox config, " +
+			"devbox.lock and the previous generation of the nix profile.",
+		Args:    cobra.NoArgs,
+		PreRunE: ensureNixInstalled,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			box, err := devbox.Open(&devopt.Opts{
+				Dir:    flags.config.path,
+				Writer: cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return box.Rollback(cmd.Context())
+		},
+	}
+
+	flags.config.register(command)
+	return command
+}
@@ -52,23 +52,32 @@ func RootCmd() *cobra.Command {
 		command.AddCommand(authCmd())
 	}
 	command.AddCommand(createCmd())
+	command.AddCommand(envCmd())
 	command.AddCommand(exportCmd())
+	command.AddCommand(gcCmd())
 	command.AddCommand(generateCmd())
 	command.AddCommand(globalCmd())
 	command.AddCommand(hookCmd())
+	command.AddCommand(importCmd())
 	command.AddCommand(infoCmd())
 	command.AddCommand(initCmd())
 	command.AddCommand(installCmd())
 	command.AddCommand(integrateCmd())
 	command.AddCommand(logCmd())
+	command.AddCommand(outdatedCmd())
+	command.AddCommand(projectsCmd())
 	command.AddCommand(removeCmd())
+	command.AddCommand(rollbackCmd())
 	command.AddCommand(runCmd())
 	command.AddCommand(searchCmd())
 	command.AddCommand(servicesCmd())
 	command.AddCommand(setupCmd())
 	command.AddCommand(shellCmd())
 	command.AddCommand(shellEnvCmd())
+	command.AddCommand(trustCmd())
+	command.AddCommand(untrustCmd())
 	command.AddCommand(updateCmd())
+	command.AddCommand(validateCmd())
 	command.AddCommand(versionCmd())
 	// Preview commands
 	command.AddCommand(cloudCmd())
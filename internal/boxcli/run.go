@@ -26,12 +26,12 @@ func runCmd() *cobra.Command {
 		Use:   "run [<script> | <cmd>]",
 		Short: "Run a script or command in a shell with access to your packages",
 		Long: "Start a new shell and runs your script or command in it, exiting when done.\n\n" +
-			"The script must be defined in `devbox.json`, or else it will be interpreted as an " +
+			"The script must be defined in the devbox config, or else it will be interpreted as an " +
 			"arbitrary command. You can pass arguments to your script or command. Everything " +
 			"after `--` will be passed verbatim into your command (see examples).\n\n",
 		Example: "\nRun a command directly:\n\n  devbox add cowsay\n  devbox run cowsay hello\n  " +
 			"devbox run -- cowsay -d hello\n\nRun a script (defined as `\"moo\": \"cowsay moo\"`) " +
-			"in your devbox.json:\n\n  devbox run moo",
+			"in your devbox config:\n\n  devbox run moo",
 		PreRunE: ensureNixInstalled,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runScriptCmd(cmd, args, flags)
@@ -66,7 +66,7 @@ func runScriptCmd(cmd *cobra.Command, args []string, flags runCmdFlags) error {
 	if len(args) == 0 {
 		scripts := listScripts(cmd, flags)
 		if len(scripts) == 0 {
-			fmt.Fprintln(cmd.OutOrStdout(), "no scripts defined in devbox.json")
+			fmt.Fprintln(cmd.OutOrStdout(), "no scripts defined in the devbox config")
 			return nil
 		}
 		fmt.Fprintln(cmd.OutOrStdout(), "Available scripts:")
@@ -90,7 +90,7 @@ func runScriptCmd(cmd *cobra.Command, args []string, flags runCmdFlags) error {
 		Pure:   flags.pure,
 	})
 	if err != nil {
-		return redact.Errorf("error reading devbox.json: %w", err)
+		return redact.Errorf("error reading devbox config: %w", err)
 	}
 
 	if err := box.RunScript(cmd.Context(), script, scriptArgs); err != nil {
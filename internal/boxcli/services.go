@@ -29,7 +29,7 @@ func (flags *serviceUpFlags) register(cmd *cobra.Command) {
 		"process-compose-file",
 		"",
 		"path to process compose file or directory containing process "+
-			"compose-file.yaml|yml. Default is directory containing devbox.json",
+			"compose-file.yaml|yml. Default is directory containing the devbox config",
 	)
 	cmd.Flags().BoolVarP(
 		&flags.background, "background", "b", false, "Run service in background")
@@ -27,7 +27,7 @@ func shellCmd() *cobra.Command {
 		Use:   "shell",
 		Short: "Start a new shell with access to your packages",
 		Long: "Start a new shell with access to your packages.\n\n" +
-			"If the --config flag is set, the shell will be started using the devbox.json found in the --config flag directory. " +
+			"If the --config flag is set, the shell will be started using the devbox config found in the --config flag directory. " +
 			"If --config isn't set, then devbox recursively searches the current directory and its parents.",
 		Args:    cobra.NoArgs,
 		PreRunE: ensureNixInstalled,
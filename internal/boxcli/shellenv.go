@@ -9,6 +9,8 @@ import (
 	"github.com/spf13/cobra"
 	"go.jetpack.io/devbox"
 	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/trust"
+	"go.jetpack.io/devbox/internal/ux"
 )
 
 type shellEnvCmdFlags struct {
@@ -16,6 +18,9 @@ type shellEnvCmdFlags struct {
 	runInitHook bool
 	install     bool
 	pure        bool
+	// checkTrust skips untrusted projects instead of exporting their
+	// environment. It is set by the shell hook.
+	checkTrust bool
 }
 
 func shellEnvCmd() *cobra.Command {
@@ -57,14 +62,31 @@ func registerShellEnvFlags(command *cobra.Command, flags *shellEnvCmdFlags) {
 
 func shellEnvFunc(cmd *cobra.Command, flags shellEnvCmdFlags) (string, error) {
 	box, err := devbox.Open(&devopt.Opts{
-		Dir:    flags.config.path,
-		Writer: cmd.ErrOrStderr(),
-		Pure:   flags.pure,
+		Dir:       flags.config.path,
+		Writer:    cmd.ErrOrStderr(),
+		Pure:      flags.pure,
+		ShellHook: flags.checkTrust,
 	})
 	if err != nil {
 		return "", err
 	}
 
+	if flags.checkTrust {
+		status, err := box.TrustStatus()
+		if err != nil {
+			return "", err
+		}
+		if status != trust.Trusted {
+			ux.Fwarning(
+				cmd.ErrOrStderr(),
+				"%s. Run `devbox trust --config %s` to activate it.\n",
+				trust.Message(status),
+				box.ProjectDir(),
+			)
+			return "", nil
+		}
+	}
+
 	if flags.install {
 		if err := box.Install(cmd.Context()); err != nil {
 			return "", err
@@ -0,0 +1,107 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/goutil"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+type syncCmdFlags struct {
+	config        configFlags
+	git           gitFlags
+	dryRun        bool
+	yes           bool
+	allowUnsigned bool
+}
+
+func syncCmd() *cobra.Command {
+	flags := syncCmdFlags{}
+	cmd := &cobra.Command{
+		Use:   "sync <url>",
+		Short: "Merge the global config with a pushed config, and update both",
+		Long: "Merge the packages and env of the global config with the config stored at " +
+			"<url>, and update both with the result. Changes made on either side since " +
+			"the last sync are kept. Leave <url> empty to use jetpack cloud.",
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: ensureNixInstalled,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return syncCmdFunc(cmd, goutil.GetDefaulted(args, 0), &flags)
+		},
+	}
+
+	cmd.Flags().BoolVar(
+		&flags.dryRun, "dry-run", false,
+		"show the changes without applying them",
+	)
+	cmd.Flags().BoolVarP(
+		&flags.yes, "yes", "y", false,
+		"apply the changes without asking for confirmation",
+	)
+	cmd.Flags().BoolVar(
+		&flags.allowUnsigned, "allow-unsigned", false,
+		"pull configs that aren't signed by a trusted key",
+	)
+
+	flags.git.register(cmd, false)
+	flags.config.register(cmd)
+
+	return cmd
+}
+
+func syncCmdFunc(cmd *cobra.Command, url string, flags *syncCmdFlags) error {
+	box, err := devbox.Open(&devopt.Opts{
+		Dir:    flags.config.path,
+		Writer: cmd.ErrOrStderr(),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	syncURL, err := absolutizeIfLocal(url)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if syncURL, err = flags.git.apply(syncURL); err != nil {
+		return err
+	}
+
+	updated, err := box.Sync(cmd.Context(), devopt.SyncOpts{
+		URL:           syncURL,
+		DryRun:        flags.dryRun,
+		AllowUnsigned: flags.allowUnsigned,
+		Confirm:       func() (bool, error) { return confirmSync(flags.yes) },
+	})
+	if err != nil || !updated {
+		return err
+	}
+
+	return installCmdFunc(
+		cmd,
+		runCmdFlags{config: configFlags{path: flags.config.path}},
+	)
+}
+
+func confirmSync(yes bool) (bool, error) {
+	if yes {
+		return true, nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false, usererr.New(
+			"Not applying the changes without a terminal. Rerun with --yes to apply them.",
+		)
+	}
+	ok := false
+	prompt := &survey.Confirm{Message: "Apply these changes?"}
+	return ok, errors.WithStack(survey.AskOne(prompt, &ok))
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+type trustCmdFlags struct {
+	config configFlags
+}
+
+func trustCmd() *cobra.Command {
+	flags := &trustCmdFlags{}
+
+	command := &cobra.Command{
+		Use:   "trust",
+		Short: "Allow devbox to activate this project's environment and hooks",
+		Long: "Allow devbox to activate this project's environment and init_hook in `devbox shell` " +
+			"and the shell hook. Trust is tied to the current contents of the devbox config, so you have " +
+			"to trust the project again after someone else changes it.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			box, err := devbox.Open(&devopt.Opts{
+				Dir:    flags.config.path,
+				Writer: cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return box.Trust(cmd.Context())
+		},
+	}
+
+	flags.config.register(command)
+	return command
+}
+
+func untrustCmd() *cobra.Command {
+	flags := &trustCmdFlags{}
+
+	command := &cobra.Command{
+		Use:   "untrust",
+		Short: "Revoke trust in this project",
+		Long: "Revoke trust in this project, so that devbox asks again before activating its " +
+			"environment and hooks.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			box, err := devbox.Open(&devopt.Opts{
+				Dir:    flags.config.path,
+				Writer: cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return box.Untrust(cmd.Context())
+		},
+	}
+
+	flags.config.register(command)
+	return command
+}
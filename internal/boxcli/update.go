@@ -13,6 +13,7 @@ import (
 
 type updateCmdFlags struct {
 	config configFlags
+	dryRun bool
 }
 
 func updateCmd() *cobra.Command {
@@ -24,7 +25,9 @@ func updateCmd() *cobra.Command {
 		Long: "Update one, many, or all packages in your devbox. " +
 			"If no packages are specified, all packages will be updated. " +
 			"Legacy non-versioned packages will be converted to @latest versioned " +
-			"packages resolved to their current version.",
+			"packages resolved to their current version. Versioned packages " +
+			"may use update policies such as `nodejs@^18`, `python@~3.11` or " +
+			"`go@>=1.20 <1.22` which resolve to the newest matching version.",
 		PreRunE: ensureNixInstalled,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return updateCmdFunc(cmd, args, flags)
@@ -32,6 +35,10 @@ func updateCmd() *cobra.Command {
 	}
 
 	flags.config.register(command)
+	command.Flags().BoolVar(
+		&flags.dryRun, "dry-run", false,
+		"show the versions packages would be updated to without changing anything",
+	)
 	return command
 }
 
@@ -44,5 +51,8 @@ func updateCmdFunc(cmd *cobra.Command, args []string, flags *updateCmdFlags) err
 		return errors.WithStack(err)
 	}
 
-	return box.Update(cmd.Context(), args...)
+	return box.Update(cmd.Context(), devopt.UpdateOpts{
+		Pkgs:   args,
+		DryRun: flags.dryRun,
+	})
 }
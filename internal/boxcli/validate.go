@@ -0,0 +1,42 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package boxcli
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.jetpack.io/devbox"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+type validateCmdFlags struct {
+	config configFlags
+}
+
+func validateCmd() *cobra.Command {
+	flags := validateCmdFlags{}
+	command := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the devbox config for errors",
+		Long: "Check the devbox config (devbox.json, devbox.yaml or devbox.toml) for unknown " +
+			"fields, values of the wrong type and other errors, and report where in the " +
+			"file each one is.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateCmdFunc(cmd, flags)
+		},
+	}
+
+	flags.config.register(command)
+	return command
+}
+
+func validateCmdFunc(cmd *cobra.Command, flags validateCmdFlags) error {
+	path, err := devbox.ValidateConfig(flags.config.path)
+	if err != nil {
+		return err
+	}
+	ux.Fsuccess(cmd.ErrOrStderr(), "%s is valid\n", path)
+	return nil
+}
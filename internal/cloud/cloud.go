@@ -26,6 +26,7 @@ import (
 	"go.jetpack.io/devbox/internal/cloud/openssh"
 	"go.jetpack.io/devbox/internal/cloud/openssh/sshshim"
 	"go.jetpack.io/devbox/internal/debug"
+	"go.jetpack.io/devbox/internal/devconfig"
 	"go.jetpack.io/devbox/internal/envir"
 	"go.jetpack.io/devbox/internal/services"
 	"go.jetpack.io/devbox/internal/telemetry"
@@ -428,7 +429,10 @@ func copyConfigFileToVM(hostname, username, projectDir, pathInVM string) error {
 
 	// Copy the config file to the devbox-project directory in the VM
 	destServer := fmt.Sprintf("%s@%s", username, hostname)
-	configFilePath := filepath.Join(projectDir, "devbox.json")
+	configFilePath, err := devconfig.Find(projectDir)
+	if err != nil {
+		return err
+	}
 	destPath := fmt.Sprintf("%s:%s", destServer, pathInVM)
 	cmd := exec.Command("scp", configFilePath, destPath)
 	err = cmd.Run()
@@ -86,9 +86,13 @@ func WriteFile(path string, value any) error {
 	return errors.WithStack(os.WriteFile(path, data, 0644))
 }
 
+// IsSupportedExtension reports whether ext is the extension of a config file
+// format, which both Unmarshal and ParseTree support. XML is left out since
+// ParseTree doesn't support it. It can still be unmarshalled, such as for
+// project.csproj files.
 func IsSupportedExtension(ext string) bool {
 	switch ext {
-	case ".json", ".lock", ".yml", ".yaml", ".toml", ".xml":
+	case ".json", ".lock", ".yml", ".yaml", ".toml":
 		return true
 	default:
 		return false
@@ -0,0 +1,240 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// EditFile writes value to path in the format of its extension. Unlike
+// WriteFile, value is encoded by its JSON tags and marshallers in every format,
+// so that a JSON, YAML or TOML file holds the same fields. If path exists, only
+// the values that changed are rewritten: JSON files keep their comments, key
+// order and formatting (see EditJSON), and YAML files keep their comments and
+// key order (see EditYAML). TOML files are rewritten.
+func EditFile(path string, value any) error {
+	src, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.WithStack(err)
+	}
+	var data []byte
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		data, err = EditJSON(src, value)
+	case ".yml", ".yaml":
+		data, err = EditYAML(src, value)
+	case ".toml":
+		data, err = marshalTOMLByJSON(value)
+	default:
+		return WriteFile(path, value)
+	}
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(os.WriteFile(path, data, 0644))
+}
+
+// UnmarshalByJSON unmarshals a JSON, YAML or TOML document into valuePtr with
+// its JSON tags and unmarshallers, which is how EditFile encodes it.
+func UnmarshalByJSON(data []byte, extension string, valuePtr any) error {
+	if extension == ".json" {
+		return Unmarshal(data, extension, valuePtr)
+	}
+	tree, err := ParseTree(data, extension)
+	if err != nil {
+		return err
+	}
+	j, err := MarshalJSON(tree.Interface())
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(unmarshalJSON(j, valuePtr))
+}
+
+// treeByJSON marshals value to JSON and parses it, which gives its fields in
+// the order of the struct.
+func treeByJSON(value any) (*Node, error) {
+	data, err := Marshal(value, ".json")
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONTree(data)
+}
+
+func marshalTOMLByJSON(value any) ([]byte, error) {
+	tree, err := treeByJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	data, err := toml.Marshal(tree.Interface())
+	return data, errors.WithStack(err)
+}
+
+// EditYAML returns src, a YAML document, changed to hold value. Fields that
+// are in src keep their order and comments, and new fields are added at the
+// end of their mapping. If src is empty or can't be parsed, it returns value
+// as YAML.
+func EditYAML(src []byte, value any) ([]byte, error) {
+	tree, err := treeByJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	root := yamlNodeOf(tree)
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(src, doc); err == nil && len(doc.Content) > 0 {
+		doc.Content[0] = mergeYAML(doc.Content[0], root)
+	} else {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	}
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(yamlIndent(src))
+	if err := enc.Encode(doc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), errors.WithStack(enc.Close())
+}
+
+// yamlNodeOf converts a tree to YAML nodes.
+func yamlNodeOf(n *Node) *yaml.Node {
+	y := &yaml.Node{Kind: yaml.ScalarNode}
+	switch n.Kind {
+	case NullNode:
+		y.Tag, y.Value = "!!null", "null"
+	case BoolNode:
+		y.Tag, y.Value = "!!bool", strconv.FormatBool(n.Scalar.(bool))
+	case NumberNode:
+		f := n.Scalar.(float64)
+		y.Tag, y.Value = "!!float", strconv.FormatFloat(f, 'f', -1, 64)
+		if f == float64(int64(f)) {
+			y.Tag = "!!int"
+		}
+	case StringNode:
+		y.Tag, y.Value = "!!str", n.Scalar.(string)
+		if strings.Contains(y.Value, "\n") {
+			y.Style = yaml.LiteralStyle
+		}
+	case ArrayNode:
+		y.Kind, y.Tag = yaml.SequenceNode, "!!seq"
+		for _, item := range n.Items {
+			y.Content = append(y.Content, yamlNodeOf(item))
+		}
+	case ObjectNode:
+		y.Kind, y.Tag = yaml.MappingNode, "!!map"
+		for _, f := range n.Fields {
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.Key}
+			y.Content = append(y.Content, key, yamlNodeOf(f.Value))
+		}
+	}
+	return y
+}
+
+// mergeYAML returns the node that replaces old with the value of new. It
+// reuses the nodes of old that hold the same values, which keeps their
+// comments and style.
+func mergeYAML(old, new *yaml.Node) *yaml.Node {
+	if reflect.DeepEqual(yamlValue(old), yamlValue(new)) {
+		return old
+	}
+	switch {
+	case old.Kind == yaml.MappingNode && new.Kind == yaml.MappingNode:
+		merged := *old
+		merged.Content = nil
+		for i := 0; i+1 < len(old.Content); i += 2 {
+			key, value := old.Content[i], old.Content[i+1]
+			newValue := yamlGet(new, key.Value)
+			if newValue == nil && yamlIsEmpty(value) {
+				// Empty values are omitted when marshalling, but are
+				// still the same value.
+				newValue = value
+			} else if newValue == nil {
+				continue
+			}
+			merged.Content = append(merged.Content, key, mergeYAML(value, newValue))
+		}
+		for i := 0; i+1 < len(new.Content); i += 2 {
+			if yamlGet(old, new.Content[i].Value) == nil {
+				merged.Content = append(merged.Content, new.Content[i], new.Content[i+1])
+			}
+		}
+		return &merged
+	case old.Kind == yaml.SequenceNode && new.Kind == yaml.SequenceNode:
+		merged := *old
+		merged.Content = nil
+		for _, op := range diffItems(yamlValues(old.Content), yamlValues(new.Content)) {
+			switch {
+			case op.old >= 0 && op.new >= 0:
+				merged.Content = append(merged.Content, mergeYAML(old.Content[op.old], new.Content[op.new]))
+			case op.new >= 0:
+				merged.Content = append(merged.Content, new.Content[op.new])
+			}
+		}
+		return &merged
+	}
+	// A changed value keeps the comments about it.
+	replaced := *new
+	replaced.HeadComment = old.HeadComment
+	replaced.LineComment = old.LineComment
+	replaced.FootComment = old.FootComment
+	return &replaced
+}
+
+func yamlGet(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlIsEmpty(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(n.Content) == 0
+	case yaml.ScalarNode:
+		return n.ShortTag() == "!!null"
+	}
+	return false
+}
+
+func yamlValue(n *yaml.Node) any {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func yamlValues(nodes []*yaml.Node) []any {
+	values := make([]any, len(nodes))
+	for i, n := range nodes {
+		values[i] = yamlValue(n)
+	}
+	return values
+}
+
+// yamlIndent returns the number of spaces of the first indented line of src,
+// or 2.
+func yamlIndent(src []byte) int {
+	for _, line := range bytes.Split(src, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(trimmed) > 0 && trimmed[0] != '#' && len(trimmed) < len(line) {
+			return len(line) - len(trimmed)
+		}
+	}
+	return 2
+}
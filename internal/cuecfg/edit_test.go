@@ -0,0 +1,69 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditYAML(t *testing.T) {
+	src := `# Tools for the backend.
+shell:
+  init_hook:
+    - echo hi
+packages:
+  - go@1.19 # pinned for the CI image
+  - ripgrep@latest
+env: {}
+`
+	cfg := &editTestConfig{}
+	require.NoError(t, UnmarshalByJSON([]byte(src), ".yaml", cfg))
+	cfg.Packages[0] = "go@1.20"
+	cfg.Packages = append(cfg.Packages, "jq@latest")
+	cfg.Shell.Scripts = map[string]string{"test": "go test ./..."}
+
+	got, err := EditYAML([]byte(src), cfg)
+	require.NoError(t, err)
+	require.Equal(t, `# Tools for the backend.
+shell:
+  init_hook:
+    - echo hi
+  scripts:
+    test: go test ./...
+packages:
+  - go@1.20 # pinned for the CI image
+  - ripgrep@latest
+  - jq@latest
+env: {}
+`, string(got))
+}
+
+func TestEditFileRoundTrip(t *testing.T) {
+	want := &editTestConfig{
+		Packages: []string{"go@1.20"},
+		Env:      map[string]string{"GOFLAGS": "-mod=mod"},
+		Shell: &editTestShell{
+			InitHook: []string{"echo hi"},
+			Scripts:  map[string]string{"test": "go test ./..."},
+		},
+	}
+	for _, ext := range []string{".json", ".yaml", ".toml"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "devbox"+ext)
+			require.NoError(t, EditFile(path, want))
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			// Fields are named by their json tags in every format.
+			require.Contains(t, string(data), "init_hook")
+			got := &editTestConfig{}
+			require.NoError(t, UnmarshalByJSON(data, ext, got))
+			require.Equal(t, want, got)
+		})
+	}
+}
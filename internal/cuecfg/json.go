@@ -28,5 +28,60 @@ func MarshalJSON(v interface{}) ([]byte, error) {
 }
 
 func unmarshalJSON(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
+	return json.Unmarshal(stripJSONC(data), v)
+}
+
+// stripJSONC turns JSONC, which is JSON with comments and trailing commas,
+// into JSON by replacing the comments and trailing commas with spaces. Newlines
+// are kept, so offsets, lines and columns in the result are the same as in
+// data.
+func stripJSONC(data []byte) []byte {
+	if !bytes.ContainsAny(data, "/,") {
+		return data
+	}
+	out := bytes.Clone(data)
+	inString := false
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(out)
+			} else {
+				end += i + 4
+			}
+			for ; i < end; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			lastComma = i
+		case c == ']' || c == '}':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
 }
@@ -0,0 +1,406 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+// EditJSON returns src, a JSON or JSONC document, changed to hold value. Only
+// the values that differ are rewritten, so the comments, key order and
+// formatting of the rest of src are kept, and a diff of the result only shows
+// the change. New fields are added at the end of their object. If src can't
+// be parsed, value is marshalled like Marshal does.
+func EditJSON(src []byte, value any) ([]byte, error) {
+	newSrc, err := Marshal(value, ".json")
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := parseJSONTree(newSrc)
+	if err != nil {
+		return nil, err
+	}
+	oldTree, err := parseJSONTree(src)
+	if err != nil {
+		return newSrc, nil
+	}
+
+	e := &jsonEditor{src: src, newSrc: newSrc, unit: indentUnit(src), newline: newline(src)}
+	buf := &bytes.Buffer{}
+	buf.Write(src[:oldTree.Offset])
+	buf.WriteString(e.render(oldTree, newTree))
+	buf.Write(src[oldTree.End:])
+	return buf.Bytes(), nil
+}
+
+type jsonEditor struct {
+	// src is the document being edited, and newSrc is the new value as
+	// marshalled JSON, which is where new values are copied from.
+	src    []byte
+	newSrc []byte
+	// unit is the indentation of each level of nesting in src.
+	unit string
+	// newline ends the lines that are added to src, "\r\n" if src uses
+	// CRLF line endings.
+	newline string
+}
+
+// render returns the text of the new node that replaces old. Unchanged values
+// are copied from the source, and changed objects and arrays are edited
+// element by element.
+func (e *jsonEditor) render(old, new *Node) string {
+	switch {
+	case reflect.DeepEqual(old.Interface(), new.Interface()):
+		return string(e.src[old.Offset:old.End])
+	case old.Kind == ObjectNode && new.Kind == ObjectNode && len(old.Fields) > 0:
+		return e.renderObject(old, new)
+	case old.Kind == ArrayNode && new.Kind == ArrayNode && len(old.Items) > 0:
+		return e.renderArray(old, new)
+	}
+	return e.fresh(new, lineIndent(e.src, old.Offset))
+}
+
+func (e *jsonEditor) renderObject(old, new *Node) string {
+	spans := make([][2]int, len(old.Fields))
+	for i, f := range old.Fields {
+		spans[i] = [2]int{f.KeyOffset, f.Value.End}
+	}
+	c := e.split(old, spans)
+
+	var elems []*element
+	for i, f := range old.Fields {
+		newValue := new.Get(f.Key)
+		if newValue == nil && isEmpty(f.Value) {
+			// Empty values that are omitted when marshalling, such as
+			// "env": {}, are still the same value.
+			newValue = f.Value
+		} else if newValue == nil {
+			continue
+		}
+		el := *c.elems[i]
+		el.body = string(e.src[f.KeyOffset:f.Value.Offset]) + e.render(f.Value, newValue)
+		elems = append(elems, &el)
+	}
+	for _, f := range new.Fields {
+		if old.Get(f.Key) != nil {
+			continue
+		}
+		el := c.newElement(e.src, len(elems))
+		key, _ := MarshalJSON(f.Key)
+		el.body = string(key) + ": " + e.fresh(f.Value, c.indent(e.src))
+		elems = append(elems, el)
+	}
+	return c.join(elems, '{', '}')
+}
+
+func (e *jsonEditor) renderArray(old, new *Node) string {
+	spans := make([][2]int, len(old.Items))
+	for i, item := range old.Items {
+		spans[i] = [2]int{item.Offset, item.End}
+	}
+	c := e.split(old, spans)
+
+	var elems []*element
+	for _, op := range diffItems(interfaces(old.Items), interfaces(new.Items)) {
+		switch {
+		case op.old >= 0 && op.new >= 0:
+			// A kept or replaced item keeps its comments and layout.
+			el := *c.elems[op.old]
+			el.body = e.render(old.Items[op.old], new.Items[op.new])
+			elems = append(elems, &el)
+		case op.new >= 0:
+			el := c.newElement(e.src, len(elems))
+			el.body = e.fresh(new.Items[op.new], c.indent(e.src))
+			elems = append(elems, el)
+		}
+	}
+	return c.join(elems, '[', ']')
+}
+
+// fresh returns the text of a node of the new value, indented to start on a
+// line with the given indentation.
+func (e *jsonEditor) fresh(n *Node, indent string) string {
+	text := string(e.newSrc[n.Offset:n.End])
+	base := len(lineIndent(e.newSrc, n.Offset))
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		// MarshalJSON indents each level with two spaces.
+		levels := (len(lines[i]) - len(trimmed) - base) / 2
+		if levels < 0 {
+			levels = 0
+		}
+		lines[i] = indent + strings.Repeat(e.unit, levels) + trimmed
+	}
+	return strings.Join(lines, e.newline)
+}
+
+// element is an item of an array or a field of an object, with the text
+// around it that moves with it when the array or object is edited.
+type element struct {
+	// lead is the indentation and comments on the lines before the element.
+	lead string
+	// body is the item, or the key and value of the field.
+	body string
+	// gap is the text between the body and its comma.
+	gap string
+	// tail is the text after the comma up to the end of the line, such as a
+	// comment about the element.
+	tail string
+}
+
+// container is the layout of an array or object.
+type container struct {
+	node *Node
+	// open is the text after the opening bracket up to the end of its line,
+	// and close is the text before the closing bracket.
+	open  string
+	close string
+	elems []*element
+	// trailingComma is whether the last element has a comma after it.
+	trailingComma bool
+	// newline ends the lines of added elements.
+	newline string
+}
+
+// split divides the text of an array or object into its elements, whose
+// bodies span the given offsets.
+func (e *jsonEditor) split(n *Node, spans [][2]int) *container {
+	c := &container{node: n, newline: e.newline}
+	before := string(e.src[n.Offset+1 : spans[0][0]])
+	c.open, before = cutLine(before)
+	for i, span := range spans {
+		el := &element{lead: before, body: string(e.src[span[0]:span[1]])}
+		end := n.End - 1
+		if i+1 < len(spans) {
+			end = spans[i+1][0]
+		}
+		after := string(e.src[span[1]:end])
+		if comma := findComma(after); comma >= 0 {
+			el.gap, after = after[:comma], after[comma+1:]
+			c.trailingComma = i == len(spans)-1
+		}
+		el.tail, before = cutLine(after)
+		c.elems = append(c.elems, el)
+	}
+	c.close = before
+	return c
+}
+
+// multiline reports whether the elements of the container are on their own
+// lines.
+func (c *container) multiline() bool {
+	if strings.Contains(c.open, "\n") {
+		return true
+	}
+	for _, el := range c.elems {
+		if strings.Contains(el.tail, "\n") {
+			return true
+		}
+	}
+	return false
+}
+
+// indent returns the indentation of the lines of the elements.
+func (c *container) indent(src []byte) string {
+	first := c.elems[0]
+	return lineIndent(src, c.node.Offset+1+len(c.open)+len(first.lead))
+}
+
+// newElement returns an element without a body that is laid out like the
+// existing elements, to be added at index.
+func (c *container) newElement(src []byte, index int) *element {
+	if c.multiline() {
+		return &element{lead: c.indent(src), tail: c.newline}
+	}
+	if index == 0 {
+		return &element{}
+	}
+	return &element{lead: " "}
+}
+
+// join returns the text of the container with elems.
+func (c *container) join(elems []*element, open, close byte) string {
+	if len(elems) == 0 {
+		return string([]byte{open, close})
+	}
+	buf := &strings.Builder{}
+	multiline := c.multiline()
+	buf.WriteByte(open)
+	buf.WriteString(c.open)
+	for i, el := range elems {
+		lead := el.lead
+		switch {
+		case !multiline && strings.TrimSpace(lead) == "":
+			// Elements on one line are separated by a space.
+			lead = " "
+			if i == 0 {
+				lead = c.elems[0].lead
+			}
+		case multiline && i > 0 && !strings.HasSuffix(elems[i-1].tail, "\n"):
+			// The element that was last didn't end its line.
+			lead = c.newline + lead
+		}
+		buf.WriteString(lead)
+		buf.WriteString(el.body)
+		buf.WriteString(el.gap)
+		if i < len(elems)-1 || c.trailingComma {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(el.tail)
+	}
+	buf.WriteString(c.close)
+	buf.WriteByte(close)
+	return buf.String()
+}
+
+// itemOp pairs an item of the old array with an item of the new array. An
+// index of -1 means the item was added or removed.
+type itemOp struct {
+	old, new int
+}
+
+func interfaces(nodes []*Node) []any {
+	values := make([]any, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.Interface()
+	}
+	return values
+}
+
+// diffItems matches the items of two arrays by their longest common
+// subsequence. Items that were removed where others were added are paired as
+// replacements so that the new items take their place.
+func diffItems(oldValues, newValues []any) []itemOp {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldValues[i:] and newValues[j:].
+	lcs := make([][]int, len(oldValues)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newValues)+1)
+	}
+	for i := len(oldValues) - 1; i >= 0; i-- {
+		for j := len(newValues) - 1; j >= 0; j-- {
+			if reflect.DeepEqual(oldValues[i], newValues[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []itemOp
+	var removed, added []int
+	flush := func() {
+		for k := 0; k < len(removed) || k < len(added); k++ {
+			op := itemOp{old: -1, new: -1}
+			if k < len(removed) {
+				op.old = removed[k]
+			}
+			if k < len(added) {
+				op.new = added[k]
+			}
+			ops = append(ops, op)
+		}
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(oldValues) || j < len(newValues) {
+		switch {
+		case i < len(oldValues) && j < len(newValues) && reflect.DeepEqual(oldValues[i], newValues[j]):
+			flush()
+			ops = append(ops, itemOp{old: i, new: j})
+			i++
+			j++
+		case j >= len(newValues) || (i < len(oldValues) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+	return ops
+}
+
+// isEmpty reports whether n is null, or an empty array or object.
+func isEmpty(n *Node) bool {
+	switch n.Kind {
+	case NullNode:
+		return true
+	case ArrayNode:
+		return len(n.Items) == 0
+	case ObjectNode:
+		return len(n.Fields) == 0
+	}
+	return false
+}
+
+// cutLine splits s after its first newline. If s has no newline, line is
+// empty.
+func cutLine(s string) (line, rest string) {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i+1], s[i+1:]
+	}
+	return "", s
+}
+
+// findComma returns the index of the comma in the text between two JSONC
+// values, or -1. The text only has whitespace, comments and commas.
+func findComma(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "//"):
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return -1
+			}
+			i += end
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return -1
+			}
+			i += end + 3
+		case s[i] == ',':
+			return i
+		}
+	}
+	return -1
+}
+
+// lineIndent returns the whitespace at the start of the line that offset is
+// on.
+func lineIndent(src []byte, offset int) string {
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
+
+// newline returns the line ending of the first line of src.
+func newline(src []byte) string {
+	if i := bytes.IndexByte(src, '\n'); i > 0 && src[i-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// indentUnit returns the indentation of the first indented line of src, which
+// is assumed to be one level of nesting.
+func indentUnit(src []byte) string {
+	for _, line := range bytes.Split(src, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) > 0 && len(trimmed) < len(line) {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+	return "  "
+}
@@ -0,0 +1,239 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type editTestConfig struct {
+	Packages []string          `json:"packages"`
+	Env      map[string]string `json:"env,omitempty"`
+	Shell    *editTestShell    `json:"shell,omitempty"`
+}
+
+type editTestShell struct {
+	InitHook []string          `json:"init_hook,omitempty"`
+	Scripts  map[string]string `json:"scripts,omitempty"`
+}
+
+func TestEditJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		edit func(cfg *editTestConfig)
+		want string
+	}{
+		{
+			name: "append package keeps comments",
+			src: `{
+  // Tools for the backend.
+  "packages": [
+    "go@1.20", // pinned for the CI image
+    "ripgrep@latest"
+  ],
+  "shell": {"init_hook": ["echo hi"]}
+}
+`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages = append(cfg.Packages, "jq@latest")
+			},
+			want: `{
+  // Tools for the backend.
+  "packages": [
+    "go@1.20", // pinned for the CI image
+    "ripgrep@latest",
+    "jq@latest"
+  ],
+  "shell": {"init_hook": ["echo hi"]}
+}
+`,
+		},
+		{
+			name: "remove package",
+			src: `{
+  "packages": [
+    "go@1.20", // pinned for the CI image
+    // Search.
+    "ripgrep@latest",
+    "jq@latest"
+  ]
+}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages = []string{"go@1.20", "jq@latest"}
+			},
+			want: `{
+  "packages": [
+    "go@1.20", // pinned for the CI image
+    "jq@latest"
+  ]
+}`,
+		},
+		{
+			name: "remove last package",
+			src: `{
+  "packages": [
+    "go@1.20",
+    "ripgrep@latest", // search
+  ],
+}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages = []string{"go@1.20"}
+			},
+			want: `{
+  "packages": [
+    "go@1.20",
+  ],
+}`,
+		},
+		{
+			name: "replace package keeps its comment",
+			src: `{
+  "packages": [
+    "go@1.19", // pinned for the CI image
+    "ripgrep@latest"
+  ]
+}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages[0] = "go@1.20"
+			},
+			want: `{
+  "packages": [
+    "go@1.20", // pinned for the CI image
+    "ripgrep@latest"
+  ]
+}`,
+		},
+		{
+			name: "one line array",
+			src:  `{"packages": ["go@1.20", "ripgrep@latest"]}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages = []string{"jq@latest", "go@1.20"}
+			},
+			want: `{"packages": ["jq@latest", "go@1.20"]}`,
+		},
+		{
+			name: "empty array",
+			src: `{
+    "packages": [],
+    "env": {}
+}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages = []string{"go@1.20"}
+			},
+			want: `{
+    "packages": [
+        "go@1.20"
+    ],
+    "env": {}
+}`,
+		},
+		{
+			name: "remove every package",
+			src:  "{\n  \"packages\": [\n    \"go@1.20\"\n  ]\n}",
+			edit: func(cfg *editTestConfig) {
+				cfg.Packages = []string{}
+			},
+			want: "{\n  \"packages\": []\n}",
+		},
+		{
+			name: "add field keeps key order",
+			src: `{
+    "shell": {
+        "scripts": {"test": "go test ./..."}
+    },
+    "packages": ["go@1.20"]
+}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Env = map[string]string{"GOFLAGS": "-mod=mod"}
+				cfg.Shell.InitHook = []string{"echo hi"}
+			},
+			want: `{
+    "shell": {
+        "scripts": {"test": "go test ./..."},
+        "init_hook": [
+            "echo hi"
+        ]
+    },
+    "packages": ["go@1.20"],
+    "env": {
+        "GOFLAGS": "-mod=mod"
+    }
+}`,
+		},
+		{
+			name: "remove field",
+			src: `{
+  "packages": [],
+  /* Set by the team lead. */
+  "env": {
+    "FOO": "bar"
+  },
+  "shell": {}
+}`,
+			edit: func(cfg *editTestConfig) {
+				cfg.Env = nil
+			},
+			want: `{
+  "packages": [],
+  "shell": {}
+}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &editTestConfig{}
+			require.NoError(t, Unmarshal([]byte(tc.src), ".json", cfg))
+			tc.edit(cfg)
+			got, err := EditJSON([]byte(tc.src), cfg)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(got))
+
+			roundTrip := &editTestConfig{}
+			require.NoError(t, Unmarshal(got, ".json", roundTrip))
+			require.Equal(t, cfg, roundTrip)
+		})
+	}
+}
+
+func TestEditJSONUnchanged(t *testing.T) {
+	src := "{\n\t// Comment.\n\t\"packages\": [\"go\"],\n\t\"env\": {\"A\": \"b\",},\n}\n"
+	cfg := &editTestConfig{}
+	require.NoError(t, Unmarshal([]byte(src), ".json", cfg))
+	got, err := EditJSON([]byte(src), cfg)
+	require.NoError(t, err)
+	require.Equal(t, src, string(got))
+}
+
+func TestEditJSONCRLF(t *testing.T) {
+	crlf := func(s string) string { return strings.ReplaceAll(s, "\n", "\r\n") }
+	src := crlf(`{
+  "packages": [
+    "go@1.20" // pinned for the CI image
+  ]
+}
+`)
+	cfg := &editTestConfig{}
+	require.NoError(t, Unmarshal([]byte(src), ".json", cfg))
+	cfg.Packages = append(cfg.Packages, "jq@latest")
+	cfg.Env = map[string]string{"GOFLAGS": "-mod=mod"}
+	got, err := EditJSON([]byte(src), cfg)
+	require.NoError(t, err)
+
+	// Added lines use the file's line endings.
+	require.Equal(t, crlf(`{
+  "packages": [
+    "go@1.20", // pinned for the CI image
+    "jq@latest"
+  ],
+  "env": {
+    "GOFLAGS": "-mod=mod"
+  }
+}
+`), string(got))
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Position is a location in a config file. Lines and columns start at 1, and
+// a zero Position means the location is unknown.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// NodeKind is the JSON type of a Node.
+type NodeKind int
+
+const (
+	NullNode NodeKind = iota
+	BoolNode
+	NumberNode
+	StringNode
+	ArrayNode
+	ObjectNode
+)
+
+func (k NodeKind) String() string {
+	switch k {
+	case NullNode:
+		return "null"
+	case BoolNode:
+		return "boolean"
+	case NumberNode:
+		return "number"
+	case StringNode:
+		return "string"
+	case ArrayNode:
+		return "array"
+	case ObjectNode:
+		return "object"
+	}
+	return fmt.Sprintf("NodeKind(%d)", int(k))
+}
+
+// Node is a value in a config file and where it is, so that errors about the
+// value can point to it. JSON, YAML and TOML files all parse to the same
+// kinds of nodes.
+type Node struct {
+	Kind NodeKind
+	Pos  Position
+	// Offset and End are the byte offsets of the start and end of the node
+	// in the file. They're only set for JSON files.
+	Offset int
+	End    int
+
+	// Scalar is the value of a bool (bool), number (float64) or string
+	// (string) node.
+	Scalar any
+	// Items are the elements of an array node.
+	Items []*Node
+	// Fields are the fields of an object node, in the order of the file.
+	Fields []*Field
+}
+
+// Field is a key and value of an object node.
+type Field struct {
+	Key    string
+	KeyPos Position
+	// KeyOffset is the byte offset of the key in the file. It's only set for
+	// JSON files.
+	KeyOffset int
+	Value     *Node
+}
+
+// Get returns the value of the field named key of an object node, or nil.
+func (n *Node) Get(key string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, f := range n.Fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// Interface returns the node as the values that encoding/json decodes into
+// an any: nil, bool, float64, string, []any and map[string]any.
+func (n *Node) Interface() any {
+	switch n.Kind {
+	case ArrayNode:
+		items := make([]any, len(n.Items))
+		for i, item := range n.Items {
+			items[i] = item.Interface()
+		}
+		return items
+	case ObjectNode:
+		fields := make(map[string]any, len(n.Fields))
+		for _, f := range n.Fields {
+			fields[f.Key] = f.Value.Interface()
+		}
+		return fields
+	}
+	return n.Scalar
+}
+
+// SyntaxError is an error parsing a config file at a position.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Pos.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// ParseTree parses a JSON, YAML or TOML file, chosen by its extension, into
+// nodes that remember their position. Syntax errors are returned as a
+// *SyntaxError.
+func ParseTree(data []byte, extension string) (*Node, error) {
+	switch extension {
+	case ".json", ".lock":
+		return parseJSONTree(data)
+	case ".yml", ".yaml":
+		return parseYAMLTree(data)
+	case ".toml":
+		return parseTOMLTree(data)
+	}
+	return nil, errors.Errorf("Unsupported file format '%s' for config file", extension)
+}
+
+// positionAt returns the position of a byte offset in data.
+func positionAt(data []byte, offset int) Position {
+	pos := Position{Line: 1, Column: 1}
+	for i := 0; i < offset && i < len(data); i++ {
+		if data[i] == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else if data[i]&0xC0 != 0x80 {
+			// Count runes rather than bytes by skipping UTF-8
+			// continuation bytes.
+			pos.Column++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// jsonTreeParser builds a tree from the tokens of a json.Decoder. The decoder
+// only reports the offset after each token, so the start of a token is found
+// by skipping the whitespace and separators before it.
+type jsonTreeParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// parseJSONTree parses JSON, or JSONC with comments and trailing commas.
+func parseJSONTree(data []byte) (*Node, error) {
+	data = stripJSONC(data)
+	p := &jsonTreeParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+	n, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		return nil, p.errorAt(p.tokenStart(), "unexpected data after the end of the config")
+	}
+	return n, nil
+}
+
+func (p *jsonTreeParser) parseValue() (*Node, error) {
+	start := p.tokenStart()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, p.syntaxError(err, start)
+	}
+	n := &Node{Pos: positionAt(p.data, start), Offset: start}
+	switch tok := tok.(type) {
+	case nil:
+		n.Kind = NullNode
+	case bool:
+		n.Kind, n.Scalar = BoolNode, tok
+	case json.Number:
+		f, err := tok.Float64()
+		if err != nil {
+			return nil, p.errorAt(start, fmt.Sprintf("invalid number %s", tok))
+		}
+		n.Kind, n.Scalar = NumberNode, f
+	case string:
+		n.Kind, n.Scalar = StringNode, tok
+	case json.Delim:
+		switch tok {
+		case '[':
+			n.Kind = ArrayNode
+			for p.dec.More() {
+				item, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				n.Items = append(n.Items, item)
+			}
+		case '{':
+			n.Kind = ObjectNode
+			for p.dec.More() {
+				keyStart := p.tokenStart()
+				key, err := p.dec.Token()
+				if err != nil {
+					return nil, p.syntaxError(err, keyStart)
+				}
+				if n.Get(key.(string)) != nil {
+					return nil, p.errorAt(keyStart, fmt.Sprintf("duplicate key %q", key))
+				}
+				value, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				n.Fields = append(n.Fields, &Field{
+					Key:       key.(string),
+					KeyPos:    positionAt(p.data, keyStart),
+					KeyOffset: keyStart,
+					Value:     value,
+				})
+			}
+		}
+		// Consume the closing delimiter.
+		end := p.tokenStart()
+		if _, err := p.dec.Token(); err != nil {
+			return nil, p.syntaxError(err, end)
+		}
+	}
+	n.End = int(p.dec.InputOffset())
+	return n, nil
+}
+
+// tokenStart returns the offset of the next token.
+func (p *jsonTreeParser) tokenStart() int {
+	i := int(p.dec.InputOffset())
+	for i < len(p.data) {
+		switch p.data[i] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func (p *jsonTreeParser) syntaxError(err error, offset int) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset of a SyntaxError is just after the invalid byte.
+		offset = int(syntaxErr.Offset) - 1
+		if offset < 0 {
+			offset = 0
+		}
+		return p.errorAt(offset, syntaxErr.Error())
+	}
+	if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+		return p.errorAt(len(p.data), "unexpected end of the config")
+	}
+	return p.errorAt(offset, err.Error())
+}
+
+func (p *jsonTreeParser) errorAt(offset int, msg string) error {
+	return &SyntaxError{Pos: positionAt(p.data, offset), Msg: msg}
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTree(t *testing.T) {
+	testCases := []struct {
+		name string
+		ext  string
+		data string
+	}{
+		{
+			name: "json",
+			ext:  ".json",
+			data: `{
+  "packages": ["go", "ripgrep"],
+  "shell": {
+    "scripts": {"test": "go test"}
+  },
+  "n": 3
+}`,
+		},
+		{
+			name: "yaml",
+			ext:  ".yaml",
+			data: `packages:
+  - go
+  - ripgrep
+shell:
+  scripts:
+    test: go test
+n: 3
+`,
+		},
+		{
+			name: "toml",
+			ext:  ".toml",
+			data: `packages = ["go", "ripgrep"]
+n = 3
+
+[shell.scripts]
+test = "go test"
+`,
+		},
+	}
+	want := map[string]any{
+		"packages": []any{"go", "ripgrep"},
+		"shell":    map[string]any{"scripts": map[string]any{"test": "go test"}},
+		"n":        3.0,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := ParseTree([]byte(tc.data), tc.ext)
+			require.NoError(t, err)
+			require.Equal(t, want, tree.Interface())
+		})
+	}
+}
+
+func TestParseTreePositions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ext        string
+		data       string
+		keyPos     Position
+		packagePos Position
+	}{
+		{
+			name:       "json",
+			ext:        ".json",
+			data:       "{\n  \"shell\": {},\n  \"packages\": [\"go\", \"ripgrep\"]\n}",
+			keyPos:     Position{Line: 3, Column: 3},
+			packagePos: Position{Line: 3, Column: 22},
+		},
+		{
+			name:       "yaml",
+			ext:        ".yaml",
+			data:       "shell: {}\npackages:\n  - go\n  - ripgrep\n",
+			keyPos:     Position{Line: 2, Column: 1},
+			packagePos: Position{Line: 4, Column: 5},
+		},
+		{
+			name:       "toml",
+			ext:        ".toml",
+			data:       "packages = [\"go\", \"ripgrep\"]\n\n[shell]\n",
+			keyPos:     Position{Line: 1, Column: 1},
+			packagePos: Position{Line: 1, Column: 19},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := ParseTree([]byte(tc.data), tc.ext)
+			require.NoError(t, err)
+			var field *Field
+			for _, f := range tree.Fields {
+				if f.Key == "packages" {
+					field = f
+				}
+			}
+			require.NotNil(t, field)
+			require.Equal(t, tc.keyPos, field.KeyPos)
+			require.Len(t, field.Value.Items, 2)
+			require.Equal(t, tc.packagePos, field.Value.Items[1].Pos)
+		})
+	}
+}
+
+func TestParseTreeSyntaxError(t *testing.T) {
+	testCases := []struct {
+		name string
+		ext  string
+		data string
+		pos  Position
+	}{
+		{
+			name: "json",
+			ext:  ".json",
+			data: "{\n  \"packages\": [\"go\" \"ripgrep\"]\n}",
+			pos:  Position{Line: 2, Column: 21},
+		},
+		{
+			name: "json duplicate key",
+			ext:  ".json",
+			data: "{\n  \"env\": {},\n  \"env\": {}\n}",
+			pos:  Position{Line: 3, Column: 3},
+		},
+		{
+			name: "yaml",
+			ext:  ".yaml",
+			data: "packages:\n  - go\n - ripgrep\n",
+			// yaml.v3 reports the line where the sequence started.
+			pos: Position{Line: 2, Column: 1},
+		},
+		{
+			name: "toml",
+			ext:  ".toml",
+			data: "packages = [\"go\"]\nenv = \n",
+			pos:  Position{Line: 2, Column: 7},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseTree([]byte(tc.data), tc.ext)
+			var syntaxErr *SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			require.Equal(t, tc.pos, syntaxErr.Pos, syntaxErr.Msg)
+		})
+	}
+}
+
+func TestParseTreeSupportedExtensions(t *testing.T) {
+	// An empty config in each format.
+	configs := map[string]string{
+		".json": "{}",
+		".lock": "{}",
+		".yml":  "{}",
+		".yaml": "{}",
+		".toml": "",
+		".xml":  "<config></config>",
+		".cue":  "{}",
+	}
+	for ext, data := range configs {
+		_, err := ParseTree([]byte(data), ext)
+		require.Equal(t, IsSupportedExtension(ext), err == nil, ext)
+	}
+}
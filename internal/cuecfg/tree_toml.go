@@ -0,0 +1,193 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/pkg/errors"
+)
+
+// tomlTreeParser builds a tree from the expressions of a TOML document. Each
+// [table] and [[array table]] header changes the object that the key/value
+// pairs after it are added to.
+type tomlTreeParser struct {
+	data   []byte
+	parser unstable.Parser
+	root   *Node
+}
+
+func parseTOMLTree(data []byte) (*Node, error) {
+	p := &tomlTreeParser{
+		data: data,
+		root: &Node{Kind: ObjectNode, Pos: Position{Line: 1, Column: 1}},
+	}
+	p.parser.Reset(data)
+	current := p.root
+	for p.parser.NextExpression() {
+		expr := p.parser.Expression()
+		var err error
+		switch expr.Kind {
+		case unstable.KeyValue:
+			err = p.addKeyValue(current, expr)
+		case unstable.Table:
+			current, err = p.table(expr)
+		case unstable.ArrayTable:
+			current, err = p.arrayTable(expr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := p.parser.Error(); err != nil {
+		var parserErr *unstable.ParserError
+		if errors.As(err, &parserErr) {
+			return nil, &SyntaxError{
+				Pos: p.pos(p.parser.Range(parserErr.Highlight)),
+				Msg: parserErr.Message,
+			}
+		}
+		return nil, &SyntaxError{Msg: err.Error()}
+	}
+	return p.root, nil
+}
+
+// addKeyValue adds a key/value pair, whose key may be dotted, to obj.
+func (p *tomlTreeParser) addKeyValue(obj *Node, expr *unstable.Node) error {
+	keys := p.keys(expr.Key())
+	parent, err := p.walk(obj, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if parent.Get(last.Key) != nil {
+		return &SyntaxError{Pos: last.KeyPos, Msg: fmt.Sprintf("duplicate key %q", last.Key)}
+	}
+	value, err := p.value(expr.Value(), last.KeyPos)
+	if err != nil {
+		return err
+	}
+	last.Value = value
+	parent.Fields = append(parent.Fields, last)
+	return nil
+}
+
+func (p *tomlTreeParser) table(expr *unstable.Node) (*Node, error) {
+	return p.walk(p.root, p.keys(expr.Key()))
+}
+
+func (p *tomlTreeParser) arrayTable(expr *unstable.Node) (*Node, error) {
+	keys := p.keys(expr.Key())
+	parent, err := p.walk(p.root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	array := parent.Get(last.Key)
+	if array == nil {
+		array = &Node{Kind: ArrayNode, Pos: last.KeyPos}
+		last.Value = array
+		parent.Fields = append(parent.Fields, last)
+	} else if array.Kind != ArrayNode {
+		return nil, &SyntaxError{Pos: last.KeyPos, Msg: fmt.Sprintf("%q is not an array of tables", last.Key)}
+	}
+	table := &Node{Kind: ObjectNode, Pos: last.KeyPos}
+	array.Items = append(array.Items, table)
+	return table, nil
+}
+
+// walk returns the object at the path of keys under obj, creating the
+// objects that don't exist yet. The last table of an array of tables is used
+// for keys that name one.
+func (p *tomlTreeParser) walk(obj *Node, keys []*Field) (*Node, error) {
+	for _, key := range keys {
+		child := obj.Get(key.Key)
+		if child == nil {
+			child = &Node{Kind: ObjectNode, Pos: key.KeyPos}
+			key.Value = child
+			obj.Fields = append(obj.Fields, key)
+		}
+		if child.Kind == ArrayNode && len(child.Items) > 0 {
+			child = child.Items[len(child.Items)-1]
+		}
+		if child.Kind != ObjectNode {
+			return nil, &SyntaxError{Pos: key.KeyPos, Msg: fmt.Sprintf("%q is not a table", key.Key)}
+		}
+		obj = child
+	}
+	return obj, nil
+}
+
+func (p *tomlTreeParser) keys(it unstable.Iterator) []*Field {
+	var keys []*Field
+	for it.Next() {
+		key := it.Node()
+		keys = append(keys, &Field{Key: string(key.Data), KeyPos: p.pos(key.Raw)})
+	}
+	return keys
+}
+
+// value converts a TOML value. Not every value records where it is, so those
+// that don't use the position of their key.
+func (p *tomlTreeParser) value(v *unstable.Node, keyPos Position) (*Node, error) {
+	n := &Node{Pos: keyPos}
+	if v.Raw.Length > 0 {
+		n.Pos = p.pos(v.Raw)
+	}
+	switch v.Kind {
+	case unstable.String:
+		n.Kind, n.Scalar = StringNode, string(v.Data)
+	case unstable.Bool:
+		n.Kind, n.Scalar = BoolNode, string(v.Data) == "true"
+	case unstable.Integer, unstable.Float:
+		f, err := parseTOMLNumber(string(v.Data))
+		if err != nil {
+			return nil, &SyntaxError{Pos: n.Pos, Msg: err.Error()}
+		}
+		n.Kind, n.Scalar = NumberNode, f
+	case unstable.LocalDate, unstable.LocalTime, unstable.LocalDateTime, unstable.DateTime:
+		n.Kind, n.Scalar = StringNode, string(v.Data)
+	case unstable.Array:
+		n.Kind = ArrayNode
+		it := v.Children()
+		for it.Next() {
+			item, err := p.value(it.Node(), n.Pos)
+			if err != nil {
+				return nil, err
+			}
+			n.Items = append(n.Items, item)
+		}
+	case unstable.InlineTable:
+		n.Kind = ObjectNode
+		it := v.Children()
+		for it.Next() {
+			if err := p.addKeyValue(n, it.Node()); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, &SyntaxError{Pos: n.Pos, Msg: fmt.Sprintf("unsupported TOML value %s", v.Kind)}
+	}
+	return n, nil
+}
+
+func parseTOMLNumber(s string) (float64, error) {
+	s = strings.ReplaceAll(s, "_", "")
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0o"), strings.HasPrefix(s, "0b"):
+		i, err := strconv.ParseInt(s, 0, 64)
+		return float64(i), errors.WithStack(err)
+	case s == "inf" || s == "+inf" || s == "-inf" || strings.HasSuffix(s, "nan"):
+		return 0, errors.Errorf("unsupported number %s", s)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, errors.WithStack(err)
+}
+
+func (p *tomlTreeParser) pos(r unstable.Range) Position {
+	return positionAt(p.data, int(r.Offset))
+}
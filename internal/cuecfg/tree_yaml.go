@@ -0,0 +1,99 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package cuecfg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+func parseYAMLTree(data []byte) (*Node, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, yamlSyntaxError(err)
+	}
+	if len(doc.Content) == 0 {
+		// An empty document is an empty config.
+		return &Node{Kind: ObjectNode, Pos: Position{Line: 1, Column: 1}}, nil
+	}
+	return convertYAML(doc.Content[0])
+}
+
+func convertYAML(y *yaml.Node) (*Node, error) {
+	n := &Node{Pos: Position{Line: y.Line, Column: y.Column}}
+	switch y.Kind {
+	case yaml.AliasNode:
+		return convertYAML(y.Alias)
+	case yaml.SequenceNode:
+		n.Kind = ArrayNode
+		for _, item := range y.Content {
+			child, err := convertYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			n.Items = append(n.Items, child)
+		}
+	case yaml.MappingNode:
+		n.Kind = ObjectNode
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			key, value := y.Content[i], y.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, &SyntaxError{
+					Pos: Position{Line: key.Line, Column: key.Column},
+					Msg: "keys must be strings",
+				}
+			}
+			if n.Get(key.Value) != nil {
+				return nil, &SyntaxError{
+					Pos: Position{Line: key.Line, Column: key.Column},
+					Msg: fmt.Sprintf("duplicate key %q", key.Value),
+				}
+			}
+			child, err := convertYAML(value)
+			if err != nil {
+				return nil, err
+			}
+			n.Fields = append(n.Fields, &Field{
+				Key:    key.Value,
+				KeyPos: Position{Line: key.Line, Column: key.Column},
+				Value:  child,
+			})
+		}
+	case yaml.ScalarNode:
+		switch y.ShortTag() {
+		case "!!null":
+			n.Kind = NullNode
+		case "!!bool":
+			b := false
+			if err := y.Decode(&b); err != nil {
+				return nil, &SyntaxError{Pos: n.Pos, Msg: err.Error()}
+			}
+			n.Kind, n.Scalar = BoolNode, b
+		case "!!int", "!!float":
+			f := 0.0
+			if err := y.Decode(&f); err != nil {
+				return nil, &SyntaxError{Pos: n.Pos, Msg: err.Error()}
+			}
+			n.Kind, n.Scalar = NumberNode, f
+		default:
+			n.Kind, n.Scalar = StringNode, y.Value
+		}
+	default:
+		return nil, &SyntaxError{Pos: n.Pos, Msg: "unsupported YAML value"}
+	}
+	return n, nil
+}
+
+var yamlLineRegex = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func yamlSyntaxError(err error) error {
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &SyntaxError{Pos: Position{Line: line, Column: 1}, Msg: m[2]}
+	}
+	return &SyntaxError{Msg: err.Error()}
+}
@@ -6,6 +6,7 @@ package devconfig
 import (
 	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -20,6 +21,10 @@ const DefaultName = "devbox.json"
 
 // Config defines a devbox environment as JSON.
 type Config struct {
+	// Schema is the URL of the JSON Schema that the config follows, which
+	// editors use to validate and complete it. See SchemaURL.
+	Schema string `json:"$schema,omitempty"`
+
 	// Packages is the slice of Nix packages that devbox makes available in
 	// its environment. Deliberately do not omitempty.
 	Packages []string `cue:"[...string]" json:"packages"`
@@ -38,6 +43,13 @@ type Config struct {
 	// plugin: for built-in plugins
 	// This is a similar format to nix inputs
 	Include []string `json:"include,omitempty"`
+
+	// BinaryCaches adds nix substituters that packages can be downloaded from.
+	BinaryCaches *BinaryCacheConfig `json:"binary_caches,omitempty"`
+
+	// Overrides customize how packages are built, keyed by package (e.g.
+	// `python@3.11`) or package name (e.g. `python`).
+	Overrides map[string]*Override `json:"overrides,omitempty"`
 }
 
 type shellConfig struct {
@@ -46,6 +58,15 @@ type shellConfig struct {
 	Scripts  map[string]*shellcmd.Commands `json:"scripts,omitempty"`
 }
 
+type BinaryCacheConfig struct {
+	// Substituters are URLs of binary caches, e.g. s3://my-cache?region=us-east-1
+	// or https://cache.example.com.
+	Substituters []string `json:"substituters,omitempty"`
+	// TrustedPublicKeys verify the signatures of paths from the substituters,
+	// e.g. cache.example.com-1:base64key.
+	TrustedPublicKeys []string `json:"trusted_public_keys,omitempty"`
+}
+
 type NixpkgsConfig struct {
 	Commit string `json:"commit,omitempty"`
 }
@@ -77,6 +98,18 @@ func (c *Config) Hash() (string, error) {
 	return cuecfg.Hash(c)
 }
 
+// NixHash returns a hash of the parts of the config that determine its Nix
+// environment. It leaves out the fields that devbox applies without Nix, such
+// as env, init_hook and scripts, so that changing them doesn't require
+// installing packages or evaluating the flake again.
+func (c *Config) NixHash() (string, error) {
+	nixCfg := *c
+	nixCfg.Schema = ""
+	nixCfg.Env = nil
+	nixCfg.Shell = nil
+	return cuecfg.Hash(&nixCfg)
+}
+
 func (c *Config) Equals(other *Config) bool {
 	hash1, _ := c.Hash()
 	hash2, _ := other.Hash()
@@ -93,6 +126,22 @@ func (c *Config) NixPkgsCommitHash() string {
 	return c.Nixpkgs.Commit
 }
 
+// Substituters returns the extra binary caches declared in the config.
+func (c *Config) Substituters() []string {
+	if c == nil || c.BinaryCaches == nil {
+		return nil
+	}
+	return c.BinaryCaches.Substituters
+}
+
+// TrustedPublicKeys returns the keys for the binary caches in the config.
+func (c *Config) TrustedPublicKeys() []string {
+	if c == nil || c.BinaryCaches == nil {
+		return nil
+	}
+	return c.BinaryCaches.TrustedPublicKeys
+}
+
 func (c *Config) Scripts() map[string]*shellcmd.Commands {
 	if c == nil || c.Shell == nil {
 		return nil
@@ -107,15 +156,45 @@ func (c *Config) InitHook() *shellcmd.Commands {
 	return c.Shell.InitHook
 }
 
-// SaveTo writes the config to a file.
+// AppendInitHook appends each line of script to the shell's init hook.
+func (c *Config) AppendInitHook(script string) {
+	if c.Shell == nil {
+		c.Shell = &shellConfig{}
+	}
+	if c.Shell.InitHook == nil {
+		c.Shell.InitHook = &shellcmd.Commands{}
+	}
+	c.Shell.InitHook.AppendScript(script)
+}
+
+// SaveTo writes the config to the config file in the directory at path, in
+// the file's format, or to a new devbox.json. Only the parts of an existing
+// file that changed are rewritten, so its comments and formatting are kept.
 func (c *Config) SaveTo(path string) error {
-	cfgPath := filepath.Join(path, DefaultName)
-	return cuecfg.WriteFile(cfgPath, c)
+	cfgPath, err := pathIn(path)
+	if err != nil {
+		return err
+	}
+	return cuecfg.EditFile(cfgPath, c)
 }
 
 func readConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseConfig(data, filepath.Ext(path), path)
+}
+
+// parseConfig checks the config in data against the schema of a Config before
+// unmarshalling it, so that unknown fields and values of the wrong type are
+// errors instead of being ignored. file names the config in errors.
+func parseConfig(data []byte, ext, file string) (*Config, error) {
+	if err := checkSchema(data, ext, file); err != nil {
+		return nil, err
+	}
 	cfg := &Config{}
-	return cfg, errors.WithStack(cuecfg.ParseFile(path, cfg))
+	return cfg, errors.WithStack(cuecfg.UnmarshalByJSON(data, ext, cfg))
 }
 
 // Load reads a devbox config file, and validates it.
@@ -133,7 +212,6 @@ func LoadConfigFromURL(url string) (*Config, error) {
 		return nil, errors.WithStack(err)
 	}
 	defer res.Body.Close()
-	cfg := &Config{}
 	data, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -142,8 +220,9 @@ func LoadConfigFromURL(url string) (*Config, error) {
 	if !cuecfg.IsSupportedExtension(ext) {
 		ext = ".json"
 	}
-	if err = cuecfg.Unmarshal(data, ext, cfg); err != nil {
-		return nil, errors.WithStack(err)
+	cfg, err := parseConfig(data, ext, url)
+	if err != nil {
+		return nil, err
 	}
 	return cfg, validateConfig(cfg)
 }
@@ -154,13 +233,14 @@ func WriteConfig(path string, cfg *Config) error {
 	if err != nil {
 		return err
 	}
-	return cuecfg.WriteFile(path, cfg)
+	return cuecfg.EditFile(path, cfg)
 }
 
 func validateConfig(cfg *Config) error {
 	fns := []func(cfg *Config) error{
 		ValidateNixpkg,
 		validateScripts,
+		validateOverrides,
 	}
 
 	for _, fn := range fns {
@@ -0,0 +1,72 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveToKeepsComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, DefaultName)
+	src := `{
+  // The tools we need.
+  "packages": [
+    "go@1.20", // matches go.mod
+  ],
+  "shell": {
+    "init_hook": "echo 'Welcome!'",
+    "scripts": {
+      "test": ["go test ./..."]
+    }
+  }
+}
+`
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	cfg.Packages = append(cfg.Packages, "golangci-lint@latest")
+	require.NoError(t, cfg.SaveTo(dir))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  // The tools we need.
+  "packages": [
+    "go@1.20", // matches go.mod
+    "golangci-lint@latest",
+  ],
+  "shell": {
+    "init_hook": "echo 'Welcome!'",
+    "scripts": {
+      "test": ["go test ./..."]
+    }
+  }
+}
+`, string(got))
+}
+
+func TestNixHashIgnoresEnvAndShell(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Packages = []string{"go@1.20"}
+	hash, err := cfg.NixHash()
+	require.NoError(t, err)
+
+	cfg.Env = map[string]string{"FOO": "bar"}
+	cfg.Shell.Scripts = nil
+	cfg.Schema = SchemaURL
+	changed, err := cfg.NixHash()
+	require.NoError(t, err)
+	require.Equal(t, hash, changed)
+
+	cfg.Packages = append(cfg.Packages, "jq")
+	changed, err = cfg.NixHash()
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changed)
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Diff is the difference in packages and env between two configs.
+type Diff struct {
+	AddedPackages   []string
+	RemovedPackages []string
+	// SetEnv are the env variables that were added or changed.
+	SetEnv map[string]string
+	// UnsetEnv are the env variables that were removed.
+	UnsetEnv []string
+}
+
+// DiffConfigs returns the changes to packages and env that turn from into to.
+// Either config may be nil, which is the same as an empty config.
+func DiffConfigs(from, to *Config) *Diff {
+	from, to = orEmpty(from), orEmpty(to)
+	d := &Diff{SetEnv: map[string]string{}}
+	for _, pkg := range to.Packages {
+		if !slices.Contains(from.Packages, pkg) {
+			d.AddedPackages = append(d.AddedPackages, pkg)
+		}
+	}
+	for _, pkg := range from.Packages {
+		if !slices.Contains(to.Packages, pkg) {
+			d.RemovedPackages = append(d.RemovedPackages, pkg)
+		}
+	}
+	for k, v := range to.Env {
+		if old, ok := from.Env[k]; !ok || old != v {
+			d.SetEnv[k] = v
+		}
+	}
+	for k := range from.Env {
+		if _, ok := to.Env[k]; !ok {
+			d.UnsetEnv = append(d.UnsetEnv, k)
+		}
+	}
+	sort.Strings(d.UnsetEnv)
+	return d
+}
+
+// IsEmpty reports whether the configs have the same packages and env.
+func (d *Diff) IsEmpty() bool {
+	return len(d.AddedPackages) == 0 && len(d.RemovedPackages) == 0 &&
+		len(d.SetEnv) == 0 && len(d.UnsetEnv) == 0
+}
+
+// String lists the changes one per line, prefixed by + for additions, - for
+// removals and ~ for changed env variables.
+func (d *Diff) String() string {
+	sb := strings.Builder{}
+	for _, pkg := range d.AddedPackages {
+		fmt.Fprintf(&sb, "+ %s\n", pkg)
+	}
+	for _, pkg := range d.RemovedPackages {
+		fmt.Fprintf(&sb, "- %s\n", pkg)
+	}
+	for _, k := range sortedKeys(d.SetEnv) {
+		fmt.Fprintf(&sb, "~ env %s=%s\n", k, d.SetEnv[k])
+	}
+	for _, k := range d.UnsetEnv {
+		fmt.Fprintf(&sb, "- env %s\n", k)
+	}
+	return sb.String()
+}
+
+// CommitMessage describes the changes as a commit message, with a subject
+// that summarizes the package changes and a body that lists every change.
+func (d *Diff) CommitMessage() string {
+	var parts []string
+	if len(d.AddedPackages) > 0 {
+		parts = append(parts, "add "+strings.Join(d.AddedPackages, ", "))
+	}
+	if len(d.RemovedPackages) > 0 {
+		parts = append(parts, "remove "+strings.Join(d.RemovedPackages, ", "))
+	}
+	if len(d.SetEnv) > 0 || len(d.UnsetEnv) > 0 {
+		parts = append(parts, "update env")
+	}
+	if len(parts) == 0 {
+		return "devbox: update global config"
+	}
+	return fmt.Sprintf("devbox: %s\n\n%s", strings.Join(parts, "; "), d.String())
+}
+
+func orEmpty(cfg *Config) *Config {
+	if cfg == nil {
+		return &Config{}
+	}
+	return cfg
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	from := &Config{
+		Packages: []string{"go@1.20", "ripgrep"},
+		Env:      map[string]string{"EDITOR": "vim", "PAGER": "less"},
+	}
+	to := &Config{
+		Packages: []string{"go@1.21", "ripgrep", "jq"},
+		Env:      map[string]string{"EDITOR": "nvim", "GOPATH": "/go"},
+	}
+
+	d := DiffConfigs(from, to)
+	require.Equal(t, []string{"go@1.21", "jq"}, d.AddedPackages)
+	require.Equal(t, []string{"go@1.20"}, d.RemovedPackages)
+	require.Equal(t, map[string]string{"EDITOR": "nvim", "GOPATH": "/go"}, d.SetEnv)
+	require.Equal(t, []string{"PAGER"}, d.UnsetEnv)
+	require.Equal(t,
+		"devbox: add go@1.21, jq; remove go@1.20; update env\n\n"+
+			"+ go@1.21\n+ jq\n- go@1.20\n~ env EDITOR=nvim\n~ env GOPATH=/go\n- env PAGER\n",
+		d.CommitMessage(),
+	)
+
+	require.True(t, DiffConfigs(to, to).IsEmpty())
+	require.Equal(t, "devbox: update global config", DiffConfigs(to, to).CommitMessage())
+	require.Equal(t, []string{"go@1.21", "ripgrep", "jq"}, DiffConfigs(nil, to).AddedPackages)
+}
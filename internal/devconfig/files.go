@@ -0,0 +1,76 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/fileutil"
+)
+
+// FileNames are the names that a project's config file can have. A project
+// has exactly one of them.
+var FileNames = []string{DefaultName, "devbox.yaml", "devbox.toml"}
+
+// Formats are the formats that a config file can be written in, which are
+// the extensions of FileNames.
+var Formats = []string{"json", "yaml", "toml"}
+
+// IsConfigName reports whether name is the name of a config file.
+func IsConfigName(name string) bool {
+	for _, n := range FileNames {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// NameForFormat returns the name of a config file in format, which is json,
+// yaml or toml.
+func NameForFormat(format string) (string, error) {
+	for _, name := range FileNames {
+		if strings.TrimPrefix(filepath.Ext(name), ".") == format {
+			return name, nil
+		}
+	}
+	return "", usererr.New(
+		"Unsupported config format %q. Use one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// Find returns the path of the config file in dir. It returns an error that
+// matches fs.ErrNotExist if dir has none, and an error if it has more than
+// one, since devbox wouldn't know which to use.
+func Find(dir string) (string, error) {
+	var found []string
+	for _, name := range FileNames {
+		if fileutil.Exists(filepath.Join(dir, name)) {
+			found = append(found, name)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", errors.Wrapf(fs.ErrNotExist, "no devbox config in %s", dir)
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	}
+	return "", usererr.New(
+		"Found more than one devbox config in %s: %s. Remove all but one of them.",
+		dir, strings.Join(found, ", "))
+}
+
+// pathIn returns the path of the config file in dir, or of a new devbox.json
+// if dir doesn't have one.
+func pathIn(dir string) (string, error) {
+	path, err := Find(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return filepath.Join(dir, DefaultName), nil
+	}
+	return path, err
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Find(dir)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	yamlPath := filepath.Join(dir, "devbox.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("packages: []\n"), 0o644))
+	path, err := Find(dir)
+	require.NoError(t, err)
+	require.Equal(t, yamlPath, path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DefaultName), []byte("{}"), 0o644))
+	_, err = Find(dir)
+	require.ErrorContains(t, err, "devbox.json, devbox.yaml")
+}
+
+func TestNameForFormat(t *testing.T) {
+	for format, want := range map[string]string{
+		"json": "devbox.json",
+		"yaml": "devbox.yaml",
+		"toml": "devbox.toml",
+	} {
+		name, err := NameForFormat(format)
+		require.NoError(t, err)
+		require.Equal(t, want, name)
+	}
+	_, err := NameForFormat("xml")
+	require.Error(t, err)
+}
+
+func TestLoadAndSaveFormats(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "devbox.yaml",
+			src: `# Our tools.
+packages:
+  - go@1.20
+shell:
+  init_hook: echo hi
+  scripts:
+    test:
+      - go test ./...
+`,
+		},
+		{
+			name: "devbox.toml",
+			src: `packages = ["go@1.20"]
+
+[shell]
+init_hook = "echo hi"
+
+[shell.scripts]
+test = ["go test ./..."]
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tc.name)
+			require.NoError(t, os.WriteFile(path, []byte(tc.src), 0o644))
+
+			cfg, err := Load(path)
+			require.NoError(t, err)
+			require.Equal(t, []string{"go@1.20"}, cfg.Packages)
+			require.Equal(t, "echo hi", cfg.InitHook().String())
+			require.Equal(t, "go test ./...", cfg.Scripts()["test"].String())
+
+			cfg.Packages = append(cfg.Packages, "jq@latest")
+			require.NoError(t, cfg.SaveTo(dir))
+			// The config is saved in its own format, not as a new
+			// devbox.json.
+			require.NoFileExists(t, filepath.Join(dir, DefaultName))
+
+			saved, err := Load(path)
+			require.NoError(t, err)
+			require.Equal(t, cfg, saved)
+		})
+	}
+}
+
+func TestLoadYAMLUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devbox.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("packages: []\nshell:\n  scrips: {}\n"), 0o644))
+	_, err := Load(path)
+	require.ErrorContains(t, err, `devbox.yaml:3:3: shell: unknown field "scrips", did you mean "scripts"?`)
+}
@@ -6,24 +6,36 @@ package devconfig
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/pkg/errors"
 
 	"go.jetpack.io/devbox/internal/cuecfg"
 	"go.jetpack.io/devbox/internal/initrec"
+	"go.jetpack.io/devbox/internal/trust"
 )
 
+// Init creates a devbox.json in dir if dir doesn't have a config yet.
 func Init(dir string, writer io.Writer) (created bool, err error) {
-	cfgPath := filepath.Join(dir, DefaultName)
+	return InitWithFormat(dir, "json", writer)
+}
 
-	config := DefaultConfig()
+// InitWithFormat creates a config file in format, which is json, yaml or
+// toml, if dir doesn't have a config yet. It suggests the packages that the
+// project may need, but doesn't add them.
+func InitWithFormat(dir, format string, writer io.Writer) (created bool, err error) {
+	created, err = InitWithPackages(dir, format, nil)
+	if !created || err != nil {
+		return created, err
+	}
 
 	// package suggestion
 	pkgsToSuggest, err := initrec.Get(dir)
 	if err != nil {
-		return false, err
+		return true, err
 	}
 	if len(pkgsToSuggest) > 0 {
 		s := fmt.Sprintf("devbox add %s", strings.Join(pkgsToSuggest, " "))
@@ -33,6 +45,35 @@ func Init(dir string, writer io.Writer) (created bool, err error) {
 			color.HiYellowString(s),
 		)
 	}
+	return true, nil
+}
 
-	return cuecfg.InitFile(cfgPath, config)
+// InitWithPackages creates a config file in format with packages, if dir
+// doesn't have a config yet.
+func InitWithPackages(dir, format string, packages []string) (created bool, err error) {
+	name, err := NameForFormat(format)
+	if err != nil {
+		return false, err
+	}
+	if _, err := Find(dir); err == nil {
+		return false, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	cfgPath := filepath.Join(dir, name)
+
+	config := DefaultConfig()
+	if packages != nil {
+		config.Packages = packages
+	}
+	if err := cuecfg.EditFile(cfgPath, config); err != nil {
+		return false, err
+	}
+	// The user created this config, so they don't need to be asked whether
+	// they trust it.
+	hash, err := trust.Hash(cfgPath)
+	if err != nil {
+		return true, err
+	}
+	return true, trust.Add(dir, hash)
 }
@@ -0,0 +1,48 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+func TestInitSuggestsPackages(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n\ngo 1.21.0\n"), 0o644))
+
+	// Init doesn't prompt or look up versions, it only prints a suggestion.
+	out := &bytes.Buffer{}
+	created, err := Init(dir, out)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Contains(t, out.String(), "devbox add go@1.21")
+
+	cfg, err := Load(filepath.Join(dir, DefaultName))
+	require.NoError(t, err)
+	require.Empty(t, cfg.Packages)
+}
+
+func TestInitWithPackages(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+
+	created, err := InitWithPackages(dir, "json", []string{"go@1.21.0"})
+	require.NoError(t, err)
+	require.True(t, created)
+	cfg, err := Load(filepath.Join(dir, DefaultName))
+	require.NoError(t, err)
+	require.Equal(t, []string{"go@1.21.0"}, cfg.Packages)
+
+	created, err = InitWithPackages(dir, "json", nil)
+	require.NoError(t, err)
+	require.False(t, created, "an existing config isn't replaced")
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/devpkg"
+)
+
+// Override customizes how a nixpkgs package is built. Values are nix
+// expressions and are rendered verbatim into the generated flake.nix.
+//
+//	"overrides": {
+//	  "python@3.11": {
+//	    "override": {"x11Support": "true"},
+//	    "override_attrs": {"doCheck": "false", "patches": "(old.patches or []) ++ [ ./fix.patch ]"}
+//	  }
+//	}
+type Override struct {
+	// Override sets the package's function arguments with `pkg.override`.
+	Override map[string]string `json:"override,omitempty"`
+	// OverrideAttrs sets derivation attributes with `pkg.overrideAttrs`. The
+	// previous attributes are available as `old`.
+	OverrideAttrs map[string]string `json:"override_attrs,omitempty"`
+}
+
+// OverrideFor returns the override for a package in the config, matching
+// either the package as written (`python@3.11`) or its name (`python`).
+func (c *Config) OverrideFor(pkg string) *Override {
+	if c == nil || c.Overrides == nil {
+		return nil
+	}
+	if o, ok := c.Overrides[pkg]; ok {
+		return o
+	}
+	return c.Overrides[overridePackageName(pkg)]
+}
+
+func overridePackageName(pkg string) string {
+	name, _ := devpkg.ParseOutputs(pkg)
+	name, _, _ = strings.Cut(name, "@")
+	return name
+}
+
+// Hash returns a hash of the override so that changes to it are recorded in
+// the lockfile.
+func (o *Override) Hash() (string, error) {
+	if o == nil {
+		return "", nil
+	}
+	return cuecfg.Hash(o)
+}
+
+// Apply wraps the nix expression for a package with the override.
+func (o *Override) Apply(expr string) string {
+	if o == nil {
+		return expr
+	}
+	if len(o.Override) > 0 {
+		expr = fmt.Sprintf("(%s.override { %s })", expr, nixAttrSet(o.Override))
+	}
+	if len(o.OverrideAttrs) > 0 {
+		expr = fmt.Sprintf("(%s.overrideAttrs (old: { %s }))", expr, nixAttrSet(o.OverrideAttrs))
+	}
+	return expr
+}
+
+func nixAttrSet(attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b := strings.Builder{}
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "%s = %s;", k, attrs[k])
+	}
+	return b.String()
+}
+
+var nixIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_'-]*$`)
+
+func validateOverrides(cfg *Config) error {
+	for pkg, o := range cfg.Overrides {
+		found := false
+		for _, p := range cfg.Packages {
+			if pkg == p || pkg == overridePackageName(p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return usererr.New("overrides.%s does not match any package in packages", pkg)
+		}
+		if o == nil {
+			continue
+		}
+		for _, attrs := range []map[string]string{o.Override, o.OverrideAttrs} {
+			for k, v := range attrs {
+				if !nixIdentifier.MatchString(k) {
+					return usererr.New("overrides.%s: %q is not a valid nix attribute name", pkg, k)
+				}
+				if strings.TrimSpace(v) == "" {
+					return usererr.New("overrides.%s: %s cannot be empty", pkg, k)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverrideApply(t *testing.T) {
+	o := &Override{
+		Override:      map[string]string{"x11Support": "true"},
+		OverrideAttrs: map[string]string{"doCheck": "false", "NIX_CFLAGS": `"-O3"`},
+	}
+	require.Equal(t,
+		`((pkgs.python3.override { x11Support = true; }).overrideAttrs (old: { NIX_CFLAGS = "-O3"; doCheck = false; }))`,
+		o.Apply("pkgs.python3"),
+	)
+
+	var none *Override
+	require.Equal(t, "pkgs.python3", none.Apply("pkgs.python3"))
+}
+
+func TestOverrideFor(t *testing.T) {
+	pinned := &Override{OverrideAttrs: map[string]string{"doCheck": "false"}}
+	byName := &Override{Override: map[string]string{"withPython": "true"}}
+	cfg := &Config{
+		Packages: []string{"python@3.11", "vim@latest^out", "go@1.20"},
+		Overrides: map[string]*Override{
+			"python@3.11": pinned,
+			"vim":         byName,
+		},
+	}
+	require.Same(t, pinned, cfg.OverrideFor("python@3.11"))
+	require.Same(t, byName, cfg.OverrideFor("vim@latest^out"))
+	require.Nil(t, cfg.OverrideFor("go@1.20"))
+	require.NoError(t, validateOverrides(cfg))
+}
+
+func TestValidateOverrides(t *testing.T) {
+	cfg := &Config{
+		Packages:  []string{"go@1.20"},
+		Overrides: map[string]*Override{"python": {}},
+	}
+	require.Error(t, validateOverrides(cfg))
+
+	cfg.Overrides = map[string]*Override{
+		"go": {OverrideAttrs: map[string]string{"not valid": "true"}},
+	}
+	require.Error(t, validateOverrides(cfg))
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/impl/shellcmd"
+)
+
+// SchemaURL is where the JSON Schema of devbox.json is published. It can be
+// set as the config's "$schema" so that editors validate and complete it.
+const SchemaURL = "https://raw.githubusercontent.com/jetpack-io/devbox/main/.schema/devbox.schema.json"
+
+// jsonSchema is the subset of JSON Schema needed to describe a Config.
+type jsonSchema struct {
+	Schema      string     `json:"$schema,omitempty"`
+	ID          string     `json:"$id,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Type        schemaType `json:"type,omitempty"`
+	Deprecated  bool       `json:"deprecated,omitempty"`
+
+	// Items is the schema of the elements of an array.
+	Items *jsonSchema `json:"items,omitempty"`
+	// Properties are the known fields of an object.
+	Properties map[string]*jsonSchema `json:"properties,omitempty"`
+	// AdditionalProperties is the schema of the fields of an object that
+	// aren't in Properties. Objects made from structs don't allow any, and
+	// objects made from maps allow any key.
+	AdditionalProperties *jsonSchema `json:"-"`
+}
+
+// schemaType is one or more JSON types, which is written as a string or an
+// array of strings.
+type schemaType []cuecfg.NodeKind
+
+func (t schemaType) MarshalJSON() ([]byte, error) {
+	names := make([]string, len(t))
+	for i, k := range t {
+		names[i] = k.String()
+	}
+	if len(names) == 1 {
+		return json.Marshal(names[0])
+	}
+	return json.Marshal(names)
+}
+
+func (t schemaType) allows(kind cuecfg.NodeKind) bool {
+	for _, k := range t {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (t schemaType) String() string {
+	names := make([]string, len(t))
+	for i, k := range t {
+		names[i] = k.String()
+	}
+	return strings.Join(names, " or ")
+}
+
+func (s *jsonSchema) MarshalJSON() ([]byte, error) {
+	type plain jsonSchema
+	out := struct {
+		*plain
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+	if s.Type.allows(cuecfg.ObjectNode) {
+		out.AdditionalProperties = false
+		if s.AdditionalProperties != nil {
+			out.AdditionalProperties = s.AdditionalProperties
+		}
+	}
+	// Don't escape the <, > and & in descriptions.
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fieldDocs describe the fields of a Config in the JSON Schema, keyed by
+// their path in devbox.json.
+var fieldDocs = map[string]string{
+	"$schema":  "The JSON Schema that the config follows.",
+	"packages": "The Nix packages that devbox makes available in its environment, e.g. `go@1.20` or `python3@latest`.",
+	"env":      "Environment variables to set in the devbox environment.",
+	"shell":    "Configures the devbox shell environment.",
+	"shell.init_hook": "Commands that run when the devbox shell starts. " +
+		"Either a single string or an array of commands.",
+	"shell.scripts": "Named scripts that can be run with `devbox run <script>`. " +
+		"Each is either a single string or an array of commands.",
+	"nixpkgs":        "The nixpkgs repository to pull unversioned packages from. Versioned packages don't need this.",
+	"nixpkgs.commit": "The nixpkgs commit hash.",
+	"include":        "Other configs or plugins to include, e.g. `plugin:nginx` or `path:./my-plugin.json`.",
+	"binary_caches":  "Additional Nix binary caches to download packages from.",
+	"binary_caches.substituters": "URLs of binary caches, " +
+		"e.g. `s3://my-cache?region=us-east-1` or `https://cache.example.com`.",
+	"binary_caches.trusted_public_keys": "Keys that verify the signatures of paths from the substituters, " +
+		"e.g. `cache.example.com-1:base64key`.",
+	"overrides": "Customizes how packages are built, keyed by package (e.g. `python@3.11`) or package name.",
+	"overrides.*.override": "Arguments to set with `pkg.override`. " +
+		"Values are Nix expressions.",
+	"overrides.*.override_attrs": "Derivation attributes to set with `pkg.overrideAttrs`. " +
+		"Values are Nix expressions, and the previous attributes are available as `old`.",
+}
+
+// deprecatedFields are the paths of fields that configs shouldn't use anymore.
+var deprecatedFields = map[string]bool{
+	"nixpkgs": true,
+}
+
+var commandsType = reflect.TypeOf(shellcmd.Commands{})
+
+// configSchema is the schema of a Config, generated from its struct fields
+// and their json tags.
+var configSchema = newConfigSchema()
+
+func newConfigSchema() *jsonSchema {
+	s := schemaOf(reflect.TypeOf(Config{}), "")
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.ID = SchemaURL
+	s.Title = "devbox.json"
+	s.Description = "Defines a devbox environment."
+	return s
+}
+
+// schemaOf returns the schema of values of type t, which are at path in the
+// config. Pointers, slices and maps may be null, since encoding/json leaves
+// them unset.
+func schemaOf(t reflect.Type, path string) *jsonSchema {
+	s := &jsonSchema{Description: fieldDocs[path], Deprecated: deprecatedFields[path]}
+	nullable := false
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	switch {
+	case t == commandsType:
+		// Commands unmarshal from a string or an array of strings.
+		s.Type = schemaType{cuecfg.StringNode, cuecfg.ArrayNode}
+		s.Items = &jsonSchema{Type: schemaType{cuecfg.StringNode}}
+	case t.Kind() == reflect.String:
+		s.Type = schemaType{cuecfg.StringNode}
+	case t.Kind() == reflect.Bool:
+		s.Type = schemaType{cuecfg.BoolNode}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64:
+		s.Type = schemaType{cuecfg.NumberNode}
+	case t.Kind() == reflect.Slice:
+		s.Type = schemaType{cuecfg.ArrayNode}
+		s.Items = schemaOf(t.Elem(), path+"[]")
+		nullable = true
+	case t.Kind() == reflect.Map:
+		s.Type = schemaType{cuecfg.ObjectNode}
+		s.AdditionalProperties = schemaOf(t.Elem(), path+".*")
+		nullable = true
+	case t.Kind() == reflect.Struct:
+		s.Type = schemaType{cuecfg.ObjectNode}
+		s.Properties = map[string]*jsonSchema{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			s.Properties[name] = schemaOf(field.Type, fieldPath)
+		}
+	}
+	if nullable {
+		s.Type = append(s.Type, cuecfg.NullNode)
+	}
+	return s
+}
+
+// JSONSchema returns the JSON Schema of devbox.json.
+func JSONSchema() ([]byte, error) {
+	data, err := cuecfg.MarshalJSON(configSchema)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// propertyNames returns the sorted names of the known fields of an object.
+func (s *jsonSchema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
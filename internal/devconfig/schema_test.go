@@ -0,0 +1,34 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// update overwrites the published schema with the generated one.
+var update = flag.Bool("update", false, "update the golden files with the test results")
+
+// publishedSchema is the JSON Schema at SchemaURL.
+var publishedSchema = filepath.Join("..", "..", ".schema", "devbox.schema.json")
+
+func TestJSONSchemaIsPublished(t *testing.T) {
+	got, err := JSONSchema()
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(publishedSchema), 0o755))
+		require.NoError(t, os.WriteFile(publishedSchema, got, 0o644))
+	}
+
+	want, err := os.ReadFile(publishedSchema)
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got),
+		"%s is out of date. Update it with: go test ./internal/devconfig -run TestJSONSchemaIsPublished -update",
+		publishedSchema)
+}
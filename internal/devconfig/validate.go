@@ -0,0 +1,185 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/cuecfg"
+)
+
+// SchemaError is a problem with a value in a config file.
+type SchemaError struct {
+	// Path is where the value is in the config, e.g. shell.scripts.test.
+	Path string
+	Pos  cuecfg.Position
+	Msg  string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// SchemaErrors are all the problems with a config file.
+type SchemaErrors struct {
+	File   string
+	Errors []*SchemaError
+}
+
+// Error lists each problem on its own line, prefixed by file:line:column like
+// a compiler error.
+func (e *SchemaErrors) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = fmt.Sprintf("%s:%s: %s", e.File, err.Pos, err)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkSchema checks that the config in data, which is in the format of ext,
+// matches the schema of a Config. file names the config in errors.
+func checkSchema(data []byte, ext, file string) error {
+	errs, err := schemaErrors(data, ext, file)
+	if err != nil || errs == nil {
+		return err
+	}
+	return usererr.New("%s is invalid:\n%s", file, errs)
+}
+
+// schemaErrors returns the syntax error or schema errors of the config in
+// data, or nil if it's valid.
+func schemaErrors(data []byte, ext, file string) (*SchemaErrors, error) {
+	tree, err := cuecfg.ParseTree(data, ext)
+	if err != nil {
+		var syntaxErr *cuecfg.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			return nil, err
+		}
+		return &SchemaErrors{File: file, Errors: []*SchemaError{
+			{Pos: syntaxErr.Pos, Msg: syntaxErr.Msg},
+		}}, nil
+	}
+	v := &schemaValidator{}
+	v.check(configSchema, tree, "")
+	if len(v.errors) == 0 {
+		return nil, nil
+	}
+	return &SchemaErrors{File: file, Errors: v.errors}, nil
+}
+
+type schemaValidator struct {
+	errors []*SchemaError
+}
+
+func (v *schemaValidator) errorf(pos cuecfg.Position, path, format string, args ...any) {
+	v.errors = append(v.errors, &SchemaError{
+		Path: path,
+		Pos:  pos,
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) check(s *jsonSchema, n *cuecfg.Node, path string) {
+	if !s.Type.allows(n.Kind) {
+		v.errorf(n.Pos, path, "expected %s, got %s", s.Type, n.Kind)
+		return
+	}
+	switch n.Kind {
+	case cuecfg.ArrayNode:
+		for i, item := range n.Items {
+			v.check(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case cuecfg.ObjectNode:
+		for _, f := range n.Fields {
+			fieldPath := f.Key
+			if path != "" {
+				fieldPath = path + "." + f.Key
+			}
+			if prop := s.Properties[f.Key]; prop != nil {
+				v.check(prop, f.Value, fieldPath)
+			} else if s.AdditionalProperties != nil {
+				v.check(s.AdditionalProperties, f.Value, fieldPath)
+			} else {
+				v.unknownField(s, f, path)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) unknownField(s *jsonSchema, f *cuecfg.Field, path string) {
+	msg := fmt.Sprintf("unknown field %q", f.Key)
+	if suggestion := closestName(f.Key, s.propertyNames()); suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", suggestion)
+	}
+	v.errorf(f.KeyPos, path, "%s", msg)
+}
+
+// closestName returns the name that is closest to key, if it's close enough
+// to be a likely typo.
+func closestName(key string, names []string) string {
+	best, bestDist := "", 0
+	for _, name := range names {
+		dist := editDistance(strings.ToLower(key), name)
+		if best == "" || dist < bestDist {
+			best, bestDist = name, dist
+		}
+	}
+	// Allow about one typo for every three characters.
+	if best == "" || bestDist > maxInt(1, len(best)/3) {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the number of insertions, deletions, substitutions and
+// transpositions of adjacent characters that turn a into b (the optimal
+// string alignment distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, n := range rest {
+		if n < m {
+			m = n
+		}
+	}
+	return m
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		ext  string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			ext:  ".json",
+			data: `{
+  "$schema": "` + SchemaURL + `",
+  "packages": ["go@1.20"],
+  "env": {"GOPATH": "$PWD/.go"},
+  "shell": {
+    "init_hook": "echo hi",
+    "scripts": {"test": ["go test ./..."]}
+  },
+  "nixpkgs": null
+}`,
+		},
+		{
+			name: "json",
+			ext:  ".json",
+			data: `{
+  "pakages": ["go@1.20"],
+  "shell": {
+    "init_hook": 3,
+    "scrips": {}
+  },
+  "env": {"CGO_ENABLED": false}
+}`,
+			want: []string{
+				`devbox.json:2:3: unknown field "pakages", did you mean "packages"?`,
+				`devbox.json:4:18: shell.init_hook: expected string or array or null, got number`,
+				`devbox.json:5:5: shell: unknown field "scrips", did you mean "scripts"?`,
+				`devbox.json:7:26: env.CGO_ENABLED: expected string, got boolean`,
+			},
+		},
+		{
+			name: "yaml",
+			ext:  ".yaml",
+			data: `packages:
+  - go@1.20
+  - 3
+shell:
+  init-hook: echo hi
+`,
+			want: []string{
+				`devbox.json:3:5: packages[1]: expected string, got number`,
+				`devbox.json:5:3: shell: unknown field "init-hook", did you mean "init_hook"?`,
+			},
+		},
+		{
+			name: "toml",
+			ext:  ".toml",
+			data: `packages = ["go@1.20"]
+includes = ["plugin:nginx"]
+
+[binary_caches]
+substituters = "https://cache.example.com"
+`,
+			want: []string{
+				`devbox.json:2:1: unknown field "includes", did you mean "include"?`,
+				`devbox.json:5:16: binary_caches.substituters: expected array or null, got string`,
+			},
+		},
+		{
+			name: "no suggestion",
+			ext:  ".json",
+			data: `{"packages": [], "services": {}}`,
+			want: []string{`devbox.json:1:18: unknown field "services"`},
+		},
+		{
+			name: "syntax error",
+			ext:  ".json",
+			data: "{\n  \"packages\": [\n}",
+			want: []string{`devbox.json:3:1: invalid character '}' looking for beginning of value`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs, err := schemaErrors([]byte(tc.data), tc.ext, "devbox.json")
+			require.NoError(t, err)
+			if tc.want == nil {
+				require.Nil(t, errs)
+				return
+			}
+			require.NotNil(t, errs)
+			require.Equal(t, strings.Join(tc.want, "\n"), errs.Error())
+		})
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultName)
+	err := os.WriteFile(path, []byte(`{"packages": [], "env": {}, "evn": {}}`), 0o644)
+	require.NoError(t, err)
+
+	_, err = Load(path)
+	require.ErrorContains(t, err, `unknown field "evn", did you mean "env"?`)
+}
+
+func TestClosestName(t *testing.T) {
+	names := configSchema.propertyNames()
+	require.Equal(t, "packages", closestName("pakages", names))
+	require.Equal(t, "packages", closestName("Packages", names))
+	require.Equal(t, "binary_caches", closestName("binary_cache", names))
+	require.Equal(t, "env", closestName("evn", names))
+	require.Equal(t, "", closestName("services", names))
+	require.Equal(t, "", closestName("x", names))
+}
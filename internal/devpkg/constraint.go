@@ -0,0 +1,204 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devpkg
+
+import (
+	"strconv"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+)
+
+// IsVersionConstraint returns true if version is an update policy like
+// `^18`, `~3.11` or `>=1.20 <1.22` rather than a plain version or version
+// prefix like `3.11` or `latest`.
+func IsVersionConstraint(version string) bool {
+	if version == "" {
+		return false
+	}
+	return strings.ContainsAny(version[:1], "^~<>=") ||
+		strings.ContainsAny(version, " ,")
+}
+
+// VersionConstraint is a set of comparisons that a version must satisfy.
+type VersionConstraint struct {
+	raw         string
+	comparisons []comparison
+}
+
+type comparison struct {
+	op      string
+	version string
+}
+
+// ParseVersionConstraint parses an update policy. Supported forms are:
+//
+//	^1.2.3       >=1.2.3 <2.0.0 (>=0.2.3 <0.3.0 for 0.x versions)
+//	~1.2         >=1.2 <1.3
+//	~1           >=1 <2
+//	>=1.20 <1.22 all comparisons must match. Commas are treated as spaces.
+//
+// Comparison operators are >=, >, <=, < and =.
+func ParseVersionConstraint(s string) (*VersionConstraint, error) {
+	c := &VersionConstraint{raw: s}
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) == 0 {
+		return nil, usererr.New("Empty version constraint")
+	}
+	for _, field := range fields {
+		comps, err := parseComparisons(field)
+		if err != nil {
+			return nil, err
+		}
+		c.comparisons = append(c.comparisons, comps...)
+	}
+	return c, nil
+}
+
+func parseComparisons(field string) ([]comparison, error) {
+	switch {
+	case strings.HasPrefix(field, "^"):
+		lower := strings.TrimPrefix(field, "^")
+		segments, err := parseSegments(lower)
+		if err != nil {
+			return nil, err
+		}
+		// Bump the left-most non-zero segment. ^0.0.3 only allows 0.0.3.x
+		i := 0
+		for i < len(segments)-1 && segments[i] == 0 {
+			i++
+		}
+		return []comparison{
+			{">=", lower},
+			{"<", bumpSegment(segments, i)},
+		}, nil
+	case strings.HasPrefix(field, "~"):
+		lower := strings.TrimPrefix(field, "~")
+		segments, err := parseSegments(lower)
+		if err != nil {
+			return nil, err
+		}
+		// ~1 allows 1.x, ~1.2 and ~1.2.3 allow 1.2.x
+		i := 0
+		if len(segments) > 1 {
+			i = 1
+		}
+		return []comparison{
+			{">=", lower},
+			{"<", bumpSegment(segments, i)},
+		}, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if version, ok := strings.CutPrefix(field, op); ok {
+			if _, err := parseSegments(version); err != nil {
+				return nil, err
+			}
+			return []comparison{{op, version}}, nil
+		}
+	}
+
+	// A bare version inside a constraint is an exact match.
+	if _, err := parseSegments(field); err != nil {
+		return nil, err
+	}
+	return []comparison{{"=", field}}, nil
+}
+
+// Check returns true if version satisfies every comparison in the constraint.
+func (c *VersionConstraint) Check(version string) bool {
+	for _, comp := range c.comparisons {
+		cmp := CompareVersions(version, comp.version)
+		var ok bool
+		switch comp.op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *VersionConstraint) String() string {
+	return c.raw
+}
+
+// CompareVersions compares two dot separated versions segment by segment and
+// returns -1, 0 or 1. Missing segments are treated as 0, so 1.2 == 1.2.0.
+// A segment with a non-numeric suffix (1.2.0rc1) sorts before the same
+// segment without one (1.2.0).
+func CompareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		an, asuffix := splitSegment(as, i)
+		bn, bsuffix := splitSegment(bs, i)
+		if an != bn {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+		if asuffix != bsuffix {
+			switch {
+			case asuffix == "":
+				return 1
+			case bsuffix == "":
+				return -1
+			case asuffix < bsuffix:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+func splitSegment(segments []string, i int) (int, string) {
+	if i >= len(segments) {
+		return 0, ""
+	}
+	s := segments[i]
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n, s[end:]
+}
+
+func parseSegments(version string) ([]int, error) {
+	if version == "" {
+		return nil, usererr.New("Missing version in constraint")
+	}
+	var segments []int
+	for _, s := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, usererr.New("Invalid version %q in constraint", version)
+		}
+		segments = append(segments, n)
+	}
+	return segments, nil
+}
+
+func bumpSegment(segments []int, i int) string {
+	parts := make([]string, i+1)
+	for j := 0; j < i; j++ {
+		parts[j] = strconv.Itoa(segments[j])
+	}
+	parts[i] = strconv.Itoa(segments[i] + 1)
+	return strings.Join(parts, ".")
+}
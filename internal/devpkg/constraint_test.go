@@ -0,0 +1,88 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devpkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsVersionConstraint(t *testing.T) {
+	cases := map[string]bool{
+		"":             false,
+		"latest":       false,
+		"3.11":         false,
+		"^18":          true,
+		"~3.11":        true,
+		">=1.20 <1.22": true,
+		"=1.2.3":       true,
+		">=1.20,<1.22": true,
+	}
+	for version, expected := range cases {
+		t.Run(version, func(t *testing.T) {
+			require.Equal(t, expected, IsVersionConstraint(version))
+		})
+	}
+}
+
+func TestVersionConstraintCheck(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		matches    bool
+	}{
+		{"^18", "18.0.0", true},
+		{"^18", "18.17.1", true},
+		{"^18", "19.0.0", false},
+		{"^18", "17.9.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"~3.11", "3.11.4", true},
+		{"~3.11", "3.12.0", false},
+		{"~3", "3.12.0", true},
+		{"~3", "4.0", false},
+		{">=1.20 <1.22", "1.21.3", true},
+		{">=1.20 <1.22", "1.22.0", false},
+		{">=1.20 <1.22", "1.19.9", false},
+		{">=1.20, <1.22", "1.20", true},
+		{"=1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.constraint+"/"+tc.version, func(t *testing.T) {
+			req := require.New(t)
+			c, err := ParseVersionConstraint(tc.constraint)
+			req.NoError(err)
+			req.Equal(tc.matches, c.Check(tc.version))
+		})
+	}
+}
+
+func TestParseVersionConstraintInvalid(t *testing.T) {
+	for _, s := range []string{"", "^", "~x", ">=1.a"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseVersionConstraint(s)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2", "1.2.0", 0},
+		{"1.10", "1.9", 1},
+		{"1.2.0rc1", "1.2.0", -1},
+		{"2", "10", -1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.a+"_"+tc.b, func(t *testing.T) {
+			require.Equal(t, tc.expected, CompareVersions(tc.a, tc.b))
+		})
+	}
+}
@@ -4,12 +4,30 @@
 package devpkg
 
 import (
+	"regexp"
 	"strings"
 )
 
 // ParseVersionedPackage checks if the given package is a versioned package (`python@3.10`)
 // and returns its name and version
 func ParseVersionedPackage(pkg string) (string, string, bool) {
+	pkg, _ = ParseOutputs(pkg)
 	name, version, found := strings.Cut(pkg, "@")
 	return name, version, found && name != "" && version != ""
 }
+
+// ParseOutputs splits the output selector from a package (`openssl@3^dev,out`)
+// and returns the package without it and the selected outputs. If no outputs
+// are selected it returns nil and nix installs the package's default outputs.
+//
+// Output names always start with a letter, which distinguishes the selector
+// from caret version constraints like `nodejs@^18`.
+func ParseOutputs(pkg string) (string, []string) {
+	i := strings.LastIndex(pkg, "^")
+	if i == -1 || !outputsRegex.MatchString(pkg[i+1:]) {
+		return pkg, nil
+	}
+	return pkg[:i], strings.Split(pkg[i+1:], ",")
+}
+
+var outputsRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*(,[a-zA-Z][a-zA-Z0-9_-]*)*$`)
@@ -0,0 +1,39 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package devpkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputs(t *testing.T) {
+	cases := []struct {
+		pkg     string
+		base    string
+		outputs []string
+	}{
+		{"openssl@3", "openssl@3", nil},
+		{"openssl@3^dev,out", "openssl@3", []string{"dev", "out"}},
+		{"openssl^man", "openssl", []string{"man"}},
+		{"nodejs@^18", "nodejs@^18", nil},
+		{"nodejs@^18^lib", "nodejs@^18", []string{"lib"}},
+		{"github:nixos/nixpkgs#zlib^dev", "github:nixos/nixpkgs#zlib", []string{"dev"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.pkg, func(t *testing.T) {
+			base, outputs := ParseOutputs(tc.pkg)
+			require.Equal(t, tc.base, base)
+			require.Equal(t, tc.outputs, outputs)
+		})
+	}
+}
+
+func TestParseVersionedPackageIgnoresOutputs(t *testing.T) {
+	name, version, ok := ParseVersionedPackage("openssl@3^dev,out")
+	require.True(t, ok)
+	require.Equal(t, "openssl", name)
+	require.Equal(t, "3", version)
+}
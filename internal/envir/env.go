@@ -9,14 +9,19 @@ const (
 	DevboxDebug         = "DEVBOX_DEBUG"
 	DevboxFeaturePrefix = "DEVBOX_FEATURE_"
 	DevboxGateway       = "DEVBOX_GATEWAY"
+	DevboxGlobalData    = "DEVBOX_GLOBAL_DATA"
+	DevboxGlobalProfile = "DEVBOX_GLOBAL_PROFILE"
 	// DevboxLatestVersion is the latest version available of the devbox CLI binary.
 	// NOTE: it should NOT start with v (like 0.4.8)
 	DevboxLatestVersion  = "DEVBOX_LATEST_VERSION"
+	DevboxProgress       = "DEVBOX_PROGRESS"
 	DevboxRegion         = "DEVBOX_REGION"
 	DevboxSearchHost     = "DEVBOX_SEARCH_HOST"
 	DevboxShellEnabled   = "DEVBOX_SHELL_ENABLED"
 	DevboxShellStartTime = "DEVBOX_SHELL_START_TIME"
-	DevboxVM             = "DEVBOX_VM"
+	// DevboxTrustAll trusts every project without asking, e.g. in CI.
+	DevboxTrustAll = "DEVBOX_TRUST_ALL"
+	DevboxVM       = "DEVBOX_VM"
 
 	LauncherVersion = "LAUNCHER_VERSION"
 	LauncherPath    = "LAUNCHER_PATH"
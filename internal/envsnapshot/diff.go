@@ -0,0 +1,231 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package envsnapshot
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Diff is the difference between two snapshots.
+type Diff struct {
+	Packages []*PackageChange
+	// Vars are the changed environment variables other than PATH, sorted by
+	// name.
+	Vars []*VarChange
+	// Path is every component of the PATH of either snapshot, in order. It's
+	// nil if PATH didn't change.
+	Path []*PathEntry
+}
+
+// PackageChange is a package that was added, removed or resolved to a
+// different version. Old is nil if it was added, and New is nil if it was
+// removed.
+type PackageChange struct {
+	Name string
+	Old  *Package
+	New  *Package
+}
+
+// VarChange is an environment variable that was added, removed or changed.
+type VarChange struct {
+	Name     string
+	Old, New string
+	// Added and Removed are whether the variable was only set in the new or
+	// old snapshot.
+	Added, Removed bool
+}
+
+// PathEntry is a component of PATH. Added and Removed are whether it's only
+// in the new or old PATH, and Index is its position in the PATH it's in.
+type PathEntry struct {
+	Dir            string
+	Index          int
+	Added, Removed bool
+}
+
+// IsEmpty reports whether the snapshots were the same.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Packages) == 0 && len(d.Vars) == 0 && d.Path == nil
+}
+
+// Compare returns the changes from old to new.
+func Compare(old, new *Snapshot) *Diff {
+	return &Diff{
+		Packages: comparePackages(old.Packages, new.Packages),
+		Vars:     compareVars(old.Env, new.Env),
+		Path:     comparePath(old.Env["PATH"], new.Env["PATH"]),
+	}
+}
+
+func comparePackages(old, new []*Package) []*PackageChange {
+	byName := func(pkgs []*Package) map[string]*Package {
+		m := map[string]*Package{}
+		for _, pkg := range pkgs {
+			m[pkg.Name] = pkg
+		}
+		return m
+	}
+	oldByName, newByName := byName(old), byName(new)
+
+	changes := []*PackageChange{}
+	for _, pkg := range old {
+		newPkg := newByName[pkg.Name]
+		if newPkg == nil {
+			changes = append(changes, &PackageChange{Name: pkg.Name, Old: pkg})
+		} else if pkg.Version != newPkg.Version ||
+			strings.Join(pkg.StorePaths, " ") != strings.Join(newPkg.StorePaths, " ") {
+			changes = append(changes, &PackageChange{Name: pkg.Name, Old: pkg, New: newPkg})
+		}
+	}
+	for _, pkg := range new {
+		if oldByName[pkg.Name] == nil {
+			changes = append(changes, &PackageChange{Name: pkg.Name, New: pkg})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func compareVars(old, new map[string]string) []*VarChange {
+	changes := []*VarChange{}
+	for name, oldValue := range old {
+		if name == "PATH" {
+			continue
+		}
+		newValue, ok := new[name]
+		switch {
+		case !ok:
+			changes = append(changes, &VarChange{Name: name, Old: oldValue, Removed: true})
+		case newValue != oldValue:
+			changes = append(changes, &VarChange{Name: name, Old: oldValue, New: newValue})
+		}
+	}
+	for name, newValue := range new {
+		if _, ok := old[name]; !ok && name != "PATH" {
+			changes = append(changes, &VarChange{Name: name, New: newValue, Added: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// comparePath lines up the components of two PATHs by their longest common
+// subsequence, so that a directory that moved shows as removed from its old
+// place and added at its new one.
+func comparePath(old, new string) []*PathEntry {
+	if old == new {
+		return nil
+	}
+	oldDirs, newDirs := filepath.SplitList(old), filepath.SplitList(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldDirs[i:] and newDirs[j:].
+	lcs := make([][]int, len(oldDirs)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newDirs)+1)
+	}
+	for i := len(oldDirs) - 1; i >= 0; i-- {
+		for j := len(newDirs) - 1; j >= 0; j-- {
+			if oldDirs[i] == newDirs[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	entries := []*PathEntry{}
+	i, j := 0, 0
+	for i < len(oldDirs) || j < len(newDirs) {
+		switch {
+		case i < len(oldDirs) && j < len(newDirs) && oldDirs[i] == newDirs[j]:
+			entries = append(entries, &PathEntry{Dir: newDirs[j], Index: j})
+			i++
+			j++
+		case j >= len(newDirs) || (i < len(oldDirs) && lcs[i+1][j] >= lcs[i][j+1]):
+			entries = append(entries, &PathEntry{Dir: oldDirs[i], Index: i, Removed: true})
+			i++
+		default:
+			entries = append(entries, &PathEntry{Dir: newDirs[j], Index: j, Added: true})
+			j++
+		}
+	}
+	return entries
+}
+
+// Write prints the diff to w. Added lines start with +, removed lines with -
+// and changed values with ~. PATH is printed in full, one directory per line,
+// so that changes in its order are visible.
+func (d *Diff) Write(w io.Writer) error {
+	if d.IsEmpty() {
+		_, err := fmt.Fprintln(w, "No differences")
+		return err
+	}
+	b := &strings.Builder{}
+	if len(d.Packages) > 0 {
+		b.WriteString("Packages:\n")
+		for _, c := range d.Packages {
+			switch {
+			case c.Old == nil:
+				fmt.Fprintf(b, "+ %s%s\n", c.Name, versionSuffix(c.New))
+			case c.New == nil:
+				fmt.Fprintf(b, "- %s%s\n", c.Name, versionSuffix(c.Old))
+			default:
+				fmt.Fprintf(b, "~ %s: %s -> %s\n", c.Name, c.Old.Version, c.New.Version)
+				for _, path := range c.Old.StorePaths {
+					fmt.Fprintf(b, "    - %s\n", path)
+				}
+				for _, path := range c.New.StorePaths {
+					fmt.Fprintf(b, "    + %s\n", path)
+				}
+			}
+		}
+	}
+	if len(d.Vars) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Variables:\n")
+		for _, c := range d.Vars {
+			switch {
+			case c.Added:
+				fmt.Fprintf(b, "+ %s=%s\n", c.Name, c.New)
+			case c.Removed:
+				fmt.Fprintf(b, "- %s=%s\n", c.Name, c.Old)
+			default:
+				fmt.Fprintf(b, "~ %s\n    - %s\n    + %s\n", c.Name, c.Old, c.New)
+			}
+		}
+	}
+	if d.Path != nil {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("PATH:\n")
+		for _, e := range d.Path {
+			marker := " "
+			if e.Added {
+				marker = "+"
+			} else if e.Removed {
+				marker = "-"
+			}
+			fmt.Fprintf(b, "%s %2d %s\n", marker, e.Index, e.Dir)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func versionSuffix(pkg *Package) string {
+	if pkg.Version == "" {
+		return ""
+	}
+	return " (" + pkg.Version + ")"
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package envsnapshot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	old := &Snapshot{
+		Packages: []*Package{
+			{Name: "go@1.20", Version: "1.20.5", StorePaths: []string{"/nix/store/a-go-1.20.5"}},
+			{Name: "hello", Version: "2.12.1"},
+		},
+		Env: map[string]string{
+			"PATH":    "/wrappers:/nix/store/a-go-1.20.5/bin:/usr/bin:/bin",
+			"GOROOT":  "/nix/store/a-go-1.20.5/share/go",
+			"REMOVED": "1",
+			"SAME":    "x",
+		},
+	}
+	new := &Snapshot{
+		Packages: []*Package{
+			{Name: "go@1.20", Version: "1.20.6", StorePaths: []string{"/nix/store/b-go-1.20.6"}},
+			{Name: "jq", Version: "1.6"},
+		},
+		Env: map[string]string{
+			"PATH":   "/wrappers:/nix/store/b-go-1.20.6/bin:/bin:/usr/bin",
+			"GOROOT": "/nix/store/b-go-1.20.6/share/go",
+			"ADDED":  "2",
+			"SAME":   "x",
+		},
+	}
+	d := Compare(old, new)
+
+	require.Equal(t, []*PackageChange{
+		{Name: "go@1.20", Old: old.Packages[0], New: new.Packages[0]},
+		{Name: "hello", Old: old.Packages[1]},
+		{Name: "jq", New: new.Packages[1]},
+	}, d.Packages)
+	require.Equal(t, []*VarChange{
+		{Name: "ADDED", New: "2", Added: true},
+		{Name: "GOROOT", Old: "/nix/store/a-go-1.20.5/share/go", New: "/nix/store/b-go-1.20.6/share/go"},
+		{Name: "REMOVED", Old: "1", Removed: true},
+	}, d.Vars)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, d.Write(buf))
+	require.Equal(t, `Packages:
+~ go@1.20: 1.20.5 -> 1.20.6
+    - /nix/store/a-go-1.20.5
+    + /nix/store/b-go-1.20.6
+- hello (2.12.1)
++ jq (1.6)
+
+Variables:
++ ADDED=2
+~ GOROOT
+    - /nix/store/a-go-1.20.5/share/go
+    + /nix/store/b-go-1.20.6/share/go
+- REMOVED=1
+
+PATH:
+   0 /wrappers
+-  1 /nix/store/a-go-1.20.5/bin
+-  2 /usr/bin
++  1 /nix/store/b-go-1.20.6/bin
+   2 /bin
++  3 /usr/bin
+`, buf.String())
+}
+
+func TestCompareSame(t *testing.T) {
+	s := &Snapshot{Env: map[string]string{"PATH": "/a:/b", "FOO": "bar"}}
+	d := Compare(s, s)
+	require.True(t, d.IsEmpty())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, d.Write(buf))
+	require.Equal(t, "No differences\n", buf.String())
+}
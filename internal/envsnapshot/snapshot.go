@@ -0,0 +1,103 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+// Package envsnapshot records the environment that devbox computes for a
+// project, and compares two of them.
+package envsnapshot
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+)
+
+// Version is the version of the snapshot format.
+const Version = "1"
+
+// Snapshot is the computed environment of a devbox project at a point in
+// time.
+type Snapshot struct {
+	Version    string `json:"version"`
+	ProjectDir string `json:"project_dir"`
+	System     string `json:"system,omitempty"`
+	// Packages are the project's packages with the versions and store paths
+	// they resolved to.
+	Packages []*Package `json:"packages"`
+	// Env is every environment variable of the devbox environment, including
+	// the ones set in the config.
+	Env map[string]string `json:"env"`
+}
+
+// Package is a package of the project as it was resolved.
+type Package struct {
+	// Name is the package as written in the config, e.g. go@1.20.
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Resolved string `json:"resolved,omitempty"`
+	// StorePaths are the Nix store paths of the package's outputs.
+	StorePaths []string `json:"store_paths,omitempty"`
+}
+
+// Write writes s to w as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(s))
+}
+
+// WriteFile writes s to the file at path.
+func (s *Snapshot) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := s.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return errors.WithStack(f.Close())
+}
+
+// Read reads the snapshot in the file at path.
+func Read(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, usererr.New("%s is not an environment snapshot: %v", path, err)
+	}
+	if s.Env == nil {
+		return nil, usererr.New("%s is not an environment snapshot: it has no env", path)
+	}
+	return s, nil
+}
+
+// StorePathsFor returns the paths in storePaths that belong to the package
+// with the given store name, e.g. /nix/store/<hash>-go-1.20.5 for "go".
+func StorePathsFor(storeName string, storePaths []string) []string {
+	matches := []string{}
+	for _, path := range storePaths {
+		base := filepath.Base(path)
+		// Store paths are named <32 character hash>-<name>-<version>.
+		_, name, ok := strings.Cut(base, "-")
+		if !ok {
+			continue
+		}
+		version, ok := strings.CutPrefix(name, storeName+"-")
+		// The version starts with a digit, which tells go-1.20.5 from
+		// go-tools-0.11.0.
+		if name == storeName || ok && version != "" && unicode.IsDigit(rune(version[0])) {
+			matches = append(matches, path)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package envsnapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	s := &Snapshot{
+		Version:    Version,
+		ProjectDir: "/project",
+		System:     "x86_64-linux",
+		Packages: []*Package{{
+			Name:       "go@1.20",
+			Version:    "1.20.5",
+			StorePaths: []string{"/nix/store/aaaa-go-1.20.5"},
+		}},
+		Env: map[string]string{"PATH": "/a:/b", "FOO": "bar"},
+	}
+	path := filepath.Join(t.TempDir(), "env.json")
+	require.NoError(t, s.WriteFile(path))
+
+	read, err := Read(path)
+	require.NoError(t, err)
+	require.Equal(t, s, read)
+}
+
+func TestReadNotASnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devbox.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"packages": []}`), 0644))
+
+	_, err := Read(path)
+	require.ErrorContains(t, err, "is not an environment snapshot")
+}
+
+func TestStorePathsFor(t *testing.T) {
+	paths := []string{
+		"/nix/store/aaaa-go-1.20.5",
+		"/nix/store/bbbb-go-tools-0.11.0",
+		"/nix/store/cccc-openssl-3.0.9-dev",
+		"/nix/store/dddd-openssl-3.0.9",
+		"/nix/store/eeee-hello",
+	}
+	require.Equal(t, []string{"/nix/store/aaaa-go-1.20.5"}, StorePathsFor("go", paths))
+	require.Equal(t, []string{
+		"/nix/store/cccc-openssl-3.0.9-dev",
+		"/nix/store/dddd-openssl-3.0.9",
+	}, StorePathsFor("openssl", paths))
+	require.Equal(t, []string{"/nix/store/eeee-hello"}, StorePathsFor("hello", paths))
+	require.Empty(t, StorePathsFor("python3", paths))
+}
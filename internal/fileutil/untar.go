@@ -30,9 +30,12 @@ func Untar(archive io.Reader, destPath string) error {
 
 	// The handler will be called for each entry in the archive.
 	handler := func(ctx context.Context, fromFile archiver.File) error {
-		// TODO: consider whether the path provided in the archive is a valid
-		// relative path to begin with.
-		rel := filepath.Clean(fromFile.NameInArchive)
+		// Archives can come from anywhere, so reject entries such as
+		// ../../.bashrc or /etc/passwd that would be written outside destPath.
+		rel := filepath.Clean(filepath.FromSlash(fromFile.NameInArchive))
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("archive contained entry %s outside of the destination directory", fromFile.NameInArchive)
+		}
 		abs := filepath.Join(destPath, rel)
 
 		mode := fromFile.Mode()
@@ -0,0 +1,56 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package fileutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tarball returns a .tar.gz archive with a regular file for each name.
+func tarball(t *testing.T, names ...string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(name)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf
+}
+
+func TestUntar(t *testing.T) {
+	dest := t.TempDir()
+	require.NoError(t, Untar(tarball(t, "devbox.json"), dest))
+	data, err := os.ReadFile(filepath.Join(dest, "devbox.json"))
+	require.NoError(t, err)
+	require.Equal(t, "devbox.json", string(data))
+}
+
+func TestUntarRejectsEntriesOutsideDest(t *testing.T) {
+	for _, name := range []string{"../escaped", "a/../../escaped", "/tmp/escaped"} {
+		t.Run(name, func(t *testing.T) {
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "dest")
+			require.NoError(t, os.Mkdir(dest, 0o755))
+
+			require.Error(t, Untar(tarball(t, name), dest))
+			require.NoFileExists(t, filepath.Join(parent, "escaped"))
+		})
+	}
+}
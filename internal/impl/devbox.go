@@ -36,6 +36,7 @@ import (
 	"go.jetpack.io/devbox/internal/lock"
 	"go.jetpack.io/devbox/internal/nix"
 	"go.jetpack.io/devbox/internal/plugin"
+	"go.jetpack.io/devbox/internal/projects"
 	"go.jetpack.io/devbox/internal/redact"
 	"go.jetpack.io/devbox/internal/searcher"
 	"go.jetpack.io/devbox/internal/services"
@@ -60,6 +61,10 @@ type Devbox struct {
 	pluginManager *plugin.Manager
 	pure          bool
 
+	// inTransaction is set while a transaction is in progress so that nested
+	// operations (e.g. Update calling Add) don't snapshot again.
+	inTransaction bool
+
 	// Possible TODO: hardcode this to stderr. Allowing the caller to specify the
 	// writer is error prone. Since it is almost always stderr, we should default
 	// it and if the user wants stdout then they can return a string and print it.
@@ -74,7 +79,10 @@ func Open(opts *devopt.Opts) (*Devbox, error) {
 	if err != nil {
 		return nil, err
 	}
-	cfgPath := filepath.Join(projectDir, devconfig.DefaultName)
+	cfgPath, err := devconfig.Find(projectDir)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg, err := devconfig.Load(cfgPath)
 	if err != nil {
@@ -109,6 +117,18 @@ func Open(opts *devopt.Opts) (*Devbox, error) {
 	)
 	box.lockfile = lock
 
+	// The registry is only used by commands that work across projects, so
+	// failing to update it shouldn't stop this one.
+	record := projects.Record
+	if opts.ShellHook {
+		record = projects.TryRecord
+	}
+	if hash, err := cfg.Hash(); err == nil {
+		if err := record(projectDir, hash); err != nil {
+			debug.Log("failed to record project %s: %v", projectDir, err)
+		}
+	}
+
 	if !opts.IgnoreWarnings &&
 		!legacyPackagesWarningHasBeenShown &&
 		box.HasDeprecatedPackages() {
@@ -136,9 +156,12 @@ func (d *Devbox) Config() *devconfig.Config {
 	return d.cfg
 }
 
+// ConfigHash returns a hash of the config and packages that determine the
+// project's Nix environment. The local lockfile uses it to tell whether
+// packages need to be installed.
 func (d *Devbox) ConfigHash() (string, error) {
 	hashes := lo.Map(d.PackagesAsInputs(), func(i *nix.Package, _ int) string { return i.Hash() })
-	h, err := d.cfg.Hash()
+	h, err := d.cfg.NixHash()
 	if err != nil {
 		return "", err
 	}
@@ -160,6 +183,9 @@ func (d *Devbox) Shell(ctx context.Context) error {
 	ctx, task := trace.NewTask(ctx, "devboxShell")
 	defer task.End()
 
+	if err := d.ensureTrusted(ctx); err != nil {
+		return err
+	}
 	if err := d.ensurePackagesAreInstalled(ctx, ensure); err != nil {
 		return err
 	}
@@ -211,6 +237,11 @@ func (d *Devbox) RunScript(ctx context.Context, cmdName string, cmdArgs []string
 	ctx, task := trace.NewTask(ctx, "devboxRun")
 	defer task.End()
 
+	// Scripts and commands run the init_hook, so they need the same trust
+	// as a shell.
+	if err := d.ensureTrusted(ctx); err != nil {
+		return err
+	}
 	if err := d.ensurePackagesAreInstalled(ctx, ensure); err != nil {
 		return err
 	}
@@ -372,30 +403,34 @@ func (d *Devbox) GenerateDevcontainer(ctx context.Context, force bool) error {
 	devContainerJSONPath := filepath.Join(devContainerPath, "devcontainer.json")
 	dockerfilePath := filepath.Join(devContainerPath, "Dockerfile")
 
-	// check if devcontainer.json or Dockerfile exist
-	filesExist := fileutil.Exists(devContainerJSONPath) || fileutil.Exists(dockerfilePath)
-	if !force && filesExist {
-		return usererr.New(
-			"Files devcontainer.json or Dockerfile are already present in .devcontainer/. " +
-				"Remove the files or use --force to overwrite them.",
-		)
-	}
-
 	// create directory
 	err := os.MkdirAll(devContainerPath, os.ModePerm)
 	if err != nil {
 		return redact.Errorf("error creating dev container directory in <project>/%s: %w",
 			redact.Safe(filepath.Base(devContainerPath)), err)
 	}
-	// generate dockerfile
-	err = generate.CreateDockerfile(ctx,
-		devContainerPath, d.getLocalFlakesDirs(), true /* isDevcontainer */)
+	// generate dockerfile, keeping an existing one unless forced since users
+	// often customize it.
+	if force || !fileutil.Exists(dockerfilePath) {
+		err = generate.CreateDockerfile(ctx, devContainerPath,
+			filepath.Base(d.configPath()), d.getLocalFlakesDirs(), true /* isDevcontainer */)
+		if err != nil {
+			return redact.Errorf("error generating dev container Dockerfile in <project>/%s: %w",
+				redact.Safe(filepath.Base(devContainerPath)), err)
+		}
+	}
+
+	ports, err := d.pluginManager.ServicePorts(d.PackagesAsInputs(), d.cfg.Include)
 	if err != nil {
-		return redact.Errorf("error generating dev container Dockerfile in <project>/%s: %w",
-			redact.Safe(filepath.Base(devContainerPath)), err)
+		return err
+	}
+	opts := &generate.DevcontainerOpts{Packages: d.Packages(), Ports: ports}
+	// generate devcontainer.json, or update the existing one in place
+	if !force && fileutil.Exists(devContainerJSONPath) {
+		err = generate.UpdateDevcontainer(ctx, devContainerPath, opts)
+	} else {
+		err = generate.CreateDevcontainer(ctx, devContainerPath, opts)
 	}
-	// generate devcontainer.json
-	err = generate.CreateDevcontainer(ctx, devContainerPath, d.Packages())
 	if err != nil {
 		return redact.Errorf("error generating devcontainer.json in <project>/%s: %w",
 			redact.Safe(filepath.Base(devContainerPath)), err)
@@ -420,12 +455,18 @@ func (d *Devbox) GenerateDockerfile(ctx context.Context, force bool) error {
 
 	// generate dockerfile
 	return errors.WithStack(
-		generate.CreateDockerfile(ctx,
-			d.projectDir, d.getLocalFlakesDirs(), false /* isDevcontainer */))
+		generate.CreateDockerfile(ctx, d.projectDir,
+			filepath.Base(d.configPath()), d.getLocalFlakesDirs(), false /* isDevcontainer */))
 }
 
-func PrintEnvrcContent(w io.Writer) error {
-	return generate.EnvrcContent(w)
+// PrintEnvrcContent prints the script that .envrc evaluates for the project
+// in dir.
+func PrintEnvrcContent(w io.Writer, dir string) error {
+	configPath, err := devconfig.Find(dir)
+	if err != nil {
+		configPath = filepath.Join(dir, devconfig.DefaultName)
+	}
+	return generate.EnvrcContent(w, configPath)
 }
 
 // GenerateEnvrcFile generates a .envrc file that makes direnv integration convenient
@@ -470,9 +511,103 @@ func (d *Devbox) GenerateEnvrcFile(ctx context.Context, force bool) error {
 	return nil
 }
 
-// saveCfg writes the config file to the devbox directory.
+// GenerateGithubActions generates a GitHub Actions workflow that installs
+// devbox, caches the nix store and runs script.
+func (d *Devbox) GenerateGithubActions(ctx context.Context, script string, force bool) error {
+	ctx, task := trace.NewTask(ctx, "devboxGenerateGithubActions")
+	defer task.End()
+
+	return d.generateCIFile(ctx, ".github/workflows/devbox.yml", script, force,
+		generate.CreateGithubWorkflow)
+}
+
+// GenerateGitlabCI generates a .gitlab-ci.yml that installs devbox, caches
+// the nix store and runs script.
+func (d *Devbox) GenerateGitlabCI(ctx context.Context, script string, force bool) error {
+	ctx, task := trace.NewTask(ctx, "devboxGenerateGitlabCI")
+	defer task.End()
+
+	return d.generateCIFile(ctx, ".gitlab-ci.yml", script, force, generate.CreateGitlabCI)
+}
+
+func (d *Devbox) generateCIFile(
+	ctx context.Context,
+	relPath, script string,
+	force bool,
+	create func(ctx context.Context, path, script string) error,
+) error {
+	path := filepath.Join(d.projectDir, relPath)
+	if !force && fileutil.Exists(path) {
+		return usererr.New(
+			"%s is already present. Remove it or use --force to overwrite it.", relPath,
+		)
+	}
+	script, err := d.ciScript(script)
+	if err != nil {
+		return err
+	}
+	if err := create(ctx, path, script); err != nil {
+		return redact.Errorf("error generating <project>/%s: %w", redact.Safe(relPath), err)
+	}
+	ux.Fsuccess(d.writer, "generated %s to run `devbox run %s`\n", relPath, script)
+	return nil
+}
+
+// ciScript returns the script CI should run. It defaults to the test script,
+// or the first script if there isn't one.
+func (d *Devbox) ciScript(script string) (string, error) {
+	scripts := d.ListScripts()
+	if script != "" {
+		if !slices.Contains(scripts, script) {
+			return "", usererr.New("Script %q is not defined in devbox.json", script)
+		}
+		return script, nil
+	}
+	if len(scripts) == 0 {
+		return "", usererr.New(
+			"devbox.json has no scripts. Add a script for CI to run, such as \"test\".",
+		)
+	}
+	if slices.Contains(scripts, "test") {
+		return "test", nil
+	}
+	slices.Sort(scripts)
+	return scripts[0], nil
+}
+
+// GenerateNix generates a flake.nix and shell.nix that create the devbox
+// environment without devbox.
+func (d *Devbox) GenerateNix(ctx context.Context, force bool) error {
+	ctx, task := trace.NewTask(ctx, "devboxGenerateNix")
+	defer task.End()
+
+	for _, name := range []string{"flake.nix", "shell.nix"} {
+		if !force && fileutil.Exists(filepath.Join(d.projectDir, name)) {
+			return usererr.New(
+				"%s is already present in the current directory. "+
+					"Remove it or use --force to overwrite it.", name,
+			)
+		}
+	}
+	if err := shellgen.GenerateStandalone(ctx, d, d.projectDir); err != nil {
+		return err
+	}
+	ux.Fsuccess(d.writer, "generated flake.nix and shell.nix\n")
+	return nil
+}
+
+// saveCfg writes the config file to the devbox directory. Projects that were
+// trusted stay trusted, since the change is made by devbox on the user's
+// behalf.
 func (d *Devbox) saveCfg() error {
-	return d.cfg.SaveTo(d.ProjectDir())
+	status, err := d.TrustStatus()
+	if err != nil {
+		return err
+	}
+	if err := d.cfg.SaveTo(d.ProjectDir()); err != nil {
+		return err
+	}
+	return d.retrust(status)
 }
 
 func (d *Devbox) Services() (services.Services, error) {
@@ -549,7 +684,11 @@ func (d *Devbox) StopServices(ctx context.Context, allProjects bool, serviceName
 	}
 
 	if allProjects {
-		return services.StopAllProcessManagers(ctx, d.writer)
+		if err := services.StopAllProcessManagers(ctx, d.writer); err != nil {
+			return err
+		}
+		recordAllServicesStopped()
+		return nil
 	}
 
 	if !services.ProcessManagerIsRunning(d.projectDir) {
@@ -557,7 +696,11 @@ func (d *Devbox) StopServices(ctx context.Context, allProjects bool, serviceName
 	}
 
 	if len(serviceNames) == 0 {
-		return services.StopProcessManager(ctx, d.projectDir, d.writer)
+		if err := services.StopProcessManager(ctx, d.projectDir, d.writer); err != nil {
+			return err
+		}
+		recordServicesRunning(d.projectDir, false)
+		return nil
 	}
 
 	svcSet, err := d.Services()
@@ -696,7 +839,7 @@ func (d *Devbox) StartProcessManager(
 
 	// Start the process manager
 
-	return services.StartProcessManager(
+	err = services.StartProcessManager(
 		ctx,
 		d.writer,
 		requestedServices,
@@ -705,6 +848,11 @@ func (d *Devbox) StartProcessManager(
 		processComposePath, processComposeFileOrDir,
 		background,
 	)
+	// In the foreground, process-compose has exited by the time it returns.
+	if err == nil && background {
+		recordServicesRunning(d.projectDir, true)
+	}
+	return err
 }
 
 // computeNixEnv computes the set of environment variables that define a Devbox
@@ -765,6 +913,7 @@ func (d *Devbox) computeNixEnv(ctx context.Context, usePrintDevEnvCache bool) (m
 	}
 
 	vaf, err := d.nix.PrintDevEnv(ctx, &nix.PrintDevEnvArgs{
+		ExtraFlags:           d.nixCacheFlags(),
 		FlakesFilePath:       d.nixFlakesFilePath(),
 		PrintDevEnvCachePath: d.nixPrintDevEnvCachePath(),
 		UsePrintDevEnvCache:  usePrintDevEnvCache,
@@ -852,8 +1001,15 @@ func (d *Devbox) computeNixEnv(ctx context.Context, usePrintDevEnvCache bool) (m
 	// We filter out nix store paths of devbox-packages (represented here as buildInputs).
 	// Motivation: if a user removes a package from their devbox it should no longer
 	// be available in their environment.
+	// Overridden packages are the exception since they aren't in the profile.
 	buildInputs := strings.Split(env["buildInputs"], " ")
+	overridden := strings.Split(env["devboxOverrides"], " ")
 	nixEnvPath = filterPathList(nixEnvPath, func(path string) bool {
+		for _, input := range overridden {
+			if strings.TrimSpace(input) != "" && strings.HasPrefix(path, input) {
+				return true
+			}
+		}
 		for _, input := range buildInputs {
 			// input is of the form: /nix/store/<hash>-<package-name>-<version>
 			// path is of the form: /nix/store/<hash>-<package-name>-<version>/bin
@@ -892,22 +1048,10 @@ func (d *Devbox) nixEnv(ctx context.Context) (map[string]string, error) {
 		return nixEnvCache, nil
 	}
 
-	usePrintDevEnvCache := false
-
-	// If lockfile is up-to-date, we can use the print-dev-env cache.
-	lockFile, err := lock.Local(d)
-	if err != nil {
-		return nil, err
-	}
-	upToDate, err := lockFile.IsUpToDate()
-	if err != nil {
-		return nil, err
-	}
-	if upToDate {
-		usePrintDevEnvCache = true
-	}
-
-	return d.computeNixEnv(ctx, usePrintDevEnvCache)
+	// The print-dev-env cache is keyed on the flake, so it's only used if it
+	// was computed for the current packages. The config's env is applied on
+	// top of it by computeNixEnv.
+	return d.computeNixEnv(ctx, true /*use cache*/)
 }
 
 func (d *Devbox) ogPathKey() string {
@@ -944,8 +1088,10 @@ func (d *Devbox) findPackageByName(name string) (string, error) {
 	results := map[string]bool{}
 	for _, pkg := range d.cfg.Packages {
 		i := nix.PackageFromString(pkg, d.lockfile)
-		if i.String() == name || i.CanonicalName() == name {
-			results[i.String()] = true
+		// Compare against the raw string too, since String() escapes version
+		// constraints like `nodejs@^18`.
+		if i.Raw == name || i.String() == name || i.CanonicalName() == name {
+			results[i.Raw] = true
 		}
 	}
 	if len(results) > 1 {
@@ -1045,11 +1191,59 @@ var ignoreDevEnvVar = map[string]bool{
 	"UID":                true,
 }
 
+// nixCacheFlags returns the nix flags for the binary caches in devbox.json.
+func (d *Devbox) nixCacheFlags() []string {
+	return nix.CacheFlags(d.cfg.Substituters(), d.cfg.TrustedPublicKeys())
+}
+
+// warnIfCachesUntrusted warns if the nix daemon will ignore any of the binary
+// caches in devbox.json. Nix silently skips substituters it doesn't trust,
+// which makes packages build from source instead.
+func (d *Devbox) warnIfCachesUntrusted() {
+	substituters, keys, err := nix.UntrustedCaches(
+		d.cfg.Substituters(),
+		d.cfg.TrustedPublicKeys(),
+	)
+	if err != nil {
+		debug.Log("Failed to check nix binary cache trust: %v", err)
+		return
+	}
+	if len(substituters) == 0 && len(keys) == 0 {
+		return
+	}
+	ux.Fwarning(
+		d.writer,
+		"The nix daemon does not trust the binary caches in devbox.json, so "+
+			"packages may be built from source.\n"+
+			"Untrusted substituters: %s\nUntrusted keys: %s\n"+
+			"Add them to trusted-substituters and trusted-public-keys in "+
+			"/etc/nix/nix.conf, or add your user to trusted-users, then restart "+
+			"the nix daemon.\n",
+		lo.Ternary(len(substituters) > 0, strings.Join(substituters, " "), "none"),
+		lo.Ternary(len(keys) > 0, strings.Join(keys, " "), "none"),
+	)
+}
+
 // setCommonHelperEnvVars sets environment variables that are required by some
 // common setups (e.g. gradio, rust)
 func (d *Devbox) setCommonHelperEnvVars(env map[string]string) {
 	env["LD_LIBRARY_PATH"] = filepath.Join(d.projectDir, nix.ProfilePath, "lib") + ":" + env["LD_LIBRARY_PATH"]
 	env["LIBRARY_PATH"] = filepath.Join(d.projectDir, nix.ProfilePath, "lib") + ":" + env["LIBRARY_PATH"]
+
+	// Packages with selected outputs (e.g. `openssl@3^dev,out`) link their
+	// headers and pkg-config files into the profile, so point compilers at them.
+	hasOutputs := lo.ContainsBy(d.PackagesAsInputs(), func(pkg *nix.Package) bool {
+		return len(pkg.Outputs) > 0
+	})
+	if hasOutputs {
+		profileDir := filepath.Join(d.projectDir, nix.ProfilePath)
+		env["PKG_CONFIG_PATH"] = JoinPathLists(
+			filepath.Join(profileDir, "lib", "pkgconfig"),
+			filepath.Join(profileDir, "share", "pkgconfig"),
+			env["PKG_CONFIG_PATH"],
+		)
+		env["CPATH"] = JoinPathLists(filepath.Join(profileDir, "include"), env["CPATH"])
+	}
 }
 
 // NixBins returns the paths to all the nix binaries that are installed by
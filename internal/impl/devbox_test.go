@@ -6,7 +6,9 @@ package impl
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -18,11 +20,16 @@ import (
 	"go.jetpack.io/devbox/internal/devconfig"
 	"go.jetpack.io/devbox/internal/envir"
 	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/impl/shellcmd"
+	"go.jetpack.io/devbox/internal/lock"
 	"go.jetpack.io/devbox/internal/nix"
+	"go.jetpack.io/devbox/internal/shellgen"
 )
 
 func TestDevbox(t *testing.T) {
 	t.Setenv("TMPDIR", "/tmp")
+	// Opening a project records it in the registry of projects.
+	t.Setenv(envir.XDGStateHome, t.TempDir())
 	testPaths, err := doublestar.FilepathGlob("../../examples/**/devbox.json")
 	require.NoError(t, err, "Reading testdata/ should not fail")
 
@@ -68,6 +75,7 @@ func (n *testNix) PrintDevEnv(ctx context.Context, args *nix.PrintDevEnvArgs) (*
 
 func TestComputeNixEnv(t *testing.T) {
 	path := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
 	_, err := devconfig.Init(path, os.Stdout)
 	require.NoError(t, err, "InitConfig should not fail")
 	d, err := Open(&devopt.Opts{
@@ -85,6 +93,7 @@ func TestComputeNixEnv(t *testing.T) {
 
 func TestComputeNixPathIsIdempotent(t *testing.T) {
 	dir := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
 	_, err := devconfig.Init(dir, os.Stdout)
 	require.NoError(t, err, "InitConfig should not fail")
 	devbox, err := Open(&devopt.Opts{
@@ -115,6 +124,7 @@ func TestComputeNixPathIsIdempotent(t *testing.T) {
 
 func TestComputeNixPathWhenRemoving(t *testing.T) {
 	dir := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
 	_, err := devconfig.Init(dir, os.Stdout)
 	require.NoError(t, err, "InitConfig should not fail")
 	devbox, err := Open(&devopt.Opts{
@@ -145,3 +155,97 @@ func TestComputeNixPathWhenRemoving(t *testing.T) {
 
 	assert.NotEqual(t, path, path2, "path should not be the same")
 }
+
+func TestGenerateCIFiles(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	_, err := devconfig.Init(dir, os.Stdout)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: os.Stdout})
+	req.NoError(err)
+	ctx := context.Background()
+
+	req.NoError(d.GenerateGithubActions(ctx, "" /*script*/, false /*force*/))
+	workflow, err := os.ReadFile(filepath.Join(dir, ".github/workflows/devbox.yml"))
+	req.NoError(err)
+	req.Contains(string(workflow), "devbox run test")
+	req.Contains(string(workflow), "branches: [main]")
+
+	// The workflow runs on pushes to the repository's default branch.
+	out, err := exec.Command("git", "init", "--initial-branch=trunk", dir).CombinedOutput()
+	req.NoError(err, string(out))
+	req.NoError(d.GenerateGithubActions(ctx, "" /*script*/, true /*force*/))
+	workflow, err = os.ReadFile(filepath.Join(dir, ".github/workflows/devbox.yml"))
+	req.NoError(err)
+	req.Contains(string(workflow), "branches: [trunk]")
+
+	req.NoError(d.GenerateGitlabCI(ctx, "test", false /*force*/))
+	pipeline, err := os.ReadFile(filepath.Join(dir, ".gitlab-ci.yml"))
+	req.NoError(err)
+	req.Contains(string(pipeline), "devbox run test")
+	req.Contains(string(pipeline), "- devbox.lock")
+
+	req.Error(d.GenerateGitlabCI(ctx, "test", false /*force*/), "should not overwrite")
+	req.Error(d.GenerateGitlabCI(ctx, "missing", true /*force*/), "script must exist")
+}
+
+func TestEnvAndScriptChangesDontNeedNix(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	_, err := devconfig.Init(dir, os.Stdout)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: os.Stdout})
+	req.NoError(err)
+	req.NoError(os.MkdirAll(filepath.Join(dir, ".devbox"), 0755))
+	localLock, err := lock.Local(d)
+	req.NoError(err)
+	req.NoError(localLock.Update())
+
+	d.cfg.Env = map[string]string{"FOO": "bar"}
+	d.cfg.Shell.Scripts["hello"] = &shellcmd.Commands{Cmds: []string{"echo hello"}}
+	req.NoError(d.cfg.SaveTo(dir))
+
+	d, err = Open(&devopt.Opts{Dir: dir, Writer: os.Stdout})
+	req.NoError(err)
+	localLock, err = lock.Local(d)
+	req.NoError(err)
+	upToDate, err := localLock.IsUpToDate()
+	req.NoError(err)
+	req.True(upToDate, "changing env and scripts shouldn't require installing packages")
+
+	// The scripts are written without installing packages.
+	req.NoError(d.ensurePackagesAreInstalled(context.Background(), ensure))
+	script, err := os.ReadFile(shellgen.ScriptPath(dir, "hello"))
+	req.NoError(err)
+	req.Contains(string(script), "echo hello")
+
+	d.nix = &testNix{}
+	env, err := d.computeNixEnv(context.Background(), true /*use cache*/)
+	req.NoError(err)
+	req.Equal("bar", env["FOO"])
+}
+
+// BenchmarkComputeNixEnv measures the part of starting a shell that happens
+// in-process once the output of nix print-dev-env is cached: applying the
+// current environment, plugins and the config's env. See also
+// nix.BenchmarkPrintDevEnvCached.
+func BenchmarkComputeNixEnv(b *testing.B) {
+	dir := b.TempDir()
+	b.Setenv(envir.XDGStateHome, b.TempDir())
+	_, err := devconfig.Init(dir, io.Discard)
+	require.NoError(b, err)
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+	require.NoError(b, err)
+	d.cfg.Env = map[string]string{"FOO": "bar", "GOPATH": "$PWD/.go"}
+	d.nix = &testNix{"/nix/store/a-go-1.20.5/bin"}
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := d.computeNixEnv(ctx, true /*use cache*/); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
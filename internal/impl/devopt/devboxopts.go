@@ -8,5 +8,50 @@ type Opts struct {
 	Dir            string
 	Pure           bool
 	IgnoreWarnings bool
-	Writer         io.Writer
+	// ShellHook is set when devbox is run by the shell hook, which runs every
+	// time the prompt is shown, so it must not wait on other devbox commands.
+	ShellHook bool
+	Writer    io.Writer
+}
+
+type UpdateOpts struct {
+	Pkgs   []string
+	DryRun bool
+}
+
+type PullboxOpts struct {
+	// URL is where the global config is stored. Leave empty to use devbox
+	// cloud.
+	URL string
+	// Overwrite replaces a global profile that isn't empty when pulling.
+	Overwrite bool
+	// AllowUnsigned pulls configs that aren't signed by a trusted key.
+	AllowUnsigned bool
+}
+
+type SyncOpts struct {
+	// URL is where the global config is stored. Leave empty to use devbox
+	// cloud.
+	URL    string
+	DryRun bool
+	// AllowUnsigned pulls configs that aren't signed by a trusted key.
+	AllowUnsigned bool
+	// Confirm is called after the changes are shown and before they're
+	// applied. The sync is canceled if it returns false.
+	Confirm func() (bool, error)
+}
+
+type GCOpts struct {
+	// Dir is a project to collect in addition to the projects that devbox
+	// finds from their nix GC roots, the global profiles and the project
+	// registry. It's skipped if it isn't in a project.
+	Dir string
+	// Keep is the number of most recent profile generations to keep in each
+	// project. The current generation and the one that `devbox rollback`
+	// restores are always kept.
+	Keep int
+	// Store runs `nix store gc` to delete the store paths that are no longer
+	// used.
+	Store  bool
+	DryRun bool
 }
@@ -4,6 +4,7 @@
 package impl
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -43,7 +44,11 @@ func findProjectDirAtPath(absPath string) (string, error) {
 
 	switch mode := fi.Mode(); {
 	case mode.IsDir():
-		if !fileutil.Exists(filepath.Join(absPath, devconfig.DefaultName)) {
+		found, err := hasConfig(absPath)
+		if err != nil {
+			return "", err
+		}
+		if !found {
 			return "", missingConfigError(absPath, false /*didCheckParents*/)
 		}
 		return absPath, nil
@@ -61,18 +66,32 @@ func findProjectDirFromParentDirSearch(
 	absPath string,
 ) (string, error) {
 	cur := absPath
-	// Search parent directories for a devbox.json
-	for cur != root {
-		debug.Log("finding %s in dir: %s\n", devconfig.DefaultName, cur)
-		if fileutil.Exists(filepath.Join(cur, devconfig.DefaultName)) {
+	// Search parent directories for a devbox config
+	for {
+		debug.Log("finding a devbox config in dir: %s\n", cur)
+		found, err := hasConfig(cur)
+		if err != nil {
+			return "", err
+		}
+		if found {
 			return cur, nil
 		}
-		cur = filepath.Dir(cur)
+		parent := filepath.Dir(cur)
+		if cur == root || parent == cur {
+			return "", missingConfigError(absPath, true /*didCheckParents*/)
+		}
+		cur = parent
 	}
-	if fileutil.Exists(filepath.Join(cur, devconfig.DefaultName)) {
-		return cur, nil
+}
+
+// hasConfig reports whether dir has a devbox config. It's an error for dir to
+// have more than one.
+func hasConfig(dir string) (bool, error) {
+	_, err := devconfig.Find(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
 	}
-	return "", missingConfigError(absPath, true /*didCheckParents*/)
+	return err == nil, err
 }
 
 func missingConfigError(path string, didCheckParents bool) error {
@@ -102,7 +121,7 @@ func missingConfigError(path string, didCheckParents bool) error {
 	}
 
 	return usererr.New(
-		"No devbox.json found in %s%s. Did you run `devbox init` yet?",
+		"No devbox.json, devbox.yaml or devbox.toml found in %s%s. Did you run `devbox init` yet?",
 		path,
 		parentDirCheckAddendum,
 	)
@@ -140,6 +140,27 @@ func TestFindParentDirAtPath(t *testing.T) {
 	}
 }
 
+func TestFindProjectDirOtherFormats(t *testing.T) {
+	assert := assert.New(t)
+	root, err := filepath.Abs(t.TempDir())
+	assert.NoError(err)
+	projectDir := filepath.Join(root, "a")
+	searchDir := filepath.Join(projectDir, "b")
+	assert.NoError(os.MkdirAll(searchDir, 0777))
+
+	assert.NoError(os.WriteFile(filepath.Join(projectDir, "devbox.toml"), []byte("packages = []"), 0666))
+	result, err := findProjectDirFromParentDirSearch(root, searchDir)
+	assert.NoError(err)
+	assert.Equal(projectDir, result)
+
+	// A project with two configs is ambiguous.
+	assert.NoError(os.WriteFile(filepath.Join(projectDir, "devbox.yaml"), []byte("packages: []"), 0666))
+	_, err = findProjectDirFromParentDirSearch(root, searchDir)
+	assert.ErrorContains(err, "more than one devbox config")
+	_, err = findProjectDirAtPath(projectDir)
+	assert.ErrorContains(err, "more than one devbox config")
+}
+
 func TestNixpkgsValidation(t *testing.T) {
 	testCases := map[string]struct {
 		commit   string
@@ -0,0 +1,69 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"runtime/trace"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/envsnapshot"
+	"go.jetpack.io/devbox/internal/nix"
+)
+
+// EnvSnapshot returns the environment that devbox computes for the project,
+// which is the Nix environment with the plugin and config variables, along
+// with the versions and store paths that its packages resolved to. Unless the
+// project was opened with Pure, the environment includes the variables
+// inherited from the current one, which may be secrets.
+func (d *Devbox) EnvSnapshot(ctx context.Context) (*envsnapshot.Snapshot, error) {
+	ctx, task := trace.NewTask(ctx, "devboxEnvSnapshot")
+	defer task.End()
+
+	if err := d.ensurePackagesAreInstalled(ctx, ensure); err != nil {
+		return nil, err
+	}
+	system, err := nix.System()
+	if err != nil {
+		return nil, err
+	}
+	return d.envSnapshot(ctx, system)
+}
+
+// envSnapshot returns the snapshot of the project's environment on system,
+// once its packages are installed.
+func (d *Devbox) envSnapshot(ctx context.Context, system string) (*envsnapshot.Snapshot, error) {
+	env, err := d.nixEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &envsnapshot.Snapshot{
+		Version:    envsnapshot.Version,
+		ProjectDir: d.projectDir,
+		System:     system,
+		Packages:   []*envsnapshot.Package{},
+		Env:        map[string]string{},
+	}
+	for k, v := range env {
+		snapshot.Env[k] = v
+	}
+
+	// buildInputs has the store paths of the packages in the flake.
+	buildInputs := strings.Fields(env["buildInputs"])
+	for _, input := range d.PackagesAsInputs() {
+		pkg := &envsnapshot.Package{Name: input.Raw}
+		storeName := input.CanonicalName()
+		if locked := d.lockfile.Packages[input.Raw]; locked != nil {
+			pkg.Version = locked.Version
+			pkg.Resolved = locked.Resolved
+			if info := locked.Systems[system]; info != nil && info.StoreName != "" {
+				storeName = info.StoreName
+			}
+		}
+		pkg.StorePaths = envsnapshot.StorePathsFor(storeName, buildInputs)
+		snapshot.Packages = append(snapshot.Packages, pkg)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+func TestEnvSnapshotExcludesHostSecrets(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	t.Setenv("DEVBOX_TEST_SECRET", "hunter2")
+	// A pure environment keeps the nix installation in PATH.
+	t.Setenv("PATH", "/nix/var/nix/profiles/default/bin:"+os.Getenv("PATH"))
+	dir := t.TempDir()
+	_, err := devconfig.Init(dir, io.Discard)
+	require.NoError(t, err)
+
+	for _, pure := range []bool{true, false} {
+		d, err := Open(&devopt.Opts{Dir: dir, Pure: pure, Writer: io.Discard})
+		require.NoError(t, err)
+		d.cfg.Env = map[string]string{"FOO": "bar"}
+		d.nix = &testNix{"/nix/store/a-go-1.20.5/bin"}
+
+		snapshot, err := d.envSnapshot(context.Background(), "x86_64-linux")
+		require.NoError(t, err)
+		require.Equal(t, "bar", snapshot.Env["FOO"])
+		if pure {
+			require.NotContains(t, snapshot.Env, "DEVBOX_TEST_SECRET")
+		} else {
+			require.Equal(t, "hunter2", snapshot.Env["DEVBOX_TEST_SECRET"])
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"runtime/trace"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/nix"
+	"go.jetpack.io/devbox/internal/projects"
+	"go.jetpack.io/devbox/internal/ux"
+	"go.jetpack.io/devbox/internal/ux/stepper"
+)
+
+// projectRootMarker is in the path of every GC root that belongs to a
+// project, such as <project>/.devbox/nix/profile/default-3-link.
+const projectRootMarker = string(filepath.Separator) + ".devbox" + string(filepath.Separator)
+
+// GC removes what keeps old packages in the nix store: profile generations
+// that are no longer needed, the GC roots of projects that were deleted, and
+// stale entries in the nixpkgs cache. If opts.Store is set, it then runs `nix
+// store gc` to delete the store paths that are no longer used, and reports
+// the space freed.
+func GC(ctx context.Context, opts devopt.GCOpts, w io.Writer) error {
+	ctx, task := trace.NewTask(ctx, "devboxGC")
+	defer task.End()
+
+	roots, err := nix.AutoGCRoots()
+	if err != nil {
+		return err
+	}
+	projects, err := gcProjects(opts.Dir, roots)
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if opts.DryRun {
+		verb = "Would remove"
+	}
+
+	removedGenerations := 0
+	for _, dir := range projects {
+		removed, err := pruneProfileGenerations(
+			filepath.Join(dir, nix.ProfilePath),
+			rollbackGeneration(dir),
+			opts,
+		)
+		if err != nil {
+			return err
+		}
+		removedGenerations += removed
+	}
+	if utilProfile, err := utilityNixProfilePath(); err == nil {
+		removed, err := pruneProfileGenerations(utilProfile, 0, opts)
+		if err != nil {
+			return err
+		}
+		removedGenerations += removed
+	}
+	if removedGenerations > 0 {
+		ux.Fsuccess(w, "%s %d old profile generations\n", verb, removedGenerations)
+	}
+
+	deadProjects, err := removeDeadProjectRoots(roots, opts, w)
+	if err != nil {
+		return err
+	}
+	if len(deadProjects) > 0 {
+		ux.Fsuccess(w, "%s the GC roots of %d deleted projects:\n", verb, len(deadProjects))
+		for _, dir := range deadProjects {
+			fmt.Fprintf(w, "  %s\n", dir)
+		}
+	}
+
+	if removedGenerations == 0 && len(deadProjects) == 0 {
+		ux.Finfo(w, "No old profile generations or GC roots to remove\n")
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	if opts.Store {
+		result, err := nix.StoreGC(ctx, w)
+		if err != nil {
+			return err
+		}
+		ux.Fsuccess(w, "Freed %s by deleting %d store paths\n", stepper.FormatBytes(result.Freed), result.Paths)
+	}
+
+	// The cache of prefetched nixpkgs is pruned after collecting the store,
+	// which may delete them.
+	if _, err := nix.PruneNixpkgsCommitFile(); err != nil {
+		return err
+	}
+	if !opts.Store {
+		ux.Finfo(w, "Run `devbox gc --store` to delete the packages that are no longer used from the nix store\n")
+	}
+	return nil
+}
+
+// gcProjects returns the directories of the projects to collect: the project
+// in dir, if there is one, the global profiles, the projects in the registry,
+// and the projects that have GC roots.
+func gcProjects(dir string, roots []*nix.GCRoot) ([]string, error) {
+	seen := map[string]bool{}
+	if projectDir, err := findProjectDir(dir); err == nil {
+		seen[projectDir] = true
+	}
+	profiles, err := ListGlobalProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range profiles {
+		seen[globalProfilePath(name)] = true
+	}
+	registered, err := projects.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range registered {
+		if fileutil.IsDir(p.Dir) {
+			seen[p.Dir] = true
+		}
+	}
+	for _, root := range roots {
+		if projectDir, ok := rootProjectDir(root); ok && fileutil.IsDir(projectDir) {
+			seen[projectDir] = true
+		}
+	}
+
+	projects := make([]string, 0, len(seen))
+	for dir := range seen {
+		projects = append(projects, dir)
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// rootProjectDir returns the project that a GC root is in, if any.
+func rootProjectDir(root *nix.GCRoot) (string, bool) {
+	i := strings.LastIndex(root.Target, projectRootMarker)
+	if i <= 0 {
+		return "", false
+	}
+	return root.Target[:i], true
+}
+
+// rollbackGeneration returns the profile generation that `devbox rollback`
+// restores in the project, or 0.
+func rollbackGeneration(projectDir string) int {
+	s := &snapshot{}
+	if err := cuecfg.ParseFile(filepath.Join(projectDir, rollbackSnapshotPath), s); err != nil {
+		return 0
+	}
+	return s.Generation
+}
+
+// pruneProfileGenerations removes the generations of a profile except the
+// current one, the one to keep for rollback, and the opts.Keep newest ones.
+// It returns how many it removed, or would remove in a dry run.
+func pruneProfileGenerations(profilePath string, rollback int, opts devopt.GCOpts) (int, error) {
+	generations, err := nix.ProfileGenerations(profilePath)
+	if err != nil {
+		return 0, err
+	}
+	current, err := nix.ProfileGeneration(profilePath)
+	if err != nil {
+		return 0, err
+	}
+	keep := map[int]bool{current: true, rollback: true}
+	for i := len(generations) - opts.Keep; i < len(generations); i++ {
+		if i >= 0 {
+			keep[generations[i]] = true
+		}
+	}
+
+	removed := 0
+	for _, generation := range generations {
+		if keep[generation] {
+			continue
+		}
+		if !opts.DryRun {
+			if err := nix.DeleteProfileGeneration(profilePath, generation); err != nil {
+				return removed, err
+			}
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// removeDeadProjectRoots removes the GC roots of projects whose directories
+// no longer exist, and returns those directories.
+func removeDeadProjectRoots(roots []*nix.GCRoot, opts devopt.GCOpts, w io.Writer) ([]string, error) {
+	dead := map[string]bool{}
+	for _, root := range roots {
+		projectDir, ok := rootProjectDir(root)
+		if !ok || fileutil.Exists(projectDir) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := root.Remove(); errors.Is(err, fs.ErrPermission) {
+				// On multi-user installs, the roots may belong to
+				// root. nix store gc ignores them anyway, since
+				// they point to files that don't exist.
+				ux.Fwarning(w, "Can't remove GC root %s: %s\n", root.Link, err)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		dead[projectDir] = true
+	}
+
+	dirs := make([]string, 0, len(dead))
+	for dir := range dead {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
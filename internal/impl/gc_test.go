@@ -0,0 +1,88 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/nix"
+)
+
+func TestPruneProfileGenerations(t *testing.T) {
+	req := require.New(t)
+	profile := filepath.Join(t.TempDir(), "default")
+	for g := 1; g <= 6; g++ {
+		req.NoError(os.Symlink("/nix/store/a-profile", nix.ProfileGenerationPath(profile, g)))
+	}
+	req.NoError(os.Symlink(filepath.Base(nix.ProfileGenerationPath(profile, 5)), profile))
+
+	removed, err := pruneProfileGenerations(profile, 2, devopt.GCOpts{Keep: 1, DryRun: true})
+	req.NoError(err)
+	req.Equal(3, removed)
+	generations, err := nix.ProfileGenerations(profile)
+	req.NoError(err)
+	req.Len(generations, 6, "a dry run shouldn't remove generations")
+
+	// The current generation (5), the rollback generation (2) and the
+	// newest generation (6) are kept.
+	removed, err = pruneProfileGenerations(profile, 2, devopt.GCOpts{Keep: 1})
+	req.NoError(err)
+	req.Equal(3, removed)
+	generations, err = nix.ProfileGenerations(profile)
+	req.NoError(err)
+	req.Equal([]int{2, 5, 6}, generations)
+}
+
+func TestRemoveDeadProjectRoots(t *testing.T) {
+	req := require.New(t)
+	rootsDir := t.TempDir()
+	liveProject := t.TempDir()
+	deadProject := filepath.Join(t.TempDir(), "deleted")
+
+	newRoot := func(name, target string) *nix.GCRoot {
+		link := filepath.Join(rootsDir, name)
+		req.NoError(os.Symlink(target, link))
+		return &nix.GCRoot{Link: link, Target: target}
+	}
+	roots := []*nix.GCRoot{
+		newRoot("live", filepath.Join(liveProject, nix.ProfilePath+"-1-link")),
+		newRoot("dead1", filepath.Join(deadProject, nix.ProfilePath+"-1-link")),
+		newRoot("dead2", filepath.Join(deadProject, nix.ProfilePath+"-2-link")),
+		newRoot("other", "/home/user/.local/state/nix/profiles/profile-1-link"),
+	}
+
+	dead, err := removeDeadProjectRoots(roots, devopt.GCOpts{}, io.Discard)
+	req.NoError(err)
+	req.Equal([]string{deadProject}, dead)
+	linkExists := func(root *nix.GCRoot) bool {
+		_, err := os.Lstat(root.Link)
+		return err == nil
+	}
+	req.True(linkExists(roots[0]))
+	req.False(linkExists(roots[1]))
+	req.False(linkExists(roots[2]))
+	req.True(linkExists(roots[3]), "roots that aren't in projects should be kept")
+}
+
+func TestGCProjects(t *testing.T) {
+	t.Setenv(envir.DevboxGlobalData, t.TempDir())
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	project := t.TempDir()
+	roots := []*nix.GCRoot{
+		{Target: filepath.Join(project, nix.ProfilePath+"-3-link")},
+		{Target: filepath.Join(t.TempDir(), "deleted", nix.ProfilePath+"-1-link")},
+		{Target: "/nix/var/nix/profiles/default-1-link"},
+	}
+
+	projects, err := gcProjects(t.TempDir(), roots)
+	require.NoError(t, err)
+	require.Equal(t, []string{project}, projects)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package generate
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime/trace"
+	"strings"
+	"text/template"
+)
+
+type ciData struct {
+	// Script is the devbox.json script that CI runs.
+	Script string
+	// JobID is the script name with characters that aren't allowed in job
+	// IDs replaced.
+	JobID string
+
+	// dir is the directory that the CI file is written to.
+	dir string
+}
+
+// DefaultBranch returns the default branch of the git repository that the CI
+// file is in. It's the branch that origin/HEAD points to or, for a repository
+// without a remote, the current branch. It falls back to main outside of a git
+// repository.
+func (d *ciData) DefaultBranch() string {
+	for _, args := range [][]string{
+		{"symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"},
+		{"symbolic-ref", "--quiet", "--short", "HEAD"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", d.dir}, args...)...).Output()
+		if err != nil {
+			continue
+		}
+		branch := strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/")
+		if branch != "" {
+			return branch
+		}
+	}
+	return "main"
+}
+
+var invalidJobIDRegex = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// CreateGithubWorkflow writes a GitHub Actions workflow that installs devbox
+// and runs script to path.
+func CreateGithubWorkflow(ctx context.Context, path, script string) error {
+	defer trace.StartRegion(ctx, "createGithubWorkflow").End()
+	return createCIFile(path, "githubActions.tmpl", script)
+}
+
+// CreateGitlabCI writes a .gitlab-ci.yml that installs devbox and runs script
+// to path.
+func CreateGitlabCI(ctx context.Context, path, script string) error {
+	defer trace.StartRegion(ctx, "createGitlabCI").End()
+	return createCIFile(path, "gitlabCI.tmpl", script)
+}
+
+func createCIFile(path, tmplName, script string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// CI files are YAML, so use text/template to avoid HTML escaping.
+	t := template.Must(template.ParseFS(tmplFS, "tmpl/"+tmplName))
+	return t.Execute(file, &ciData{
+		Script: script,
+		JobID:  invalidJobIDRegex.ReplaceAllString(script, "-"),
+		dir:    filepath.Dir(path),
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime/trace"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+)
+
+// UpdateDevcontainer adds what devbox would generate to an existing
+// devcontainer.json in path. Ports, mounts and extensions are merged with the
+// existing ones, and settings and the postCreateCommand are only set if they
+// are missing, so that user changes are kept.
+func UpdateDevcontainer(ctx context.Context, path string, opts *DevcontainerOpts) error {
+	defer trace.StartRegion(ctx, "updateDevcontainer").End()
+
+	jsonPath := filepath.Join(path, "devcontainer.json")
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	existing := map[string]any{}
+	if err := cuecfg.Unmarshal(data, ".json", &existing); err != nil {
+		return errors.Wrapf(err, "error parsing %s", jsonPath)
+	}
+	mergeDevcontainer(existing, getDevcontainerContent(opts))
+
+	// Only the merged values are rewritten, so the comments, key order and
+	// formatting of the rest of the file are kept.
+	updated, err := cuecfg.EditJSON(data, &existing)
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(os.WriteFile(jsonPath, updated, 0o644))
+}
+
+func mergeDevcontainer(existing map[string]any, generated *devcontainerObject) {
+	for _, port := range generated.ForwardPorts {
+		existing["forwardPorts"] = appendMissing(existing["forwardPorts"], float64(port))
+	}
+	for _, mount := range generated.Mounts {
+		existing["mounts"] = appendMissing(existing["mounts"], mount)
+	}
+	if _, ok := existing["postCreateCommand"]; !ok {
+		existing["postCreateCommand"] = generated.PostCreateCommand
+	}
+
+	vsc := childObject(childObject(existing, "customizations"), "vscode")
+	for _, ext := range generated.Customizations.Vscode.Extensions {
+		vsc["extensions"] = appendMissing(vsc["extensions"], ext)
+	}
+	settings := childObject(vsc, "settings")
+	for k, v := range generated.Customizations.Vscode.Settings {
+		if _, ok := settings[k]; !ok {
+			settings[k] = v
+		}
+	}
+}
+
+// childObject returns the object at key in parent, creating it if it's
+// missing or isn't an object.
+func childObject(parent map[string]any, key string) map[string]any {
+	child, ok := parent[key].(map[string]any)
+	if !ok {
+		child = map[string]any{}
+		parent[key] = child
+	}
+	return child
+}
+
+// appendMissing appends v to the JSON array list unless it already contains
+// it.
+func appendMissing(list any, v any) []any {
+	arr, _ := list.([]any)
+	if slices.Contains(arr, v) {
+		return arr
+	}
+	return append(arr, v)
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+)
+
+func TestGetDevcontainerContent(t *testing.T) {
+	content := getDevcontainerContent(&DevcontainerOpts{
+		Packages: []string{"python310@3.10", "go@1.21", "hello"},
+		Ports:    []int{5432},
+	})
+	require.Equal(t, []string{"jetpack-io.devbox", "ms-python.python", "golang.go"},
+		content.Customizations.Vscode.Extensions)
+	require.Contains(t, content.Customizations.Vscode.Settings, "python.defaultInterpreterPath")
+	require.Equal(t, []int{5432}, content.ForwardPorts)
+	require.Equal(t, []string{nixStoreMount}, content.Mounts)
+	require.Equal(t, postCreateCommand, content.PostCreateCommand)
+}
+
+func TestUpdateDevcontainer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "devcontainer.json")
+	existing := `{
+  // Customized by the user.
+  "name": "My Container",
+  "forwardPorts": [3000],
+  "postCreateCommand": "make setup",
+  "customizations": {
+    "vscode": {
+      "extensions": ["golang.go"],
+      "settings": {"editor.tabSize": 2},
+    },
+  },
+}`
+	require.NoError(t, os.WriteFile(path, []byte(existing), 0o644))
+
+	err := UpdateDevcontainer(context.Background(), dir, &DevcontainerOpts{
+		Packages: []string{"go@latest", "redis@latest"},
+		Ports:    []int{3000, 6379},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	got := map[string]any{}
+	require.NoError(t, cuecfg.Unmarshal(data, ".json", &got))
+	require.Equal(t, map[string]any{
+		"name":              "My Container",
+		"forwardPorts":      []any{3000.0, 6379.0},
+		"postCreateCommand": "make setup",
+		"mounts":            []any{nixStoreMount},
+		"customizations": map[string]any{
+			"vscode": map[string]any{
+				"extensions": []any{"golang.go", "jetpack-io.devbox"},
+				"settings":   map[string]any{"editor.tabSize": 2.0},
+			},
+		},
+	}, got)
+
+	// The user's comments and key order are kept, and new keys are added at
+	// the end.
+	require.Equal(t, `{
+  // Customized by the user.
+  "name": "My Container",
+  "forwardPorts": [3000, 6379],
+  "postCreateCommand": "make setup",
+  "customizations": {
+    "vscode": {
+      "extensions": ["golang.go", "jetpack-io.devbox"],
+      "settings": {"editor.tabSize": 2},
+    },
+  },
+  "mounts": [
+    "`+nixStoreMount+`"
+  ],
+}`, string(data))
+}
@@ -13,22 +13,24 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"runtime/trace"
-	"strings"
+
+	"github.com/samber/lo"
 
 	"go.jetpack.io/devbox/internal/boxcli/featureflag"
-	"go.jetpack.io/devbox/internal/debug"
 )
 
 //go:embed tmpl/*
 var tmplFS embed.FS
 
 type devcontainerObject struct {
-	Name           string          `json:"name"`
-	Build          *build          `json:"build"`
-	Customizations *customizations `json:"customizations"`
-	RemoteUser     string          `json:"remoteUser"`
+	Name              string          `json:"name"`
+	Build             *build          `json:"build"`
+	Customizations    *customizations `json:"customizations"`
+	ForwardPorts      []int           `json:"forwardPorts,omitempty"`
+	PostCreateCommand string          `json:"postCreateCommand,omitempty"`
+	Mounts            []string        `json:"mounts,omitempty"`
+	RemoteUser        string          `json:"remoteUser"`
 }
 
 type build struct {
@@ -41,17 +43,30 @@ type customizations struct {
 }
 
 type vscode struct {
-	Settings   any      `json:"settings"`
-	Extensions []string `json:"extensions"`
+	Settings   map[string]any `json:"settings"`
+	Extensions []string       `json:"extensions"`
+}
+
+// DevcontainerOpts are the project details that devcontainer.json is
+// generated from.
+type DevcontainerOpts struct {
+	// Packages are the packages in devbox.json.
+	Packages []string
+	// Ports are the ports of the project's plugin services.
+	Ports []int
 }
 
 type dockerfileData struct {
+	// ConfigFile is the name of the project's config file, such as
+	// devbox.json.
+	ConfigFile     string
 	IsDevcontainer bool
 	LocalFlakeDirs []string
 }
 
-// CreateDockerfile creates a Dockerfile in path and writes devcontainerDockerfile.tmpl's content into it
-func CreateDockerfile(ctx context.Context, path string, localFlakeDirs []string, isDevcontainer bool) error {
+// CreateDockerfile creates a Dockerfile in path and writes devcontainerDockerfile.tmpl's content into it.
+// configFile is the name of the project's config file, which the Dockerfile copies.
+func CreateDockerfile(ctx context.Context, path, configFile string, localFlakeDirs []string, isDevcontainer bool) error {
 	defer trace.StartRegion(ctx, "createDockerfile").End()
 
 	// create dockerfile
@@ -65,13 +80,14 @@ func CreateDockerfile(ctx context.Context, path string, localFlakeDirs []string,
 	t := template.Must(template.ParseFS(tmplFS, "tmpl/"+tmplName))
 	// write content into file
 	return t.Execute(file, &dockerfileData{
+		ConfigFile:     configFile,
 		IsDevcontainer: isDevcontainer,
 		LocalFlakeDirs: localFlakeDirs,
 	})
 }
 
 // CreateDevcontainer creates a devcontainer.json in path and writes getDevcontainerContent's output into it
-func CreateDevcontainer(ctx context.Context, path string, pkgs []string) error {
+func CreateDevcontainer(ctx context.Context, path string, opts *DevcontainerOpts) error {
 	defer trace.StartRegion(ctx, "createDevcontainer").End()
 
 	// create devcontainer.json file
@@ -81,7 +97,7 @@ func CreateDevcontainer(ctx context.Context, path string, pkgs []string) error {
 	}
 	defer file.Close()
 	// get devcontainer.json's content
-	devcontainerContent := getDevcontainerContent(pkgs)
+	devcontainerContent := getDevcontainerContent(opts)
 	devcontainerFileBytes, err := json.MarshalIndent(devcontainerContent, "", "  ")
 	if err != nil {
 		return err
@@ -107,7 +123,7 @@ func CreateEnvrc(ctx context.Context, path string) error {
 	return t.Execute(file, nil)
 }
 
-func getDevcontainerContent(pkgs []string) *devcontainerObject {
+func getDevcontainerContent(opts *DevcontainerOpts) *devcontainerObject {
 	// object that gets written in devcontainer.json
 	devcontainerContent := &devcontainerObject{
 		// For format details, see https://aka.ms/devcontainer.json. For config options, see the README at:
@@ -128,41 +144,39 @@ func getDevcontainerContent(pkgs []string) *devcontainerObject {
 				},
 			},
 		},
+		ForwardPorts: opts.Ports,
+		// The workspace is mounted after the image is built, so install
+		// packages again in case devbox.json changed and run the init_hook.
+		PostCreateCommand: postCreateCommand,
+		Mounts:            []string{nixStoreMount},
 		// Comment out to connect as root instead. More info: https://aka.ms/vscode-remote/containers/non-root.
 		RemoteUser: "devbox",
 	}
 
-	// match only python3 or python3xx as package names
-	py3pattern, err := regexp.Compile(`(python3)$|(python3[0-9]{1,2})$`)
-	if err != nil {
-		debug.Log("Failed to compile regex")
-		return nil
-	}
-	for _, pkg := range pkgs {
-		if py3pattern.MatchString(pkg) {
-			// Setup python3 interpreter path to devbox in the container
-			devcontainerContent.Customizations.Vscode.Settings = map[string]any{
-				"python.defaultInterpreterPath": "/code/.devbox/nix/profile/default/bin/python3",
-			}
-			// add python extension if a python3 package is installed
-			devcontainerContent.Customizations.Vscode.Extensions =
-				append(devcontainerContent.Customizations.Vscode.Extensions, "ms-python.python")
+	for _, pkg := range opts.Packages {
+		lang := packageLanguage(pkg)
+		if lang == nil {
+			continue
 		}
-		if strings.Contains(pkg, "go_1_") || pkg == "go" {
-			devcontainerContent.Customizations.Vscode.Extensions =
-				append(devcontainerContent.Customizations.Vscode.Extensions, "golang.go")
+		devcontainerContent.Customizations.Vscode.Extensions = lo.Uniq(append(
+			devcontainerContent.Customizations.Vscode.Extensions, lang.extensions...))
+		for k, v := range lang.settings {
+			devcontainerContent.Customizations.Vscode.Settings[k] = v
 		}
-		// TODO: add support for other common languages
 	}
 	return devcontainerContent
 }
 
-func EnvrcContent(w io.Writer) error {
+// EnvrcContent writes the script that .envrc evaluates. configPath is the
+// path of the project's config file, which direnv watches for changes.
+func EnvrcContent(w io.Writer, configPath string) error {
 	tmplName := "envrcContent.tmpl"
 	t := template.Must(template.ParseFS(tmplFS, "tmpl/"+tmplName))
 	return t.Execute(w, struct {
+		ConfigFile        string
 		PromptHookEnabled bool
 	}{
+		ConfigFile:        configPath,
 		PromptHookEnabled: featureflag.PromptHook.Enabled(),
 	})
 }
@@ -0,0 +1,32 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package generate
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDockerfileConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	err := CreateDockerfile(context.Background(), dir, "devbox.yaml", nil, false /* isDevcontainer */)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "COPY devbox.yaml devbox.yaml\n")
+	require.NotContains(t, string(data), "devbox.json")
+}
+
+func TestEnvrcContentConfigFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, EnvrcContent(buf, "devbox.toml"))
+	require.Contains(t, buf.String(), `watch_file "devbox.toml"`)
+	require.NotContains(t, buf.String(), "devbox.json")
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package generate
+
+import (
+	"regexp"
+
+	"go.jetpack.io/devbox/internal/devpkg"
+)
+
+const (
+	// postCreateCommand installs packages and runs the init_hook once the
+	// container is created.
+	postCreateCommand = "devbox install && devbox run -- true"
+
+	// nixStoreMount keeps /nix in a named volume so that the nix store is
+	// reused when the container is rebuilt. Each dev container gets its own
+	// volume, which docker fills with the image's /nix when it's created, so
+	// that projects don't share a store built from a different image.
+	nixStoreMount = "source=devbox-nix-store-${devcontainerId},target=/nix,type=volume"
+)
+
+// language is the VS Code configuration for a language.
+type language struct {
+	// match matches the package names, without version, of the language.
+	match      *regexp.Regexp
+	extensions []string
+	settings   map[string]any
+}
+
+var languages = []language{
+	{
+		match:      regexp.MustCompile(`^python(3[0-9]*)?$`),
+		extensions: []string{"ms-python.python"},
+		settings: map[string]any{
+			// Setup python3 interpreter path to devbox in the container
+			"python.defaultInterpreterPath": "/code/.devbox/nix/profile/default/bin/python3",
+		},
+	},
+	{
+		match:      regexp.MustCompile(`^go(_1_[0-9]+)?$`),
+		extensions: []string{"golang.go"},
+	},
+	{
+		match:      regexp.MustCompile(`^nodejs(-[0-9]+_x|_[0-9]+)?$`),
+		extensions: []string{"dbaeumer.vscode-eslint"},
+	},
+	{
+		match:      regexp.MustCompile(`^(rustup|rustc|cargo)$`),
+		extensions: []string{"rust-lang.rust-analyzer"},
+	},
+	{
+		match:      regexp.MustCompile(`^ruby(_[0-9_]+)?$`),
+		extensions: []string{"Shopify.ruby-lsp"},
+	},
+	{
+		match:      regexp.MustCompile(`^(jdk|openjdk)[0-9]*(_headless)?$`),
+		extensions: []string{"vscjava.vscode-java-pack"},
+	},
+	{
+		match:      regexp.MustCompile(`^php[0-9]*$`),
+		extensions: []string{"bmewburn.vscode-intelephense-client"},
+	},
+	{
+		match:      regexp.MustCompile(`^(ghc|haskell-language-server|stack|cabal-install)$`),
+		extensions: []string{"haskell.haskell"},
+	},
+	{
+		match:      regexp.MustCompile(`^elixir(_[0-9_]+)?$`),
+		extensions: []string{"JakeBecker.elixir-ls"},
+	},
+	{
+		match:      regexp.MustCompile(`^zig$`),
+		extensions: []string{"ziglang.vscode-zig"},
+	},
+	{
+		match:      regexp.MustCompile(`^dotnet-sdk(_[0-9]+)?$`),
+		extensions: []string{"ms-dotnettools.csharp"},
+	},
+	{
+		match:      regexp.MustCompile(`^terraform$`),
+		extensions: []string{"hashicorp.terraform"},
+	},
+}
+
+// packageLanguage returns the language of a devbox package, or nil.
+func packageLanguage(pkg string) *language {
+	name, _, _ := devpkg.ParseVersionedPackage(pkg)
+	for i := range languages {
+		if languages[i].match.MatchString(name) {
+			return &languages[i]
+		}
+	}
+	return nil
+}
@@ -5,17 +5,31 @@ package impl
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/fileutil"
 	"go.jetpack.io/devbox/internal/xdg"
 )
 
-// In the future we will support multiple global profiles
-const currentGlobalProfile = "default"
+// defaultGlobalProfile is the global profile that is active until the user
+// switches to another one.
+const defaultGlobalProfile = "default"
+
+// currentGlobalProfileLink is the symlink in the global directory that points
+// to the active profile.
+const currentGlobalProfileLink = "current"
+
+var globalProfileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
 
 func (d *Devbox) PrintGlobalList() error {
 	for _, p := range d.cfg.Packages {
@@ -24,28 +38,172 @@ func (d *Devbox) PrintGlobalList() error {
 	return nil
 }
 
+// GlobalDataPath returns the directory of the active global profile, creating
+// it if needed.
 func GlobalDataPath() (string, error) {
-	path := xdg.DataSubpath(filepath.Join("devbox/global", currentGlobalProfile))
+	name, err := ActiveGlobalProfile()
+	if err != nil {
+		return "", err
+	}
+	path := globalProfilePath(name)
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return "", errors.WithStack(err)
 	}
+	// Previous versions of devbox didn't create the current symlink, or may
+	// have pointed it at a directory that no longer exists.
+	if _, err := os.Stat(globalCurrentPath()); err != nil {
+		if err := setCurrentGlobalProfile(name); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// globalRoot returns the directory that contains the global profiles. It can
+// be changed with DEVBOX_GLOBAL_DATA.
+func globalRoot() string {
+	if root := os.Getenv(envir.DevboxGlobalData); root != "" {
+		return root
+	}
+	return xdg.DataSubpath("devbox/global")
+}
 
-	nixProfilePath := filepath.Join(path)
-	currentPath := xdg.DataSubpath("devbox/global/current")
+func globalProfilePath(name string) string {
+	return filepath.Join(globalRoot(), name)
+}
+
+func globalCurrentPath() string {
+	return filepath.Join(globalRoot(), currentGlobalProfileLink)
+}
 
-	// For now default is always current. In the future we will support multiple
-	// and allow user to switch. Remove any existing symlink and create a new one
-	// because previous versions of devbox may have created a symlink to a
-	// different profile.
-	existing, _ := os.Readlink(currentPath)
-	if existing != nixProfilePath {
-		_ = os.Remove(currentPath)
+// ActiveGlobalProfile returns the name of the active global profile. It is
+// the profile that DEVBOX_GLOBAL_PROFILE names, or else the last one the user
+// switched to.
+func ActiveGlobalProfile() (string, error) {
+	if name := os.Getenv(envir.DevboxGlobalProfile); name != "" {
+		return name, validateGlobalProfileName(name)
 	}
+	target, err := os.Readlink(globalCurrentPath())
+	if err != nil {
+		return defaultGlobalProfile, nil
+	}
+	if filepath.Dir(target) != filepath.Clean(globalRoot()) ||
+		validateGlobalProfileName(filepath.Base(target)) != nil {
+		return defaultGlobalProfile, nil
+	}
+	return filepath.Base(target), nil
+}
 
-	err := os.Symlink(nixProfilePath, currentPath)
-	if err != nil && !errors.Is(err, fs.ErrExist) {
-		return "", errors.WithStack(err)
+// ListGlobalProfiles returns the names of the global profiles, sorted.
+func ListGlobalProfiles() ([]string, error) {
+	entries, err := os.ReadDir(globalRoot())
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		// The current symlink isn't a directory entry, so it is skipped.
+		if entry.IsDir() && validateGlobalProfileName(entry.Name()) == nil {
+			names = append(names, entry.Name())
+		}
 	}
+	return names, nil
+}
 
-	return path, nil
+// CreateGlobalProfile creates an empty global profile. It doesn't switch to
+// it.
+func CreateGlobalProfile(name string, w io.Writer) error {
+	if err := validateGlobalProfileName(name); err != nil {
+		return err
+	}
+	path := globalProfilePath(name)
+	if fileutil.Exists(path) {
+		return usererr.New("Global profile %q already exists.", name)
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := devconfig.Init(path, w)
+	return err
+}
+
+// SwitchGlobalProfile makes name the active global profile.
+func SwitchGlobalProfile(name string) error {
+	if err := validateGlobalProfileName(name); err != nil {
+		return err
+	}
+	if !fileutil.IsDir(globalProfilePath(name)) {
+		return usererr.New(
+			"Global profile %q does not exist. Create it with `devbox global profile create %[1]s`.",
+			name,
+		)
+	}
+	return setCurrentGlobalProfile(name)
+}
+
+// DeleteGlobalProfile deletes a global profile and all of its files. The
+// active profile can't be deleted.
+func DeleteGlobalProfile(name string) error {
+	if err := validateGlobalProfileName(name); err != nil {
+		return err
+	}
+	active, err := ActiveGlobalProfile()
+	if err != nil {
+		return err
+	}
+	if name == active {
+		return usererr.New(
+			"Cannot delete the active global profile %q. Switch to another profile first.",
+			name,
+		)
+	}
+	path := globalProfilePath(name)
+	if !fileutil.IsDir(path) {
+		return usererr.New("Global profile %q does not exist.", name)
+	}
+	return errors.WithStack(os.RemoveAll(path))
+}
+
+func setCurrentGlobalProfile(name string) error {
+	currentPath := globalCurrentPath()
+	if err := os.MkdirAll(filepath.Dir(currentPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	// Replace the symlink atomically so that shells that are starting up
+	// never see it missing.
+	tmp := currentPath + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(globalProfilePath(name), tmp); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, currentPath))
+}
+
+func validateGlobalProfileName(name string) error {
+	if !globalProfileNameRegex.MatchString(name) || name == currentGlobalProfileLink {
+		return usererr.New(
+			"Invalid global profile name %q. Names may contain letters, digits, '.', '_' and '-'.",
+			name,
+		)
+	}
+	return nil
+}
+
+// globalProfileName returns the name of the global profile that d is, or ""
+// if d isn't a global profile.
+func (d *Devbox) globalProfileName() string {
+	rel, err := filepath.Rel(globalRoot(), d.projectDir)
+	if err != nil || strings.HasPrefix(rel, "..") || rel == "." {
+		return ""
+	}
+	name, _, _ := strings.Cut(rel, string(filepath.Separator))
+	if name == currentGlobalProfileLink {
+		if active, err := ActiveGlobalProfile(); err == nil {
+			return active
+		}
+	}
+	return name
 }
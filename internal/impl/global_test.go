@@ -0,0 +1,72 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+func TestGlobalProfiles(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	t.Setenv(envir.DevboxGlobalData, root)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+
+	path, err := GlobalDataPath()
+	req.NoError(err)
+	req.Equal(filepath.Join(root, "default"), path)
+
+	req.NoError(CreateGlobalProfile("work", io.Discard))
+	req.FileExists(filepath.Join(root, "work", "devbox.json"))
+	req.Error(CreateGlobalProfile("work", io.Discard), "profile already exists")
+	req.Error(CreateGlobalProfile("../work", io.Discard), "invalid name")
+	req.Error(CreateGlobalProfile("current", io.Discard), "reserved name")
+
+	profiles, err := ListGlobalProfiles()
+	req.NoError(err)
+	req.Equal([]string{"default", "work"}, profiles)
+
+	req.Error(SwitchGlobalProfile("personal"), "profile doesn't exist")
+	req.NoError(SwitchGlobalProfile("work"))
+	active, err := ActiveGlobalProfile()
+	req.NoError(err)
+	req.Equal("work", active)
+	path, err = GlobalDataPath()
+	req.NoError(err)
+	req.Equal(filepath.Join(root, "work"), path)
+
+	// DEVBOX_GLOBAL_PROFILE overrides the active profile.
+	t.Setenv(envir.DevboxGlobalProfile, "default")
+	active, err = ActiveGlobalProfile()
+	req.NoError(err)
+	req.Equal("default", active)
+	t.Setenv(envir.DevboxGlobalProfile, "")
+
+	req.Error(DeleteGlobalProfile("work"), "can't delete the active profile")
+	req.NoError(DeleteGlobalProfile("default"))
+	profiles, err = ListGlobalProfiles()
+	req.NoError(err)
+	req.Equal([]string{"work"}, profiles)
+}
+
+func TestGlobalProfileName(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(envir.DevboxGlobalData, root)
+
+	d := &Devbox{projectDir: filepath.Join(root, "work")}
+	require.Equal(t, "work", d.globalProfileName())
+	require.Equal(t, "work", d.cloudProfile())
+	require.True(t, d.isGlobal())
+
+	d = &Devbox{projectDir: t.TempDir()}
+	require.Equal(t, "", d.globalProfileName())
+	require.Equal(t, "default", d.cloudProfile())
+	require.False(t, d.isGlobal())
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"runtime/trace"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
@@ -24,6 +25,7 @@ import (
 	"go.jetpack.io/devbox/internal/nix"
 	"go.jetpack.io/devbox/internal/plugin"
 	"go.jetpack.io/devbox/internal/ux"
+	"go.jetpack.io/devbox/internal/ux/stepper"
 	"go.jetpack.io/devbox/internal/wrapnix"
 )
 
@@ -32,10 +34,16 @@ import (
 
 // Add adds the `pkgs` to the config (i.e. devbox.json) and nix profile for this
 // devbox project
-func (d *Devbox) Add(ctx context.Context, pkgsNames ...string) error {
+func (d *Devbox) Add(ctx context.Context, pkgsNames ...string) (err error) {
 	ctx, task := trace.NewTask(ctx, "devboxAdd")
 	defer task.End()
 
+	txn, err := d.beginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = txn.end(ctx, err) }()
+
 	// Only add packages that are not already in config. If same canonical exists,
 	// replace it.
 	pkgs := []*nix.Package{}
@@ -102,10 +110,16 @@ func (d *Devbox) Add(ctx context.Context, pkgsNames ...string) error {
 
 // Remove removes the `pkgs` from the config (i.e. devbox.json) and nix profile
 // for this devbox project
-func (d *Devbox) Remove(ctx context.Context, pkgs ...string) error {
+func (d *Devbox) Remove(ctx context.Context, pkgs ...string) (err error) {
 	ctx, task := trace.NewTask(ctx, "devboxRemove")
 	defer task.End()
 
+	txn, err := d.beginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = txn.end(ctx, err) }()
+
 	packagesToUninstall := []string{}
 	missingPkgs := []string{}
 	for _, pkg := range lo.Uniq(pkgs) {
@@ -174,9 +188,13 @@ func (d *Devbox) ensurePackagesAreInstalled(ctx context.Context, mode installMod
 		return err
 	}
 	if upToDate {
-		return nil
+		// Scripts and hooks aren't part of the Nix environment, so they
+		// may have changed without the packages changing.
+		return shellgen.WriteScriptsToFiles(d)
 	}
 
+	d.warnIfCachesUntrusted()
+
 	if err := shellgen.GenerateForPrintEnv(ctx, d); err != nil {
 		return err
 	}
@@ -200,6 +218,10 @@ func (d *Devbox) ensurePackagesAreInstalled(ctx context.Context, mode installMod
 	// Ensure we clean out packages that are no longer needed.
 	d.lockfile.Tidy()
 
+	if err := d.lockOverrides(); err != nil {
+		return err
+	}
+
 	if err = d.lockfile.Save(); err != nil {
 		return err
 	}
@@ -207,6 +229,31 @@ func (d *Devbox) ensurePackagesAreInstalled(ctx context.Context, mode installMod
 	return localLock.Update()
 }
 
+// lockOverrides records the hash of each package's override in the lockfile so
+// that a change to an override shows up as a change to the lockfile.
+func (d *Devbox) lockOverrides() error {
+	for _, pkg := range d.Packages() {
+		entry := d.lockfile.Packages[pkg]
+		if entry == nil {
+			continue
+		}
+		hash, err := d.cfg.OverrideFor(pkg).Hash()
+		if err != nil {
+			return err
+		}
+		entry.OverrideHash = hash
+	}
+	return nil
+}
+
+// isOverridden returns true if the package has an entry in the overrides
+// block. Overridden packages are built by the generated flake rather than
+// installed into the nix profile, since the profile would install the
+// unmodified package.
+func (d *Devbox) isOverridden(pkg *nix.Package) bool {
+	return d.cfg.OverrideFor(pkg.Raw) != nil
+}
+
 func (d *Devbox) profilePath() (string, error) {
 	absPath := filepath.Join(d.projectDir, nix.ProfilePath)
 
@@ -270,26 +317,104 @@ func (d *Devbox) addPackagesToProfile(ctx context.Context, mode installMode) err
 		return err
 	}
 
-	total := len(pkgs)
-	for idx, pkg := range pkgs {
-		stepNum := idx + 1
+	// Resolve the packages before fetching them concurrently, since
+	// resolving them updates the lockfile.
+	for _, input := range nix.PackageFromStrings(pkgs, d.lockfile) {
+		if _, err := input.URLForInstall(); err != nil {
+			return err
+		}
+	}
 
-		stepMsg := fmt.Sprintf("[%d/%d] %s", stepNum, total, pkg)
+	progress := stepper.NewProgress(d.writer, len(pkgs))
+	steps, err := d.realisePackages(pkgs, progress)
+	if err != nil {
+		return err
+	}
+	// If a package fails to be added, the packages after it aren't added
+	// either. Fail their steps so that they don't keep running. Ending a step
+	// that already ended does nothing.
+	added := 0
+	defer func() {
+		for _, step := range steps[added:] {
+			step.Fail("not added to the profile because an earlier package failed")
+		}
+	}()
 
+	// Add the packages to the profile one at a time, in the order of
+	// devbox.json, so that their priorities and the resulting profile are
+	// the same no matter which package finished fetching first. This is fast
+	// since the packages are already in the nix store.
+	for i, pkg := range pkgs {
 		if err := nix.ProfileInstall(&nix.ProfileInstallArgs{
-			CustomStepMessage: stepMsg,
-			Lockfile:          d.lockfile,
-			Package:           pkg,
-			ProfilePath:       profileDir,
-			Writer:            d.writer,
+			ExtraFlags:  d.nixCacheFlags(),
+			Lockfile:    d.lockfile,
+			Package:     pkg,
+			ProfilePath: profileDir,
+			Step:        steps[i],
+			Writer:      d.writer,
 		}); err != nil {
 			return err
 		}
+		added++
 	}
 
 	return nil
 }
 
+// maxConcurrentRealise is the number of packages that are fetched or built at
+// the same time.
+const maxConcurrentRealise = 4
+
+// realisePackages fetches or builds pkgs into the nix store concurrently. It
+// returns the step of each package, which are still running, so that they
+// can be ended when the package is added to the profile. The caller must end
+// all of them, even if adding a package fails. If any package fails,
+// the error lists all the packages that failed.
+func (d *Devbox) realisePackages(pkgs []string, progress *stepper.Progress) ([]*stepper.Step, error) {
+	steps := make([]*stepper.Step, len(pkgs))
+	errs := make([]error, len(pkgs))
+	sem := make(chan struct{}, maxConcurrentRealise)
+	wg := sync.WaitGroup{}
+	for i, pkg := range pkgs {
+		wg.Add(1)
+		sem <- struct{}{}
+		steps[i] = progress.Start(pkg)
+		go func(i int, pkg string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = nix.Realise(&nix.RealiseArgs{
+				ExtraFlags: d.nixCacheFlags(),
+				Lockfile:   d.lockfile,
+				Package:    pkg,
+				Step:       steps[i],
+			})
+		}(i, pkg)
+	}
+	wg.Wait()
+
+	failed := []string{}
+	for i, err := range errs {
+		if err != nil {
+			debug.Log("Error realising %s: %v", pkgs[i], err)
+			failed = append(failed, pkgs[i])
+		}
+	}
+	if len(failed) == 0 {
+		return steps, nil
+	}
+	// The packages that were fetched stay in the nix store, but none are
+	// added to the profile so that it doesn't end up partially updated.
+	for i, step := range steps {
+		if errs[i] == nil {
+			step.Success()
+		}
+	}
+	if len(failed) == 1 {
+		return nil, errs[slices.Index(pkgs, failed[0])]
+	}
+	return nil, usererr.New("Failed to install %d packages: %s", len(failed), strings.Join(failed, ", "))
+}
+
 func (d *Devbox) removePackagesFromProfile(ctx context.Context, pkgs []string) error {
 	defer trace.StartRegion(ctx, "removeNixProfilePkgs").End()
 
@@ -366,6 +491,9 @@ func (d *Devbox) pendingPackagesForInstallation(ctx context.Context) ([]string,
 		return nil, err
 	}
 	for _, input := range d.PackagesAsInputs() {
+		if d.isOverridden(input) {
+			continue
+		}
 		_, err := nix.ProfileListIndex(&nix.ProfileListIndexArgs{
 			List:       list,
 			Lockfile:   d.lockfile,
@@ -400,7 +528,9 @@ func (d *Devbox) extraPackagesInProfile(ctx context.Context) ([]*nix.NixProfileL
 	if err != nil {
 		return nil, err
 	}
-	devboxInputs := d.PackagesAsInputs()
+	devboxInputs := lo.Reject(d.PackagesAsInputs(), func(pkg *nix.Package, _ int) bool {
+		return d.isOverridden(pkg)
+	})
 
 	if len(devboxInputs) == len(profileItems) {
 		// Optimization: skip comparison if number of packages are the same. This only works
@@ -0,0 +1,103 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"path/filepath"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/debug"
+	"go.jetpack.io/devbox/internal/projects"
+	"go.jetpack.io/devbox/internal/services"
+)
+
+// ListProjects returns the projects that devbox has opened, most recently
+// used first, with whether they still exist and their services are running
+// now.
+func ListProjects() ([]*projects.Project, error) {
+	list, err := projects.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range list {
+		if !projectExists(p.Dir) {
+			p.Missing = true
+			continue
+		}
+		running := services.ProcessManagerIsRunning(p.Dir)
+		if running != p.ServicesRunning {
+			p.ServicesRunning = running
+			recordServicesRunning(p.Dir, running)
+		}
+	}
+	return list, nil
+}
+
+// PruneProjects removes the projects that no longer exist from the registry,
+// and returns them.
+func PruneProjects() ([]*projects.Project, error) {
+	return projects.Prune(func(p *projects.Project) bool {
+		return projectExists(p.Dir)
+	})
+}
+
+// FindProject returns the directory of the registered project that matches
+// name, which is either the project's directory or its last element.
+func FindProject(name string) (string, error) {
+	list, err := projects.List()
+	if err != nil {
+		return "", err
+	}
+	if abs, err := filepath.Abs(name); err == nil {
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		for _, p := range list {
+			if p.Dir == abs {
+				return p.Dir, nil
+			}
+		}
+	}
+	matches := []string{}
+	for _, p := range list {
+		if filepath.Base(p.Dir) == name && projectExists(p.Dir) {
+			matches = append(matches, p.Dir)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", usererr.New(
+			"No project named %q. Run `devbox projects ls` to list your projects.", name)
+	case 1:
+		return matches[0], nil
+	}
+	return "", usererr.New(
+		"More than one project is named %q. Use the project's path instead:\n  %s",
+		name, strings.Join(matches, "\n  "))
+}
+
+// projectExists reports whether dir still has a devbox config. A directory
+// with more than one config is still a project.
+func projectExists(dir string) bool {
+	ok, err := hasConfig(dir)
+	return ok || err != nil
+}
+
+func recordServicesRunning(projectDir string, running bool) {
+	if err := projects.SetServicesRunning(projectDir, running); err != nil {
+		debug.Log("failed to record services of %s: %v", projectDir, err)
+	}
+}
+
+func recordAllServicesStopped() {
+	list, err := projects.List()
+	if err != nil {
+		debug.Log("failed to list projects: %v", err)
+		return
+	}
+	for _, p := range list {
+		recordServicesRunning(p.Dir, false)
+	}
+}
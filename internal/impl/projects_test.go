@@ -0,0 +1,68 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+func TestProjects(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+
+	// newProject creates and opens a project, which records it.
+	newProject := func(dir string) string {
+		req.NoError(os.MkdirAll(dir, 0o755))
+		_, err := devconfig.Init(dir, io.Discard)
+		req.NoError(err)
+		_, err = Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+		req.NoError(err)
+		dir, err = filepath.EvalSymlinks(dir)
+		req.NoError(err)
+		return dir
+	}
+	api := newProject(filepath.Join(t.TempDir(), "api"))
+	web1 := newProject(filepath.Join(t.TempDir(), "web"))
+	web2 := newProject(filepath.Join(t.TempDir(), "web"))
+
+	dir, err := FindProject("api")
+	req.NoError(err)
+	req.Equal(api, dir)
+	dir, err = FindProject(web1)
+	req.NoError(err)
+	req.Equal(web1, dir)
+	_, err = FindProject("web")
+	req.ErrorContains(err, "More than one project")
+	_, err = FindProject("missing")
+	req.ErrorContains(err, "No project named")
+
+	// Deleted projects are marked as missing and pruned.
+	req.NoError(os.RemoveAll(web2))
+	list, err := ListProjects()
+	req.NoError(err)
+	req.Len(list, 3)
+	for _, p := range list {
+		req.Equal(p.Dir == web2, p.Missing, p.Dir)
+	}
+	dir, err = FindProject("web")
+	req.NoError(err)
+	req.Equal(web1, dir, "missing projects shouldn't be ambiguous")
+
+	removed, err := PruneProjects()
+	req.NoError(err)
+	req.Len(removed, 1)
+	req.Equal(web2, removed[0].Dir)
+	list, err = ListProjects()
+	req.NoError(err)
+	req.Len(list, 2)
+}
@@ -7,17 +7,41 @@ import (
 	"context"
 	"runtime/trace"
 
+	"go.jetpack.io/devbox/internal/impl/devopt"
 	"go.jetpack.io/devbox/internal/pullbox"
 )
 
-func (d *Devbox) Pull(ctx context.Context, force bool, path string) error {
+func (d *Devbox) Pull(ctx context.Context, opts devopt.PullboxOpts) error {
 	ctx, task := trace.NewTask(ctx, "devboxPull")
 	defer task.End()
-	return pullbox.New(d, path, force).Pull(ctx)
+	return pullbox.New(d, d.cloudProfile(), opts).Pull(ctx)
 }
 
 func (d *Devbox) Push(ctx context.Context, url string) error {
 	ctx, task := trace.NewTask(ctx, "devboxPush")
 	defer task.End()
-	return pullbox.New(d, url, false).Push(ctx)
+	return pullbox.New(d, d.cloudProfile(), devopt.PullboxOpts{URL: url}).Push(ctx)
+}
+
+// Sync merges the packages and env of the global config stored at opts.URL
+// with the project's, and updates both with the result. It reports whether
+// the project's config changed.
+func (d *Devbox) Sync(ctx context.Context, opts devopt.SyncOpts) (bool, error) {
+	ctx, task := trace.NewTask(ctx, "devboxSync")
+	defer task.End()
+	pb := pullbox.New(d, d.cloudProfile(), devopt.PullboxOpts{
+		URL:           opts.URL,
+		AllowUnsigned: opts.AllowUnsigned,
+	})
+	return pb.Sync(ctx, opts)
+}
+
+// cloudProfile is the devbox cloud profile that a global profile is pushed to
+// and pulled from. Each global profile has its own cloud profile of the same
+// name.
+func (d *Devbox) cloudProfile() string {
+	if name := d.globalProfileName(); name != "" {
+		return name
+	}
+	return defaultGlobalProfile
 }
@@ -429,9 +429,16 @@ func (d *Devbox) ExportHook(shellName string) (string, error) {
 		return "", err
 	}
 
+	projectDir := d.projectDir
+	if d.isGlobal() {
+		// Follow the current symlink so that the hook activates whichever
+		// global profile the user switches to.
+		projectDir = globalCurrentPath()
+	}
+
 	var buf bytes.Buffer
 	err = template.Must(template.New("hookTemplate").Parse(hookTemplate)).
-		Execute(&buf, struct{ ProjectDir string }{ProjectDir: d.projectDir})
+		Execute(&buf, struct{ ProjectDir string }{ProjectDir: projectDir})
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
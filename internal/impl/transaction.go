@@ -0,0 +1,238 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/trace"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/nix"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+// transaction.go makes operations that modify devbox.json, devbox.lock and
+// the nix profile all-or-nothing. Before the operation we snapshot all three,
+// and if the operation fails we restore them. When the operation succeeds the
+// snapshot is kept in .devbox so that `devbox rollback` can undo it.
+
+const rollbackSnapshotPath = ".devbox/rollback.json"
+
+// snapshot records the state of a project before it was modified.
+type snapshot struct {
+	// Files maps paths relative to the project dir to their contents. Files
+	// that did not exist when the snapshot was taken are omitted.
+	Files map[string]string `json:"files"`
+	// Generation is the nix profile generation, or 0 if there was no profile.
+	Generation int `json:"generation"`
+}
+
+type transaction struct {
+	box      *Devbox
+	snapshot *snapshot
+}
+
+// beginTransaction snapshots the project. Callers must call end with the
+// operation's error. Nested transactions are no-ops so only the outermost
+// operation is rolled back or recorded.
+func (d *Devbox) beginTransaction(ctx context.Context) (*transaction, error) {
+	defer trace.StartRegion(ctx, "beginTransaction").End()
+
+	if d.inTransaction {
+		return &transaction{}, nil
+	}
+	s, err := d.takeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	d.inTransaction = true
+	return &transaction{box: d, snapshot: s}, nil
+}
+
+// end restores the snapshot if err is not nil. Otherwise, if the operation
+// changed the project, it saves the snapshot so that the change can be undone
+// with `devbox rollback`. Operations that didn't change anything, such as
+// adding a package that's already in devbox.json, keep the snapshot of the
+// last real change. It returns err, so it can be used as
+// `defer func() { err = txn.end(ctx, err) }()`.
+func (t *transaction) end(ctx context.Context, err error) error {
+	if t.box == nil {
+		return err
+	}
+	defer trace.StartRegion(ctx, "endTransaction").End()
+	d := t.box
+	d.inTransaction = false
+
+	if err != nil {
+		ux.Fwarning(
+			d.writer,
+			"Restoring %s, devbox.lock and nix profile after error\n",
+			filepath.Base(d.configPath()),
+		)
+		if restoreErr := d.restoreSnapshot(t.snapshot); restoreErr != nil {
+			ux.Ferror(d.writer, "Failed to restore project: %s\n", restoreErr)
+		}
+		return err
+	}
+
+	// Failing to record the snapshot only affects `devbox rollback`, so don't
+	// fail an operation that otherwise succeeded.
+	current, snapErr := d.takeSnapshot()
+	if snapErr != nil {
+		ux.Fwarning(d.writer, "Failed to save rollback snapshot: %s\n", snapErr)
+		return nil
+	}
+	if current.equal(t.snapshot) {
+		return nil
+	}
+	if saveErr := cuecfg.WriteFile(
+		filepath.Join(d.projectDir, rollbackSnapshotPath),
+		t.snapshot,
+	); saveErr != nil {
+		ux.Fwarning(d.writer, "Failed to save rollback snapshot: %s\n", saveErr)
+	}
+	return nil
+}
+
+// Rollback undoes the last successful change to the project's packages by
+// restoring devbox.json, devbox.lock and the previous nix profile generation.
+func (d *Devbox) Rollback(ctx context.Context) error {
+	ctx, task := trace.NewTask(ctx, "devboxRollback")
+	defer task.End()
+
+	path := filepath.Join(d.projectDir, rollbackSnapshotPath)
+	s := &snapshot{}
+	if err := cuecfg.ParseFile(path, s); errors.Is(err, fs.ErrNotExist) {
+		return usererr.New("There is no change to roll back.")
+	} else if err != nil {
+		return err
+	}
+
+	if err := d.restoreSnapshot(s); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// The profile and lockfile are restored, this regenerates the flake,
+	// print-dev-env cache and wrappers to match.
+	if err := d.ensurePackagesAreInstalled(ctx, ensure); err != nil {
+		return err
+	}
+	ux.Fsuccess(d.writer, "Rolled back to the previous version of %s\n", filepath.Base(d.configPath()))
+	return nil
+}
+
+func (d *Devbox) snapshotFiles() []string {
+	return []string{filepath.Base(d.configPath()), d.lockfileRelPath()}
+}
+
+func (d *Devbox) lockfileRelPath() string {
+	rel, err := filepath.Rel(d.projectDir, d.lockfile.Path())
+	if err != nil {
+		return d.lockfile.Path()
+	}
+	return rel
+}
+
+func (d *Devbox) takeSnapshot() (*snapshot, error) {
+	s := &snapshot{Files: map[string]string{}}
+	for _, name := range d.snapshotFiles() {
+		data, err := os.ReadFile(filepath.Join(d.projectDir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		s.Files[name] = string(data)
+	}
+
+	profilePath, err := d.profilePath()
+	if err != nil {
+		return nil, err
+	}
+	s.Generation, err = nix.ProfileGeneration(profilePath)
+	return s, err
+}
+
+// equal reports whether two snapshots have the same files and generation.
+func (s *snapshot) equal(other *snapshot) bool {
+	return s.Generation == other.Generation && reflect.DeepEqual(s.Files, other.Files)
+}
+
+// restoreSnapshot restores the project to s. A project that is trusted stays
+// trusted, since devbox restores a config that the user had.
+func (d *Devbox) restoreSnapshot(s *snapshot) error {
+	status, err := d.TrustStatus()
+	if err != nil {
+		return err
+	}
+	for _, name := range d.snapshotFiles() {
+		path := filepath.Join(d.projectDir, name)
+		data, ok := s.Files[name]
+		if !ok {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	cfg, err := devconfig.Load(d.configPath())
+	if err != nil {
+		return err
+	}
+	d.cfg = cfg
+	if err := d.lockfile.Reload(); err != nil {
+		return err
+	}
+	if err := d.retrust(status); err != nil {
+		return err
+	}
+
+	profilePath, err := d.profilePath()
+	if err != nil {
+		return err
+	}
+	current, err := nix.ProfileGeneration(profilePath)
+	if err != nil || current == s.Generation {
+		return err
+	}
+	if s.Generation == 0 {
+		return removeProfile(profilePath)
+	}
+	return nix.ProfileRollback(profilePath, s.Generation)
+}
+
+// removeProfile removes a profile that didn't exist before, along with the
+// links to its generations so that they don't keep its packages from being
+// garbage collected.
+func removeProfile(profilePath string) error {
+	if err := os.Remove(profilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.WithStack(err)
+	}
+	generations, err := nix.ProfileGenerations(profilePath)
+	if err != nil {
+		return err
+	}
+	for _, generation := range generations {
+		if err := nix.DeleteProfileGeneration(profilePath, generation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/nix"
+)
+
+func TestTransactionRestoresConfigOnError(t *testing.T) {
+	req := require.New(t)
+	path := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	_, err := devconfig.Init(path, os.Stdout)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: path, Writer: os.Stdout})
+	req.NoError(err)
+	ctx := context.Background()
+
+	original, err := os.ReadFile(filepath.Join(path, devconfig.DefaultName))
+	req.NoError(err)
+
+	txn, err := d.beginTransaction(ctx)
+	req.NoError(err)
+	d.cfg.Packages = append(d.cfg.Packages, "hello@latest")
+	req.NoError(d.saveCfg())
+	err = txn.end(ctx, errors.New("install failed"))
+	req.EqualError(err, "install failed")
+
+	restored, err := os.ReadFile(filepath.Join(path, devconfig.DefaultName))
+	req.NoError(err)
+	req.Equal(string(original), string(restored))
+	req.Empty(d.cfg.Packages)
+	req.NoFileExists(filepath.Join(path, rollbackSnapshotPath))
+}
+
+func TestTransactionSavesRollbackSnapshot(t *testing.T) {
+	req := require.New(t)
+	path := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	_, err := devconfig.Init(path, os.Stdout)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: path, Writer: os.Stdout})
+	req.NoError(err)
+	ctx := context.Background()
+
+	txn, err := d.beginTransaction(ctx)
+	req.NoError(err)
+	// Nested transactions don't snapshot again.
+	nested, err := d.beginTransaction(ctx)
+	req.NoError(err)
+	req.Nil(nested.snapshot)
+	d.cfg.Packages = append(d.cfg.Packages, "hello@latest")
+	req.NoError(d.saveCfg())
+	req.NoError(nested.end(ctx, nil))
+	req.NoError(txn.end(ctx, nil))
+
+	req.FileExists(filepath.Join(path, rollbackSnapshotPath))
+}
+
+func TestTransactionKeepsSnapshotOnNoop(t *testing.T) {
+	req := require.New(t)
+	path := t.TempDir()
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	_, err := devconfig.Init(path, os.Stdout)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: path, Writer: os.Stdout})
+	req.NoError(err)
+	ctx := context.Background()
+	snapshotPath := filepath.Join(path, rollbackSnapshotPath)
+
+	// An operation that changes nothing doesn't record a snapshot.
+	txn, err := d.beginTransaction(ctx)
+	req.NoError(err)
+	req.NoError(txn.end(ctx, nil))
+	req.NoFileExists(snapshotPath)
+
+	txn, err = d.beginTransaction(ctx)
+	req.NoError(err)
+	d.cfg.Packages = append(d.cfg.Packages, "hello@latest")
+	req.NoError(d.saveCfg())
+	req.NoError(txn.end(ctx, nil))
+	saved, err := os.ReadFile(snapshotPath)
+	req.NoError(err)
+
+	// Nor does it overwrite the snapshot of the last real change.
+	txn, err = d.beginTransaction(ctx)
+	req.NoError(err)
+	req.NoError(txn.end(ctx, nil))
+	after, err := os.ReadFile(snapshotPath)
+	req.NoError(err)
+	req.Equal(string(saved), string(after))
+}
+
+func TestRemoveProfile(t *testing.T) {
+	req := require.New(t)
+	profile := filepath.Join(t.TempDir(), "default")
+	for g := 1; g <= 2; g++ {
+		req.NoError(os.Symlink("/nix/store/a-profile", nix.ProfileGenerationPath(profile, g)))
+	}
+	req.NoError(os.Symlink(filepath.Base(nix.ProfileGenerationPath(profile, 2)), profile))
+
+	req.NoError(removeProfile(profile))
+	entries, err := os.ReadDir(filepath.Dir(profile))
+	req.NoError(err)
+	req.Empty(entries)
+}
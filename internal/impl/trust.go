@@ -0,0 +1,172 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/trace"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/exp/slices"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/trust"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+// Trust allows devbox to activate the project's current config.
+func (d *Devbox) Trust(ctx context.Context) error {
+	ctx, task := trace.NewTask(ctx, "devboxTrust")
+	defer task.End()
+
+	hash, err := d.trustHash()
+	if err != nil {
+		return err
+	}
+	if err := trust.Add(d.projectDir, hash); err != nil {
+		return err
+	}
+	ux.Fsuccess(d.writer, "Trusted %s\n", d.projectDir)
+	return nil
+}
+
+// Untrust revokes trust in the project, so that devbox asks again before
+// activating it.
+func (d *Devbox) Untrust(ctx context.Context) error {
+	ctx, task := trace.NewTask(ctx, "devboxUntrust")
+	defer task.End()
+
+	removed, err := trust.Remove(d.projectDir)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		ux.Finfo(d.writer, "%s is not trusted\n", d.projectDir)
+		return nil
+	}
+	ux.Fsuccess(d.writer, "Untrusted %s\n", d.projectDir)
+	return nil
+}
+
+// TrustStatus returns whether the project's current config is trusted. The
+// global config is always trusted since only the user edits it.
+func (d *Devbox) TrustStatus() (trust.Status, error) {
+	if d.isGlobal() || trust.TrustAll() {
+		return trust.Trusted, nil
+	}
+	hash, err := d.trustHash()
+	if err != nil {
+		return trust.Untrusted, err
+	}
+	return trust.Check(d.projectDir, hash)
+}
+
+// ensureTrusted asks the user to trust the project if it isn't trusted yet,
+// and fails if they don't.
+func (d *Devbox) ensureTrusted(ctx context.Context) error {
+	defer trace.StartRegion(ctx, "ensureTrusted").End()
+
+	status, err := d.TrustStatus()
+	if err != nil || status == trust.Trusted {
+		return err
+	}
+	msg := trust.Message(status)
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return usererr.New("%s. Review it and run `devbox trust` to activate it.", msg)
+	}
+
+	fmt.Fprintf(d.writer, "%s.\n", msg)
+	d.printActivationSummary()
+	trusted := false
+	prompt := &survey.Confirm{Message: "Do you trust this project?"}
+	if err := survey.AskOne(prompt, &trusted); err != nil {
+		return errors.WithStack(err)
+	}
+	if !trusted {
+		return usererr.New("Not activating untrusted project. Run `devbox trust` to trust it.")
+	}
+	return d.Trust(ctx)
+}
+
+// printActivationSummary shows what activating the project will do, so that
+// the user can decide whether to trust it.
+func (d *Devbox) printActivationSummary() {
+	fmt.Fprintf(d.writer, "Activating %s will:\n", d.configPath())
+	keys := lo.Keys(d.cfg.Env)
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(d.writer, "  set %s=%s\n", k, d.cfg.Env[k])
+	}
+	if hook := d.cfg.InitHook(); hook != nil && len(hook.Cmds) > 0 {
+		fmt.Fprintf(d.writer, "  run the init_hook:\n")
+		for _, cmd := range hook.Cmds {
+			for _, line := range strings.Split(cmd, "\n") {
+				fmt.Fprintf(d.writer, "    %s\n", line)
+			}
+		}
+	}
+	for _, include := range d.cfg.Include {
+		fmt.Fprintf(d.writer, "  include the plugin %s\n", include)
+	}
+}
+
+// retrust updates the trusted hash after devbox itself changes the config of
+// a trusted project, such as in `devbox add`. previous is the trust status
+// before the change.
+func (d *Devbox) retrust(previous trust.Status) error {
+	if previous != trust.Trusted || d.isGlobal() {
+		return nil
+	}
+	hash, err := d.trustHash()
+	if err != nil {
+		return err
+	}
+	return trust.Add(d.projectDir, hash)
+}
+
+// trustHash returns the hash that trust is recorded for: the config file and
+// what else can change the environment and the commands it runs, which are
+// the plugins it uses and its local flakes.
+func (d *Devbox) trustHash() (string, error) {
+	deps, err := d.pluginManager.Contents(d.PackagesAsInputs(), d.cfg.Include)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range d.getLocalFlakesDirs() {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(d.projectDir, dir)
+		}
+		for _, name := range []string{"flake.nix", "flake.lock"} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", errors.WithStack(err)
+			}
+			deps = append(deps, data)
+		}
+	}
+	return trust.Hash(d.configPath(), deps...)
+}
+
+func (d *Devbox) isGlobal() bool {
+	return d.globalProfileName() != ""
+}
+
+// configPath returns the path of the project's config file, which is
+// devbox.json unless the project uses another format.
+func (d *Devbox) configPath() string {
+	path, err := devconfig.Find(d.projectDir)
+	if err != nil {
+		return filepath.Join(d.projectDir, devconfig.DefaultName)
+	}
+	return path
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/trust"
+)
+
+func TestTrust(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Projects created with devbox init are trusted.
+	_, err := devconfig.Init(dir, io.Discard)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+	req.NoError(err)
+	status, err := d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Trusted, status)
+
+	// Changes made by devbox keep the project trusted.
+	d.cfg.Packages = append(d.cfg.Packages, "hello@latest")
+	req.NoError(d.saveCfg())
+	status, err = d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Trusted, status)
+
+	// Changes made by others, such as a git pull, have to be trusted again.
+	cfgPath := filepath.Join(dir, devconfig.DefaultName)
+	req.NoError(os.WriteFile(cfgPath, []byte(`{"shell": {"init_hook": "rm -rf ~"}}`), 0o644))
+	status, err = d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Changed, status)
+
+	req.NoError(d.Trust(ctx))
+	status, err = d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Trusted, status)
+
+	req.NoError(d.Untrust(ctx))
+	status, err = d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Untrusted, status)
+
+	// An untrusted project isn't activated without a terminal to ask in.
+	req.Error(d.ensureTrusted(ctx))
+}
+
+func TestTrustKeptAfterRestore(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	_, err := devconfig.Init(dir, io.Discard)
+	req.NoError(err)
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+	req.NoError(err)
+
+	// A failed add is rolled back.
+	txn, err := d.beginTransaction(ctx)
+	req.NoError(err)
+	d.cfg.Packages = append(d.cfg.Packages, "hello@latest")
+	req.NoError(d.saveCfg())
+	req.Error(txn.end(ctx, errors.New("install failed")))
+	status, err := d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Trusted, status)
+
+	// So is a successful one, with devbox rollback.
+	txn, err = d.beginTransaction(ctx)
+	req.NoError(err)
+	d.cfg.Packages = append(d.cfg.Packages, "hello@latest")
+	req.NoError(d.saveCfg())
+	req.NoError(txn.end(ctx, nil))
+	s := &snapshot{}
+	req.NoError(cuecfg.ParseFile(filepath.Join(dir, rollbackSnapshotPath), s))
+	req.NoError(d.restoreSnapshot(s))
+	req.Empty(d.cfg.Packages)
+	status, err = d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Trusted, status)
+}
+
+func TestTrustCoversLocalFlakesAndPlugins(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	flakeDir := filepath.Join(dir, "my-flake")
+	req.NoError(os.Mkdir(flakeDir, 0o755))
+	req.NoError(os.WriteFile(filepath.Join(flakeDir, "flake.nix"), []byte("{ }"), 0o644))
+	req.NoError(os.WriteFile(
+		filepath.Join(dir, devconfig.DefaultName),
+		[]byte(`{"packages": ["path:./my-flake#hello"]}`),
+		0o644,
+	))
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+	req.NoError(err)
+	req.NoError(d.Trust(ctx))
+
+	// Changing the local flake has to be trusted again.
+	req.NoError(os.WriteFile(filepath.Join(flakeDir, "flake.nix"), []byte("{ evil = true; }"), 0o644))
+	status, err := d.TrustStatus()
+	req.NoError(err)
+	req.Equal(trust.Changed, status)
+
+	// So does a plugin that a package brings in.
+	req.NoError(d.Trust(ctx))
+	withoutPlugin, err := d.trustHash()
+	req.NoError(err)
+	d.cfg.Packages = append(d.cfg.Packages, "postgresql@latest")
+	withPlugin, err := d.trustHash()
+	req.NoError(err)
+	req.NotEqual(withoutPlugin, withPlugin)
+}
+
+func TestTrustAll(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	t.Setenv(envir.DevboxTrustAll, "1")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, devconfig.DefaultName), []byte(`{}`), 0o644))
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: io.Discard})
+	require.NoError(t, err)
+
+	status, err := d.TrustStatus()
+	require.NoError(t, err)
+	require.Equal(t, trust.Trusted, status)
+}
@@ -6,8 +6,11 @@ package impl
 import (
 	"context"
 	"fmt"
+	"runtime/trace"
+	"text/tabwriter"
 
 	"go.jetpack.io/devbox/internal/devpkg"
+	"go.jetpack.io/devbox/internal/impl/devopt"
 	"go.jetpack.io/devbox/internal/nix"
 	"go.jetpack.io/devbox/internal/searcher"
 	"go.jetpack.io/devbox/internal/shellgen"
@@ -15,11 +18,24 @@ import (
 	"go.jetpack.io/devbox/internal/wrapnix"
 )
 
-func (d *Devbox) Update(ctx context.Context, pkgs ...string) error {
-	inputs, err := d.inputsToUpdate(pkgs...)
+func (d *Devbox) Update(ctx context.Context, opts devopt.UpdateOpts) (err error) {
+	ctx, task := trace.NewTask(ctx, "devboxUpdate")
+	defer task.End()
+
+	inputs, err := d.inputsToUpdate(opts.Pkgs...)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		return d.printUpdatePlan(inputs)
+	}
+
+	txn, err := d.beginTransaction(ctx)
 	if err != nil {
 		return err
 	}
+	defer func() { err = txn.end(ctx, err) }()
 
 	pendingPackagesToUpdate := []*nix.Package{}
 	for _, pkg := range inputs {
@@ -61,7 +77,7 @@ func (d *Devbox) Update(ctx context.Context, pkgs ...string) error {
 		return err
 	}
 
-	return nix.FlakeUpdate(shellgen.FlakePath(d))
+	return nix.FlakeUpdate(shellgen.FlakePath(d), d.nixCacheFlags()...)
 }
 
 func (d *Devbox) inputsToUpdate(pkgs ...string) ([]*nix.Package, error) {
@@ -120,7 +136,7 @@ func (d *Devbox) attemptToUpgradeFlake(pkg *nix.Package) error {
 		pkg.Raw,
 	)
 
-	err = nix.ProfileUpgrade(profilePath, pkg, d.lockfile)
+	err = nix.ProfileUpgrade(profilePath, pkg, d.lockfile, d.nixCacheFlags()...)
 	if err != nil {
 		ux.Ferror(
 			d.writer,
@@ -132,3 +148,73 @@ func (d *Devbox) attemptToUpgradeFlake(pkg *nix.Package) error {
 
 	return nil
 }
+
+// printUpdatePlan prints the version each package would be updated to without
+// modifying the config, lockfile or profile.
+func (d *Devbox) printUpdatePlan(inputs []*nix.Package) error {
+	w := tabwriter.NewWriter(d.writer, 3, 2, 8, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "PACKAGE\tCURRENT\tCANDIDATE")
+	for _, pkg := range inputs {
+		if pkg.IsLegacy() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", pkg.Raw, "-", pkg.LegacyToVersioned())
+			continue
+		}
+		if _, _, isVersioned := devpkg.ParseVersionedPackage(pkg.Raw); !isVersioned {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", pkg.Raw, "-", "(flake, upgraded with nix profile upgrade)")
+			continue
+		}
+		current := "-"
+		if existing := d.lockfile.Packages[pkg.Raw]; existing != nil {
+			current = existing.Version
+		}
+		candidate, err := searcher.Client().Resolve(pkg.Raw)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", pkg.Raw, current, candidate.Version)
+	}
+	return w.Flush()
+}
+
+// Outdated lists the versioned packages that have a newer version available,
+// either within their version constraint (wanted) or at all (latest).
+func (d *Devbox) Outdated(ctx context.Context) error {
+	defer trace.StartRegion(ctx, "devboxOutdated").End()
+
+	type row struct{ name, current, wanted, latest string }
+	rows := []row{}
+	for _, pkg := range d.PackagesAsInputs() {
+		name, _, isVersioned := devpkg.ParseVersionedPackage(pkg.Raw)
+		if !isVersioned {
+			continue
+		}
+		existing := d.lockfile.Packages[pkg.Raw]
+		if existing == nil {
+			continue
+		}
+		wanted, err := searcher.Client().Resolve(pkg.Raw)
+		if err != nil {
+			return err
+		}
+		latest, err := searcher.Client().Resolve(name + "@latest")
+		if err != nil {
+			return err
+		}
+		if existing.Version == wanted.Version && existing.Version == latest.Version {
+			continue
+		}
+		rows = append(rows, row{pkg.Raw, existing.Version, wanted.Version, latest.Version})
+	}
+
+	if len(rows) == 0 {
+		ux.Fsuccess(d.writer, "All packages are up-to-date\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(d.writer, 3, 2, 8, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "PACKAGE\tCURRENT\tWANTED\tLATEST")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.name, r.current, r.wanted, r.latest)
+	}
+	return w.Flush()
+}
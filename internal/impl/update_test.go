@@ -0,0 +1,124 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/searcher"
+)
+
+// newUpdateTestProject returns a project with constrained packages that are
+// locked to older versions than the search API at DEVBOX_SEARCH_HOST has.
+func newUpdateTestProject(t *testing.T) (*Devbox, *bytes.Buffer) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	versions := map[string][]string{
+		"nodejs": {"20.5.0", "18.19.0", "18.17.1"},
+		"go":     {"1.21.3", "1.21.0", "1.20.10"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("q")
+		result := &searcher.SearchResult{}
+		packages := searcher.NixPackageInfoList{}
+		for _, v := range versions[name] {
+			if want := r.URL.Query().Get("v"); want != "" && want != "latest" && want != v {
+				continue
+			}
+			packages = append(packages, &searcher.NixPackageInfo{
+				AttributePath: name,
+				NixpkgCommit:  "commit",
+				Version:       v,
+			})
+		}
+		if len(packages) > 0 {
+			result.Results = []searcher.Result{{Name: name, Packages: packages}}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(result))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv(envir.DevboxSearchHost, server.URL)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "devbox.json"), `{"packages": ["nodejs@^18", "go@~1.21"]}`)
+	writeTestFile(t, filepath.Join(dir, "devbox.lock"), `{
+  "lockfile_version": "1",
+  "packages": {
+    "go@~1.21": {"resolved": "github:NixOS/nixpkgs/commit#go", "version": "1.21.3"},
+    "nodejs@^18": {"resolved": "github:NixOS/nixpkgs/commit#nodejs", "version": "18.17.1"}
+  }
+}`)
+	out := &bytes.Buffer{}
+	d, err := Open(&devopt.Opts{Dir: dir, Writer: out})
+	require.NoError(t, err)
+	return d, out
+}
+
+func TestUpdateDryRun(t *testing.T) {
+	d, out := newUpdateTestProject(t)
+	config, err := os.ReadFile(filepath.Join(d.projectDir, "devbox.json"))
+	require.NoError(t, err)
+	lockfile, err := os.ReadFile(filepath.Join(d.projectDir, "devbox.lock"))
+	require.NoError(t, err)
+
+	require.NoError(t, d.Update(context.Background(), devopt.UpdateOpts{DryRun: true}))
+
+	tests := []struct {
+		pkg, current, candidate string
+	}{
+		{"nodejs@^18", "18.17.1", "18.19.0"},
+		{"go@~1.21", "1.21.3", "1.21.3"},
+	}
+	lines := outputLines(out.String())
+	require.Len(t, lines, len(tests)+1)
+	for i, test := range tests {
+		require.Equal(t, []string{test.pkg, test.current, test.candidate}, lines[i+1])
+	}
+
+	// A dry run changes neither the config nor the lockfile.
+	requireFileContent(t, filepath.Join(d.projectDir, "devbox.json"), config)
+	requireFileContent(t, filepath.Join(d.projectDir, "devbox.lock"), lockfile)
+}
+
+func TestOutdated(t *testing.T) {
+	d, out := newUpdateTestProject(t)
+
+	require.NoError(t, d.Outdated(context.Background()))
+
+	// go is already at the latest version, so it isn't listed.
+	require.Equal(t, [][]string{
+		{"PACKAGE", "CURRENT", "WANTED", "LATEST"},
+		{"nodejs@^18", "18.17.1", "18.19.0", "20.5.0"},
+	}, outputLines(out.String()))
+}
+
+// outputLines splits tabular output into lines of fields.
+func outputLines(s string) [][]string {
+	lines := [][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		lines = append(lines, strings.Fields(line))
+	}
+	return lines
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func requireFileContent(t *testing.T, path string, want []byte) {
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got))
+}
@@ -0,0 +1,24 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package impl
+
+import (
+	"go.jetpack.io/devbox/internal/devconfig"
+)
+
+// ValidateConfig checks the config file of the project in dir, or in its
+// parent directories if dir is empty, without opening the project. It
+// returns the path of the config that it checked.
+func ValidateConfig(dir string) (string, error) {
+	projectDir, err := findProjectDir(dir)
+	if err != nil {
+		return "", err
+	}
+	cfgPath, err := devconfig.Find(projectDir)
+	if err != nil {
+		return "", err
+	}
+	_, err = devconfig.Load(cfgPath)
+	return cfgPath, err
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package importer
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/exp/slices"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/initrec/analyzer"
+	"go.jetpack.io/devbox/internal/initrec/recommenders"
+	"go.jetpack.io/devbox/internal/initrec/recommenders/toolversions"
+)
+
+// miseAliases maps the mise short names that differ from asdf plugin names.
+var miseAliases = map[string]string{
+	"go":   "golang",
+	"node": "nodejs",
+}
+
+func importToolVersions(path string, r *Result) error {
+	tools := analyzer.ToolVersions(filepath.Dir(path))
+	for _, tool := range sortedKeys(tools) {
+		addTool(path, tool, tools[tool], r)
+	}
+	return nil
+}
+
+// importMise imports the [tools] and [env] tables of a mise config file.
+func importMise(path string, r *Result) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	cfg := struct {
+		Tools map[string]any `toml:"tools"`
+		Env   map[string]any `toml:"env"`
+	}{}
+	if err := toml.Unmarshal(content, &cfg); err != nil {
+		return usererr.WithUserMessage(err, "Failed to parse %s", path)
+	}
+
+	for _, tool := range sortedKeys(cfg.Tools) {
+		version, ok := miseVersion(cfg.Tools[tool])
+		if !ok {
+			r.untranslated(path, "unsupported version for tool %q", tool)
+			continue
+		}
+		if alias, ok := miseAliases[tool]; ok {
+			tool = alias
+		}
+		addTool(path, tool, analyzer.NumericVersion(version), r)
+	}
+
+	for _, name := range sortedKeys(cfg.Env) {
+		value, ok := cfg.Env[name].(string)
+		if !ok {
+			// Directives like _.file and _.path.
+			r.untranslated(path, "env %q is not a string", name)
+			continue
+		}
+		r.Env[name] = value
+	}
+	return nil
+}
+
+// miseVersion returns the version of a tool, which mise allows to be a
+// string, a list of strings where the first is the default, or a table with a
+// version key.
+func miseVersion(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []any:
+		if len(v) > 0 {
+			return miseVersion(v[0])
+		}
+	case map[string]any:
+		if version, ok := v["version"]; ok {
+			return miseVersion(version)
+		}
+	}
+	return "", false
+}
+
+func addTool(path, tool, version string, r *Result) {
+	name, ok := toolversions.PackageName(tool)
+	if !ok {
+		r.untranslated(path, "no devbox package for tool %q", tool)
+		return
+	}
+	r.addPackage(recommenders.Versioned(name, version))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := lo.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package importer
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/initrec/recommenders/compose"
+)
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string `yaml:"image"`
+	Command     any    `yaml:"command"`
+	Environment any    `yaml:"environment"`
+	WorkingDir  string `yaml:"working_dir"`
+	DependsOn   any    `yaml:"depends_on"`
+	Volumes     []any  `yaml:"volumes"`
+}
+
+// importCompose maps services with a known image, like postgres or redis, to
+// the devbox package whose plugin provides the service. Services that run a
+// command, usually the project's own apps, become process-compose processes.
+func importCompose(path string, r *Result) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	file := composeFile{}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return usererr.WithUserMessage(err, "Failed to parse %s", path)
+	}
+
+	// deps has the services that each new process depends on. They're
+	// resolved once every service has been converted.
+	deps := map[string][]string{}
+	for _, name := range sortedKeys(file.Services) {
+		svc := file.Services[name]
+		if pkg := compose.ImagePackage(svc.Image); pkg != "" {
+			r.addPackage(pkg)
+			if svc.Environment != nil {
+				r.untranslated(path, "environment of service %q, configure the %s plugin instead", name, pkg)
+			}
+			if len(svc.Volumes) > 0 {
+				r.untranslated(path, "volumes of service %q", name)
+			}
+			continue
+		}
+
+		command := composeCommand(svc.Command)
+		if command == "" {
+			if svc.Image != "" {
+				r.untranslated(path, "service %q: no devbox package for image %q", name, svc.Image)
+			} else {
+				r.untranslated(path, "service %q: build without a command", name)
+			}
+			continue
+		}
+		r.Processes[name] = &Process{
+			Command:     command,
+			WorkingDir:  svc.WorkingDir,
+			Environment: composeEnvironment(svc.Environment),
+		}
+		deps[name] = composeDependsOn(svc.DependsOn)
+		if len(svc.Volumes) > 0 {
+			r.untranslated(path, "volumes of service %q", name)
+		}
+	}
+
+	for _, name := range sortedKeys(deps) {
+		proc := r.Processes[name]
+		for _, dep := range deps[name] {
+			// A service that became a devbox package runs as its plugin's
+			// process, which may have a different name.
+			if svc, ok := file.Services[dep]; ok && compose.ImagePackage(svc.Image) != "" {
+				if process := compose.ImageProcess(svc.Image); process != "" {
+					dep = process
+				} else {
+					r.untranslated(path, "service %q: dependency on %q, which has no devbox service", name, dep)
+					continue
+				}
+			} else if _, ok := r.Processes[dep]; !ok {
+				r.untranslated(path, "service %q: dependency on %q, which isn't a process", name, dep)
+				continue
+			}
+			if proc.DependsOn == nil {
+				proc.DependsOn = map[string]dependsOn{}
+			}
+			proc.DependsOn[dep] = dependsOn{Condition: "process_started"}
+		}
+	}
+	return nil
+}
+
+// composeCommand returns the command of a service, which may be a string or a
+// list of arguments.
+func composeCommand(cmd any) string {
+	switch cmd := cmd.(type) {
+	case string:
+		return cmd
+	case []any:
+		args := make([]string, 0, len(cmd))
+		for _, arg := range cmd {
+			s, _ := arg.(string)
+			args = append(args, shellQuote(s))
+		}
+		return strings.Join(args, " ")
+	}
+	return ""
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?&;|<>()[]{}!#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// composeEnvironment converts a map or list of variables to a list of
+// KEY=VALUE strings.
+func composeEnvironment(env any) []string {
+	switch env := env.(type) {
+	case map[string]any:
+		vars := []string{}
+		for _, k := range sortedKeys(env) {
+			v := env[k]
+			if v == nil {
+				v = ""
+			}
+			vars = append(vars, k+"="+yamlScalar(v))
+		}
+		return vars
+	case []any:
+		vars := []string{}
+		for _, v := range env {
+			vars = append(vars, yamlScalar(v))
+		}
+		return vars
+	}
+	return nil
+}
+
+// composeDependsOn returns service names from a list or a map of conditions.
+func composeDependsOn(deps any) []string {
+	switch deps := deps.(type) {
+	case map[string]any:
+		return sortedKeys(deps)
+	case []any:
+		names := []string{}
+		for _, dep := range deps {
+			if s, ok := dep.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func yamlScalar(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	out, _ := yaml.Marshal(v)
+	return strings.TrimSpace(string(out))
+}
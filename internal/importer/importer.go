@@ -0,0 +1,208 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+// Package importer translates the configuration of other development
+// environment tools, like asdf, mise, nix and docker compose, into devbox.json.
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/devpkg"
+	"go.jetpack.io/devbox/internal/fileutil"
+)
+
+// Result is the devbox configuration translated from one or more sources.
+type Result struct {
+	// Packages are versioned devbox packages, e.g. nodejs@18.
+	Packages []string
+	Env      map[string]string
+	// InitHook is a script to append to the shell's init_hook.
+	InitHook string
+	// Processes are process-compose processes for services that don't have a
+	// devbox package.
+	Processes map[string]*Process
+	// Untranslated describes the parts of the sources that couldn't be
+	// translated.
+	Untranslated []string
+}
+
+// Process is a process in process-compose.yaml.
+type Process struct {
+	Command     string               `yaml:"command"`
+	WorkingDir  string               `yaml:"working_dir,omitempty"`
+	Environment []string             `yaml:"environment,omitempty"`
+	DependsOn   map[string]dependsOn `yaml:"depends_on,omitempty"`
+}
+
+type dependsOn struct {
+	Condition string `yaml:"condition"`
+}
+
+type importFunc func(path string, r *Result) error
+
+type importer struct {
+	name string
+	fn   importFunc
+}
+
+// importers maps the file names that can be imported to their importer. The
+// order is the order in which files are imported from a directory.
+var importers = []importer{
+	{".tool-versions", importToolVersions},
+	{".mise.toml", importMise},
+	{"mise.toml", importMise},
+	{".rtx.toml", importMise},
+	{"flake.nix", importFlake},
+	{"shell.nix", importShellNix},
+	{"compose.yaml", importCompose},
+	{"compose.yml", importCompose},
+	{"docker-compose.yaml", importCompose},
+	{"docker-compose.yml", importCompose},
+}
+
+// Import translates the file at path. If path is a directory, every file in it
+// that can be imported is.
+func Import(path string) (*Result, error) {
+	r := &Result{Env: map[string]string{}, Processes: map[string]*Process{}}
+	if !fileutil.IsDir(path) {
+		fn := importerFor(filepath.Base(path))
+		if fn == nil {
+			return nil, usererr.New(
+				"Don't know how to import %s. Supported files are %s.",
+				path, supportedFiles(),
+			)
+		}
+		if !fileutil.Exists(path) {
+			return nil, usererr.New("%s does not exist", path)
+		}
+		return r, fn(path, r)
+	}
+
+	found := false
+	for _, imp := range importers {
+		file := filepath.Join(path, imp.name)
+		if !fileutil.Exists(file) {
+			continue
+		}
+		found = true
+		if err := imp.fn(file, r); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, usererr.New(
+			"Found nothing to import in %s. Supported files are %s.",
+			path, supportedFiles(),
+		)
+	}
+	return r, nil
+}
+
+func importerFor(name string) importFunc {
+	for _, imp := range importers {
+		if imp.name == name {
+			return imp.fn
+		}
+	}
+	return nil
+}
+
+func supportedFiles() string {
+	names := lo.Map(importers, func(imp importer, _ int) string { return imp.name })
+	return strings.Join(names, ", ")
+}
+
+func (r *Result) addPackage(pkg string) {
+	name, _, _ := devpkg.ParseVersionedPackage(pkg)
+	if hasPackage(r.Packages, name) {
+		return
+	}
+	r.Packages = append(r.Packages, pkg)
+}
+
+func (r *Result) untranslated(source, format string, a ...any) {
+	r.Untranslated = append(
+		r.Untranslated,
+		fmt.Sprintf("%s: %s", filepath.Base(source), fmt.Sprintf(format, a...)),
+	)
+}
+
+func hasPackage(pkgs []string, name string) bool {
+	for _, pkg := range pkgs {
+		if n, _, _ := devpkg.ParseVersionedPackage(pkg); n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply merges the result into the devbox config in projectDir, creating it if
+// it doesn't exist. Existing packages and env variables are kept. Processes
+// are written to process-compose.yaml unless one already exists.
+func Apply(projectDir string, r *Result) error {
+	cfg := devconfig.DefaultConfig()
+	cfgPath, err := devconfig.Find(projectDir)
+	if err == nil {
+		cfg, err = devconfig.Load(cfgPath)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, pkg := range r.Packages {
+		name, _, _ := devpkg.ParseVersionedPackage(pkg)
+		if !hasPackage(cfg.Packages, name) {
+			cfg.Packages = append(cfg.Packages, pkg)
+		}
+	}
+	for k, v := range r.Env {
+		if cfg.Env == nil {
+			cfg.Env = map[string]string{}
+		}
+		if existing, ok := cfg.Env[k]; ok && existing != v {
+			r.Untranslated = append(r.Untranslated, fmt.Sprintf(
+				"env %s is already set in %s, keeping %q", k, filepath.Base(cfgPath), existing,
+			))
+			continue
+		}
+		cfg.Env[k] = v
+	}
+	if r.InitHook != "" {
+		cfg.AppendInitHook(r.InitHook)
+	}
+	if err := cfg.SaveTo(projectDir); err != nil {
+		return err
+	}
+
+	if len(r.Processes) == 0 {
+		return nil
+	}
+	pcPath := filepath.Join(projectDir, "process-compose.yaml")
+	if fileutil.Exists(pcPath) {
+		for name := range r.Processes {
+			r.Untranslated = append(r.Untranslated, fmt.Sprintf(
+				"service %s was not added because process-compose.yaml already exists", name,
+			))
+		}
+		return nil
+	}
+	data, err := yaml.Marshal(map[string]any{
+		"version":   "0.5",
+		"processes": r.Processes,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(pcPath, data, 0644))
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+)
+
+func importFile(t *testing.T, name, content string) *Result {
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	r, err := Import(path)
+	require.NoError(t, err)
+	return r
+}
+
+func TestImportToolVersions(t *testing.T) {
+	r := importFile(t, ".tool-versions", "nodejs 18.17.1\ngolang 1.21.0 # comment\ndirenv 2.32.0\n")
+	require.Equal(t, []string{"go@1.21.0", "nodejs@18.17.1"}, r.Packages)
+	require.Equal(t, []string{`.tool-versions: no devbox package for tool "direnv"`}, r.Untranslated)
+}
+
+func TestImportMise(t *testing.T) {
+	r := importFile(t, ".mise.toml", `
+[tools]
+node = "20"
+python = ["3.11", "3.10"]
+go = { version = "1.21" }
+"npm:prettier" = "latest"
+
+[env]
+NODE_ENV = "development"
+_.file = ".env"
+`)
+	require.Equal(t, []string{"go@1.21", "nodejs@20", "python@3.11"}, r.Packages)
+	require.Equal(t, map[string]string{"NODE_ENV": "development"}, r.Env)
+	require.Equal(t, []string{
+		`.mise.toml: no devbox package for tool "npm:prettier"`,
+		`.mise.toml: env "_" is not a string`,
+	}, r.Untranslated)
+}
+
+func TestImportShellNix(t *testing.T) {
+	r := importFile(t, "shell.nix", `
+{ pkgs ? import <nixpkgs> {} }:
+
+pkgs.mkShell {
+  # tools
+  buildInputs = with pkgs; [
+    go
+    nodejs_18
+    python311Packages.pip
+    (python3.withPackages (ps: [ ps.requests ]))
+  ];
+  nativeBuildInputs = [ pkgs.pkg-config ] ++ lib.optionals stdenv.isDarwin [ pkgs.libiconv ];
+  GREETING = "hello; world";
+  LD_LIBRARY_PATH = "${pkgs.zlib}/lib";
+  shellHook = ''
+    echo "$GREETING"
+    export PATH=''${PWD}/bin:$PATH
+  '';
+}
+`)
+	require.Equal(t, []string{"go@latest", "nodejs@18", "python311Packages.pip", "pkg-config@latest"}, r.Packages)
+	require.Equal(t, map[string]string{"GREETING": "hello; world"}, r.Env)
+	require.Equal(t, "echo \"$GREETING\"\nexport PATH=${PWD}/bin:$PATH\n", r.InitHook)
+	require.Equal(t, []string{
+		"shell.nix: python311Packages.pip was added without a version, pin one in devbox.json",
+		"shell.nix: (python3.withPackages (ps: [ ps.requests ]))",
+		"shell.nix: lib.optionals stdenv.isDarwin [ pkgs.libiconv ]",
+		`shell.nix: LD_LIBRARY_PATH = "${pkgs.zlib}/lib"`,
+	}, r.Untranslated)
+}
+
+func TestNixPackage(t *testing.T) {
+	for attr, want := range map[string]string{
+		"go":            "go@latest",
+		"nodejs_18":     "nodejs@18",
+		"go_1_21":       "go@1.21",
+		"postgresql_15": "postgresql@15",
+		"python311":     "python@3.11",
+		"python3":       "python@3",
+		"jdk17":         "jdk@17",
+		"bzip2":         "bzip2@latest",
+	} {
+		pkg, ok := nixPackage(attr)
+		require.True(t, ok, attr)
+		require.Equal(t, want, pkg, attr)
+	}
+	_, ok := nixPackage("nodePackages.prettier")
+	require.False(t, ok)
+}
+
+func TestImportFlake(t *testing.T) {
+	r := importFile(t, "flake.nix", `
+{
+  inputs.nixpkgs.url = "github:NixOS/nixpkgs/nixos-23.05";
+  outputs = { self, nixpkgs }: let
+    pkgs = nixpkgs.legacyPackages.x86_64-linux;
+  in {
+    devShells.x86_64-linux.default = pkgs.mkShell {
+      packages = [ pkgs.ripgrep pkgs.jq ];
+    };
+  };
+}
+`)
+	require.Equal(t, []string{"ripgrep@latest", "jq@latest"}, r.Packages)
+	require.Len(t, r.Untranslated, 1)
+}
+
+func TestImportCompose(t *testing.T) {
+	r := importFile(t, "docker-compose.yml", `
+services:
+  db:
+    image: postgres:15
+    environment:
+      POSTGRES_PASSWORD: secret
+  cache:
+    image: redis:7-alpine
+  queue:
+    image: rabbitmq:3
+  web:
+    build: .
+    command: ["npm", "run", "dev", "--", "--host", "0.0.0.0 "]
+    working_dir: ./web
+    environment:
+      - PORT=3000
+    depends_on:
+      - db
+      - queue
+      - search
+      - worker
+  worker:
+    command: ./worker
+    depends_on:
+      cache:
+        condition: service_healthy
+  search:
+    image: elasticsearch:8
+`)
+	require.Equal(t, []string{"redis@7", "postgresql@15", "rabbitmq-server@3"}, r.Packages)
+	require.Equal(t, map[string]*Process{
+		"web": {
+			Command:     "npm run dev -- --host '0.0.0.0 '",
+			WorkingDir:  "./web",
+			Environment: []string{"PORT=3000"},
+			DependsOn: map[string]dependsOn{
+				"postgresql": {Condition: "process_started"},
+				"worker":     {Condition: "process_started"},
+			},
+		},
+		"worker": {
+			Command:   "./worker",
+			DependsOn: map[string]dependsOn{"redis": {Condition: "process_started"}},
+		},
+	}, r.Processes)
+	require.Equal(t, []string{
+		`docker-compose.yml: environment of service "db", configure the postgresql@15 plugin instead`,
+		`docker-compose.yml: service "search": no devbox package for image "elasticsearch:8"`,
+		`docker-compose.yml: service "web": dependency on "queue", which has no devbox service`,
+		`docker-compose.yml: service "web": dependency on "search", which isn't a process`,
+	}, r.Untranslated)
+}
+
+func TestImportUnsupported(t *testing.T) {
+	_, err := Import(filepath.Join(t.TempDir(), "Makefile"))
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	cfg := devconfig.DefaultConfig()
+	cfg.Packages = []string{"go@1.20"}
+	cfg.Env = map[string]string{"FOO": "bar"}
+	req.NoError(cfg.SaveTo(dir))
+
+	r := &Result{
+		Packages: []string{"go@1.21", "nodejs@18"},
+		Env:      map[string]string{"FOO": "baz", "BAR": "qux"},
+		InitHook: "echo hi\n",
+		Processes: map[string]*Process{
+			"web": {Command: "npm start"},
+		},
+	}
+	req.NoError(Apply(dir, r))
+
+	cfg, err := devconfig.Load(filepath.Join(dir, devconfig.DefaultName))
+	req.NoError(err)
+	req.Equal([]string{"go@1.20", "nodejs@18"}, cfg.Packages)
+	req.Equal(map[string]string{"FOO": "bar", "BAR": "qux"}, cfg.Env)
+	req.Contains(cfg.InitHook().Cmds, "echo hi")
+	req.Len(r.Untranslated, 1)
+
+	data, err := os.ReadFile(filepath.Join(dir, "process-compose.yaml"))
+	req.NoError(err)
+	pc := struct {
+		Processes map[string]*Process `yaml:"processes"`
+	}{}
+	req.NoError(yaml.Unmarshal(data, &pc))
+	req.Equal("npm start", pc.Processes["web"].Command)
+}
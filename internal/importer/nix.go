@@ -0,0 +1,381 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package importer
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/initrec/recommenders"
+)
+
+// nix.go imports the mkShell call of a shell.nix or a flake's devShell. It
+// doesn't evaluate nix. Instead it does a best-effort parse of the common
+// forms:
+//
+//	pkgs.mkShell {
+//	  buildInputs = with pkgs; [ go nodejs_18 ];
+//	  FOO = "bar";
+//	  shellHook = ''
+//	    echo hello
+//	  '';
+//	}
+//
+// Anything more dynamic is reported as untranslated.
+
+var mkShellRegex = regexp.MustCompile(`mkShell(?:NoCC)?\s*(?:rec\s*)?\{`)
+
+var attrPathRegex = regexp.MustCompile(`^[A-Za-z_][\w'-]*(\.[A-Za-z_][\w'-]*)*$`)
+
+var withRegex = regexp.MustCompile(`^with\s+[\w.]+\s*;`)
+
+var trailingWithRegex = regexp.MustCompile(`=\s*(with\s+[\w.]+\s*;\s*)*with\s+[\w.]+$`)
+
+// packageAttrs are the mkShell attributes that list packages.
+var packageAttrs = map[string]bool{
+	"buildInputs":           true,
+	"nativeBuildInputs":     true,
+	"packages":              true,
+	"propagatedBuildInputs": true,
+}
+
+func importShellNix(path string, r *Result) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	importMkShell(path, stripComments(string(content)), r)
+	return nil
+}
+
+func importFlake(path string, r *Result) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	src := stripComments(string(content))
+	if !strings.Contains(src, "devShell") {
+		r.untranslated(path, "no devShell found")
+		return nil
+	}
+	if len(mkShellRegex.FindAllStringIndex(src, -1)) > 1 {
+		r.untranslated(path, "only the first mkShell was imported")
+	}
+	r.untranslated(path, "flake inputs were not imported, devbox pins package versions in devbox.lock")
+	importMkShell(path, src, r)
+	return nil
+}
+
+func importMkShell(path, src string, r *Result) {
+	loc := mkShellRegex.FindStringIndex(src)
+	if loc == nil {
+		r.untranslated(path, "no mkShell call found")
+		return
+	}
+	open := loc[1] - 1
+	end := matchingBracket(src, open)
+	if end < 0 {
+		r.untranslated(path, "could not parse mkShell")
+		return
+	}
+
+	for _, stmt := range attrStatements(src[open+1 : end]) {
+		name, value, found := strings.Cut(stmt, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch {
+		case !found:
+			r.untranslated(path, "%s", stmt)
+		case packageAttrs[name]:
+			importPackageList(path, value, r)
+		case name == "name" || name == "pname":
+		case name == "shellHook":
+			if hook, ok := nixString(value); ok {
+				r.InitHook += hook + "\n"
+			} else {
+				r.untranslated(path, "shellHook is not a plain string")
+			}
+		default:
+			if s, ok := nixString(value); ok {
+				r.Env[name] = s
+			} else {
+				r.untranslated(path, "%s = %s", name, value)
+			}
+		}
+	}
+}
+
+// attrStatements splits the body of an attribute set into `name = value`
+// statements.
+func attrStatements(body string) []string {
+	stmts := []string{}
+	pending := ""
+	for _, stmt := range splitTopLevel(body, ';') {
+		stmt = strings.TrimSpace(pending + stmt)
+		pending = ""
+		if trailingWithRegex.MatchString(stmt) {
+			// The ; ends a `with` expression, not the statement.
+			pending = stmt + ";"
+			continue
+		}
+		if stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// importPackageList imports lists like `with pkgs; [ a b ] ++ [ pkgs.c ]`.
+func importPackageList(path, value string, r *Result) {
+	for withRegex.MatchString(value) {
+		value = strings.TrimSpace(withRegex.ReplaceAllString(value, ""))
+	}
+	for _, part := range splitTopLevelString(value, "++") {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "[") || matchingBracket(part, 0) != len(part)-1 {
+			r.untranslated(path, "%s", part)
+			continue
+		}
+		for _, elem := range listElements(part[1 : len(part)-1]) {
+			attr := strings.TrimPrefix(elem, "pkgs.")
+			if !attrPathRegex.MatchString(attr) {
+				r.untranslated(path, "%s", elem)
+				continue
+			}
+			if pkg, ok := nixPackage(attr); ok {
+				r.addPackage(pkg)
+			} else {
+				r.addPackage(attr)
+				r.untranslated(path, "%s was added without a version, pin one in devbox.json", attr)
+			}
+		}
+	}
+}
+
+// versionedAttrRegex matches attributes with the major version in their name,
+// such as nodejs_18, go_1_21 or python311.
+var versionedAttrRegex = regexp.MustCompile(`^(python|php|ruby|jdk|gcc|llvm|[A-Za-z][\w-]*?_)(\d+(?:_\d+)*)$`)
+
+// compactVersionAttrs are the attributes whose version in the name has no
+// separators, such as python311 for python 3.11.
+var compactVersionAttrs = map[string]bool{"python": true, "php": true, "ruby": true}
+
+// nixPackage returns the versioned devbox package for a nixpkgs attribute,
+// e.g. nodejs@18 for nodejs_18 and go@latest for go. Nested attributes, such
+// as python311Packages.pip, can't be looked up by version.
+func nixPackage(attr string) (string, bool) {
+	if strings.Contains(attr, ".") {
+		return "", false
+	}
+	matches := versionedAttrRegex.FindStringSubmatch(attr)
+	if matches == nil {
+		return recommenders.Versioned(attr, ""), true
+	}
+	name, version := strings.TrimSuffix(matches[1], "_"), matches[2]
+	if compactVersionAttrs[name] && len(version) > 1 && !strings.Contains(version, "_") {
+		version = version[:1] + "." + version[1:]
+	}
+	return recommenders.Versioned(name, strings.ReplaceAll(version, "_", ".")), true
+}
+
+// nixString returns the value of a string literal without interpolation.
+func nixString(value string) (string, bool) {
+	if hasInterpolation(value) {
+		return "", false
+	}
+	if len(value) >= 2 && value[0] == '"' && skipString(value, 0) == len(value) {
+		s := value[1 : len(value)-1]
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n", `\t`, "\t", `\$`, "$").Replace(s), true
+	}
+	if len(value) >= 4 && strings.HasPrefix(value, "''") && skipString(value, 0) == len(value) {
+		s := value[2 : len(value)-2]
+		s = strings.NewReplacer("'''", "''", "''$", "$", `''\n`, "\n", `''\t`, "\t").Replace(s)
+		return unindent(s), true
+	}
+	return "", false
+}
+
+// hasInterpolation reports whether a string literal contains an unescaped
+// ${...}.
+func hasInterpolation(value string) bool {
+	for i := strings.Index(value, "${"); i >= 0; {
+		escaped := (i >= 1 && value[i-1] == '\\') ||
+			(i > 2 && value[i-2:i] == "''" && strings.HasPrefix(value, "''"))
+		if !escaped {
+			return true
+		}
+		next := strings.Index(value[i+2:], "${")
+		if next < 0 {
+			return false
+		}
+		i += 2 + next
+	}
+	return false
+}
+
+// unindent removes the indentation common to all non-empty lines, as nix does
+// for indented strings.
+func unindent(s string) string {
+	s = strings.TrimPrefix(s, "\n")
+	lines := strings.Split(s, "\n")
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " ")
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " \n")
+}
+
+// skipString returns the index after the string literal that starts at i, or
+// i if there isn't one.
+func skipString(s string, i int) int {
+	switch {
+	case s[i] == '"':
+		for j := i + 1; j < len(s); j++ {
+			if s[j] == '\\' {
+				j++
+			} else if s[j] == '"' {
+				return j + 1
+			}
+		}
+		return len(s)
+	case strings.HasPrefix(s[i:], "''"):
+		for j := i + 2; j < len(s)-1; j++ {
+			if s[j] != '\'' || s[j+1] != '\'' {
+				continue
+			}
+			// ''' , ''$ and ''\ are escapes, not the end of the string.
+			if j+2 < len(s) && strings.ContainsRune(`'$\`, rune(s[j+2])) {
+				j += 2
+				continue
+			}
+			return j + 2
+		}
+		return len(s)
+	}
+	return i
+}
+
+// stripComments removes # and /* */ comments outside of strings.
+func stripComments(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if j := skipString(s, i); j != i {
+			b.WriteString(s[i:j])
+			i = j
+			continue
+		}
+		switch {
+		case s[i] == '#':
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 4
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// walk calls fn with the index and bracket depth of every byte of s that is
+// outside a string, and with the index of the start of every string.
+func walk(s string, fn func(i, depth int) bool) {
+	depth := 0
+	for i := 0; i < len(s); {
+		if j := skipString(s, i); j != i {
+			if !fn(i, depth) {
+				return
+			}
+			i = j
+			continue
+		}
+		if strings.IndexByte(")]}", s[i]) >= 0 {
+			depth--
+		}
+		if !fn(i, depth) {
+			return
+		}
+		if strings.IndexByte("([{", s[i]) >= 0 {
+			depth++
+		}
+		i++
+	}
+}
+
+// matchingBracket returns the index of the bracket that closes the one at
+// open, or -1.
+func matchingBracket(s string, open int) int {
+	end := -1
+	walk(s[open:], func(i, depth int) bool {
+		if i > 0 && depth == 0 {
+			end = open + i
+			return false
+		}
+		return true
+	})
+	return end
+}
+
+func splitTopLevel(s string, sep byte) []string {
+	return splitTopLevelString(s, string(sep))
+}
+
+// splitTopLevelString splits s on sep where sep isn't nested in brackets or
+// strings.
+func splitTopLevelString(s, sep string) []string {
+	parts := []string{}
+	start := 0
+	walk(s, func(i, depth int) bool {
+		if depth == 0 && i >= start && strings.HasPrefix(s[i:], sep) {
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+		}
+		return true
+	})
+	return append(parts, s[start:])
+}
+
+// listElements splits the contents of a list on whitespace that isn't nested
+// in brackets or strings.
+func listElements(s string) []string {
+	elems := []string{}
+	start := -1
+	walk(s, func(i, depth int) bool {
+		isSpace := strings.IndexByte(" \t\r\n", s[i]) >= 0
+		if depth == 0 && isSpace && start >= 0 {
+			elems = append(elems, s[start:i])
+			start = -1
+		} else if !isSpace && start < 0 {
+			start = i
+		}
+		return true
+	})
+	if start >= 0 {
+		elems = append(elems, strings.TrimSpace(s[start:]))
+	}
+	return elems
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var numericVersionRegex = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// NumericVersion extracts the first numeric version in s, dropping prefixes
+// and operators. For example "v18.17.0" => "18.17.0", "ruby-3.2.2" => "3.2.2"
+// and ">=3.9,<4" => "3.9". It returns "" if s doesn't contain a version, as in
+// "lts/hydrogen".
+func NumericVersion(s string) string {
+	return numericVersionRegex.FindString(s)
+}
+
+// ReadVersionFile returns the version in a single-version file such as .nvmrc,
+// .python-version or .ruby-version. It returns "" if the file doesn't exist or
+// doesn't declare a numeric version.
+func ReadVersionFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return NumericVersion(line)
+	}
+	return ""
+}
+
+// ToolVersions parses the asdf .tool-versions file in dir and returns a map
+// of tool name to its (first) declared version. It returns nil if the file
+// doesn't exist.
+func ToolVersions(dir string) map[string]string {
+	f, err := os.Open(filepath.Join(dir, ".tool-versions"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	tools := map[string]string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		tools[fields[0]] = NumericVersion(fields[1])
+	}
+	return tools
+}
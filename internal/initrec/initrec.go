@@ -4,9 +4,19 @@
 package initrec
 
 import (
+	"strings"
+
+	"github.com/pkg/errors"
 	"github.com/samber/lo"
+	"golang.org/x/exp/slices"
+
+	"go.jetpack.io/devbox/internal/debug"
+	"go.jetpack.io/devbox/internal/devpkg"
+	"go.jetpack.io/devbox/internal/lock"
+	"go.jetpack.io/devbox/internal/nix"
 
 	"go.jetpack.io/devbox/internal/initrec/recommenders"
+	"go.jetpack.io/devbox/internal/initrec/recommenders/compose"
 	"go.jetpack.io/devbox/internal/initrec/recommenders/dotnet"
 	"go.jetpack.io/devbox/internal/initrec/recommenders/golang"
 	"go.jetpack.io/devbox/internal/initrec/recommenders/haskell"
@@ -16,6 +26,7 @@ import (
 	"go.jetpack.io/devbox/internal/initrec/recommenders/python"
 	"go.jetpack.io/devbox/internal/initrec/recommenders/ruby"
 	"go.jetpack.io/devbox/internal/initrec/recommenders/rust"
+	"go.jetpack.io/devbox/internal/initrec/recommenders/toolversions"
 	"go.jetpack.io/devbox/internal/initrec/recommenders/zig"
 )
 
@@ -32,19 +43,69 @@ func getRecommenders(srcDir string) []recommenders.Recommender {
 		&ruby.Recommender{SrcDir: srcDir},
 		&rust.Recommender{SrcDir: srcDir},
 		&zig.Recommender{SrcDir: srcDir},
+		&toolversions.Recommender{SrcDir: srcDir},
+		&compose.Recommender{SrcDir: srcDir},
 	}
 }
 
+// Get returns the packages recommended for the project in srcDir, sorted by
+// name. If more than one recommender suggests the same package, a declared
+// version takes precedence over latest.
 func Get(srcDir string) ([]string, error) {
-	// Using a map of string-bool instead of array of strings to prevent duplication
-	result := map[string]bool{}
+	// Map package name to versioned package to prevent duplication
+	result := map[string]string{}
 	for _, sg := range getRecommenders(srcDir) {
-		if sg.IsRelevant() {
-			for _, pkg := range sg.Packages() {
-				result[pkg] = true
+		if !sg.IsRelevant() {
+			continue
+		}
+		for _, pkg := range sg.Packages() {
+			name, version, _ := devpkg.ParseVersionedPackage(pkg)
+			if _, ok := result[name]; ok && (version == "" || version == "latest") {
+				continue
 			}
+			result[name] = pkg
 		}
 	}
 	// TODO: check for already installed packages
-	return lo.Keys(result), nil
+	pkgs := lo.Values(result)
+	slices.Sort(pkgs)
+	return pkgs, nil
+}
+
+// Resolver looks up a versioned package. searcher.Client() implements it.
+type Resolver interface {
+	Resolve(pkg string) (*lock.Package, error)
+}
+
+// Resolve checks the recommended packages against the search API. Versions
+// that aren't found are made less specific until one is, e.g. nodejs@18.17.1
+// becomes nodejs@18.17 and then nodejs@18, falling back to latest. If the
+// search API can't be reached the packages are returned as is.
+func Resolve(pkgs []string, resolver Resolver) []string {
+	resolved := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		name, version, ok := devpkg.ParseVersionedPackage(pkg)
+		if !ok || version == "latest" {
+			resolved = append(resolved, pkg)
+			continue
+		}
+		resolved = append(resolved, resolveVersion(name, version, resolver))
+	}
+	return resolved
+}
+
+func resolveVersion(name, version string, resolver Resolver) string {
+	parts := strings.Split(version, ".")
+	for i := len(parts); i > 0; i-- {
+		pkg := name + "@" + strings.Join(parts[:i], ".")
+		_, err := resolver.Resolve(pkg)
+		if err == nil {
+			return pkg
+		}
+		if !errors.Is(err, nix.ErrPackageNotFound) {
+			debug.Log("initrec: failed to resolve %s: %v", pkg, err)
+			return name + "@" + version
+		}
+	}
+	return name + "@latest"
 }
@@ -0,0 +1,146 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package initrec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/lock"
+	"go.jetpack.io/devbox/internal/nix"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestGet(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name:     "go directive",
+			files:    map[string]string{"go.mod": "module example.com/m\n\ngo 1.21\n"},
+			expected: []string{"go@1.21"},
+		},
+		{
+			name: "nvmrc takes precedence over engines",
+			files: map[string]string{
+				"package.json": `{"engines": {"node": ">=16"}}`,
+				".nvmrc":       "v18.17.1\n",
+			},
+			expected: []string{"nodejs@18.17.1"},
+		},
+		{
+			name:     "engines",
+			files:    map[string]string{"package.json": `{"engines": {"node": "^20.5"}}`},
+			expected: []string{"nodejs@20.5"},
+		},
+		{
+			name:     "node without version",
+			files:    map[string]string{"package.json": `{}`, ".nvmrc": "lts/hydrogen"},
+			expected: []string{"nodejs@latest"},
+		},
+		{
+			name: "python version file",
+			files: map[string]string{
+				"requirements.txt": "flask\n",
+				".python-version":  "3.11.4\n",
+			},
+			expected: []string{"python@3.11.4"},
+		},
+		{
+			name: "pyproject requires-python",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nrequires-python = \">=3.10\"\n",
+			},
+			expected: []string{"poetry@latest", "python@3.10"},
+		},
+		{
+			name:     "pinned rust toolchain",
+			files:    map[string]string{"rust-toolchain.toml": "[toolchain]\nchannel = \"1.72.0\"\n"},
+			expected: []string{"cargo@1.72.0", "rustc@1.72.0"},
+		},
+		{
+			name:     "rust channel",
+			files:    map[string]string{"Cargo.toml": "", "rust-toolchain": "nightly\n"},
+			expected: []string{"rustup"},
+		},
+		{
+			name:     "ruby version file",
+			files:    map[string]string{".ruby-version": "ruby-3.2.2\n"},
+			expected: []string{"gcc", "gnumake", "ruby@3.2.2"},
+		},
+		{
+			name: "tool-versions",
+			files: map[string]string{
+				"go.mod":         "module example.com/m\n",
+				".tool-versions": "golang 1.20.5\nterraform 1.5.0 # infra\nunknown-tool 1.0\n",
+			},
+			expected: []string{"go@1.20.5", "terraform@1.5.0"},
+		},
+		{
+			name: "compose services",
+			files: map[string]string{
+				"docker-compose.yml": `
+services:
+  db:
+    image: postgres:15-alpine
+  cache:
+    image: docker.io/library/redis
+  app:
+    build: .
+`,
+			},
+			expected: []string{"postgresql@15", "redis@latest"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			pkgs, err := Get(dir)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, pkgs)
+		})
+	}
+}
+
+type fakeResolver map[string]bool
+
+func (f fakeResolver) Resolve(pkg string) (*lock.Package, error) {
+	if f[pkg] {
+		return &lock.Package{}, nil
+	}
+	return nil, nix.ErrPackageNotFound
+}
+
+func TestResolve(t *testing.T) {
+	resolver := fakeResolver{
+		"nodejs@18":     true,
+		"go@1.21.0":     true,
+		"postgresql@15": true,
+		"python@latest": true,
+		"ruby@3.2":      true,
+	}
+	pkgs := Resolve(
+		[]string{"nodejs@18.17.1", "go@1.21.0", "postgresql@15", "python@3.99.99", "ruby@3.2.2", "gcc"},
+		resolver,
+	)
+	require.Equal(
+		t,
+		[]string{"nodejs@18", "go@1.21.0", "postgresql@15", "python@latest", "ruby@3.2", "gcc"},
+		pkgs,
+	)
+}
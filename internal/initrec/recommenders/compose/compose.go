@@ -0,0 +1,121 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package compose
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/initrec/analyzer"
+	"go.jetpack.io/devbox/internal/initrec/recommenders"
+)
+
+var composeFiles = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+type service struct {
+	// pkg is the devbox package that provides the service. Most of these have
+	// a built-in plugin that adds it to `devbox services`.
+	pkg string
+	// versioned is true if the image tag is the package version.
+	versioned bool
+	// process is the name of the process that the package's plugin runs, or
+	// "" if the package has no plugin service.
+	process string
+}
+
+// imageServices maps docker image names to devbox packages.
+var imageServices = map[string]service{
+	"caddy":      {pkg: "caddy", versioned: true, process: "caddy"},
+	"httpd":      {pkg: "apacheHttpd", versioned: true, process: "apache"},
+	"mariadb":    {pkg: "mariadb", versioned: true, process: "mariadb"},
+	"memcached":  {pkg: "memcached", versioned: true},
+	"mongo":      {pkg: "mongodb", versioned: true},
+	"mysql":      {pkg: "mysql80", process: "mysql"},
+	"nginx":      {pkg: "nginx", versioned: true, process: "nginx"},
+	"postgres":   {pkg: "postgresql", versioned: true, process: "postgresql"},
+	"postgresql": {pkg: "postgresql", versioned: true, process: "postgresql"},
+	"rabbitmq":   {pkg: "rabbitmq-server", versioned: true},
+	"redis":      {pkg: "redis", versioned: true, process: "redis"},
+}
+
+// Recommender recommends packages for the services, such as databases, that a
+// project runs with docker compose.
+type Recommender struct {
+	SrcDir string
+}
+
+// implements interface recommenders.Recommender (compile-time check)
+var _ recommenders.Recommender = (*Recommender)(nil)
+
+func (r *Recommender) IsRelevant() bool {
+	return r.composeFile() != ""
+}
+
+func (r *Recommender) Packages() []string {
+	content, err := os.ReadFile(r.composeFile())
+	if err != nil {
+		return nil
+	}
+	file := struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}{}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil
+	}
+
+	pkgs := []string{}
+	for _, svc := range file.Services {
+		if pkg := ImagePackage(svc.Image); pkg != "" {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+func (r *Recommender) composeFile() string {
+	for _, name := range composeFiles {
+		path := filepath.Join(r.SrcDir, name)
+		if fileutil.Exists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// ImagePackage returns the package for an image such as postgres:15-alpine or
+// docker.io/library/redis:7, or "" if it isn't a known service.
+func ImagePackage(image string) string {
+	name, tag, _ := strings.Cut(path.Base(image), ":")
+	svc, ok := imageServices[name]
+	if !ok {
+		return ""
+	}
+	if !svc.versioned {
+		return svc.pkg
+	}
+	// Only use the major version. Images are usually tagged with it and the
+	// minor versions in nixpkgs rarely match the ones on docker hub.
+	major, _, _ := strings.Cut(analyzer.NumericVersion(tag), ".")
+	return recommenders.Versioned(svc.pkg, major)
+}
+
+// ImageProcess returns the name of the process that runs the service for an
+// image in `devbox services`, or "" if the image's package has no plugin
+// service.
+func ImageProcess(image string) string {
+	name, _, _ := strings.Cut(path.Base(image), ":")
+	return imageServices[name].process
+}
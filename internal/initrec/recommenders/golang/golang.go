@@ -13,15 +13,6 @@ import (
 	"go.jetpack.io/devbox/internal/initrec/recommenders"
 )
 
-var versionMap = map[string]string{
-	// Map go versions to the corresponding nixpkgs:
-	"1.19": "go_1_19",
-	"1.18": "go",
-	"1.17": "go_1_17",
-}
-
-const defaultPkg = "go_1_19" // Default to "latest" for cases where we can't determine a version.
-
 type Recommender struct {
 	SrcDir string
 }
@@ -34,21 +25,8 @@ func (r *Recommender) IsRelevant() bool {
 }
 
 func (r *Recommender) Packages() []string {
-	goPkg := getGoPackage(r.SrcDir)
-
-	return []string{goPkg}
-}
-
-func getGoPackage(srcDir string) string {
-	goModPath := filepath.Join(srcDir, "go.mod")
-	goVersion := parseGoVersion(goModPath)
-	v, ok := versionMap[goVersion]
-	if ok {
-		return v
-	}
-	// Should we be throwing an error instead, if we don't have a nix package
-	// for the specified version of go?
-	return defaultPkg
+	goModPath := filepath.Join(r.SrcDir, "go.mod")
+	return []string{recommenders.Versioned("go", parseGoVersion(goModPath))}
 }
 
 func parseGoVersion(gomodPath string) string {
@@ -5,5 +5,17 @@ package recommenders
 
 type Recommender interface {
 	IsRelevant() bool
+	// Packages returns the packages to recommend. Packages are versioned, e.g.
+	// nodejs@18 or go@latest, when the project declares the version of a
+	// tool. Other packages, such as rustup or gcc for ruby, are unversioned.
 	Packages() []string
 }
+
+// Versioned returns name@version, or name@latest if the project doesn't
+// declare a version.
+func Versioned(name, version string) string {
+	if version == "" {
+		version = "latest"
+	}
+	return name + "@" + version
+}
@@ -41,35 +41,15 @@ type nodeProject struct {
 	} `json:"engines,omitempty"`
 }
 
-var versionMap = map[string]string{
-	// Map node versions to the corresponding nixpkgs:
-	"10": "nodejs-10_x",
-	"12": "nodejs-12_x",
-	"16": "nodejs-16_x",
-	"18": "nodejs-18_x",
-}
-var defaultNodeJSPkg = "nodejs"
-
-func (r *Recommender) nodePackage(project *nodeProject) string {
-	v := r.nodeVersion(project)
-	if v != nil {
-		pkg, ok := versionMap[v.Major()]
-		if ok {
-			return pkg
-		}
-	}
-
-	return defaultNodeJSPkg
-}
-
-func (r *Recommender) nodeVersion(project *nodeProject) *analyzer.Version {
-	if r != nil {
-		if v, err := analyzer.NewVersion(project.Engines.Node); err == nil {
+// nodeVersion returns the node version declared in .nvmrc, .node-version or
+// the engines field of package.json, in that order.
+func (r *Recommender) nodeVersion(project *nodeProject) string {
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		if v := analyzer.ReadVersionFile(filepath.Join(r.SrcDir, name)); v != "" {
 			return v
 		}
 	}
-
-	return nil
+	return analyzer.NumericVersion(project.Engines.Node)
 }
 
 func (r *Recommender) packageManager() string {
@@ -80,11 +60,10 @@ func (r *Recommender) packageManager() string {
 }
 
 func (r *Recommender) packages(pkgManager string, project *nodeProject) []string {
-	nodeJSPkg := r.nodePackage(project)
-	pkgs := []string{nodeJSPkg}
+	pkgs := []string{recommenders.Versioned("nodejs", r.nodeVersion(project))}
 
 	if pkgManager == "yarn" {
-		return append(pkgs, "yarn")
+		return append(pkgs, recommenders.Versioned("yarn", ""))
 	}
 	return pkgs
 }
@@ -18,10 +18,11 @@ type RecommenderPip struct {
 var _ recommenders.Recommender = (*RecommenderPip)(nil)
 
 func (r *RecommenderPip) IsRelevant() bool {
-	return fileutil.Exists(filepath.Join(r.SrcDir, "requirements.txt"))
+	return fileutil.Exists(filepath.Join(r.SrcDir, "requirements.txt")) ||
+		fileutil.Exists(filepath.Join(r.SrcDir, ".python-version"))
 }
 func (r *RecommenderPip) Packages() []string {
 	return []string{
-		"python3",
+		recommenders.Versioned("python", pythonVersion(r.SrcDir)),
 	}
 }
@@ -4,14 +4,13 @@
 package python
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/pelletier/go-toml/v2"
-	"go.jetpack.io/devbox/internal/initrec/analyzer"
 
 	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/initrec/analyzer"
 	"go.jetpack.io/devbox/internal/initrec/recommenders"
 )
 
@@ -27,31 +26,32 @@ func (r *RecommenderPoetry) IsRelevant() bool {
 		fileutil.Exists(filepath.Join(r.SrcDir, "pyproject.toml"))
 }
 func (r *RecommenderPoetry) Packages() []string {
-	version := r.PythonVersion()
-	pythonPkg := fmt.Sprintf("python%s", version.MajorMinorConcatenated())
-
 	return []string{
-		pythonPkg,
-		"poetry",
+		recommenders.Versioned("python", pythonVersion(r.SrcDir)),
+		recommenders.Versioned("poetry", ""),
 	}
 }
 
-// TODO: This can be generalized to all python planners
-func (r *RecommenderPoetry) PythonVersion() *analyzer.Version {
-	defaultVersion, _ := analyzer.NewVersion("3.10.6")
-	project := r.pyProject()
-
+// pythonVersion returns the python version declared in .python-version or
+// pyproject.toml, in that order. It returns "" if neither declares one.
+func pythonVersion(srcDir string) string {
+	if v := analyzer.ReadVersionFile(filepath.Join(srcDir, ".python-version")); v != "" {
+		return v
+	}
+	project := readPyProject(srcDir)
 	if project == nil {
-		return defaultVersion
+		return ""
 	}
-
-	if v, err := analyzer.NewVersion(project.Tool.Poetry.Dependencies.Python); err == nil {
+	if v := analyzer.NumericVersion(project.Tool.Poetry.Dependencies.Python); v != "" {
 		return v
 	}
-	return defaultVersion
+	return analyzer.NumericVersion(project.Project.RequiresPython)
 }
 
 type pyProject struct {
+	Project struct {
+		RequiresPython string `toml:"requires-python"`
+	} `toml:"project"`
 	Tool struct {
 		Poetry struct {
 			Name         string `toml:"name"`
@@ -67,8 +67,8 @@ type pyProject struct {
 	} `toml:"tool"`
 }
 
-func (r *RecommenderPoetry) pyProject() *pyProject {
-	pyProjectPath := filepath.Join(r.SrcDir, "pyproject.toml")
+func readPyProject(srcDir string) *pyProject {
+	pyProjectPath := filepath.Join(srcDir, "pyproject.toml")
 	content, err := os.ReadFile(pyProjectPath)
 	if err != nil {
 		return nil
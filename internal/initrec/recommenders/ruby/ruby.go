@@ -9,9 +9,8 @@ import (
 	"path/filepath"
 	"regexp"
 
-	"golang.org/x/mod/semver"
-
 	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/initrec/analyzer"
 	"go.jetpack.io/devbox/internal/initrec/recommenders"
 )
 
@@ -22,29 +21,20 @@ type Recommender struct {
 // implements interface recommenders.Recommender (compile-time check)
 var _ recommenders.Recommender = (*Recommender)(nil)
 
-var nixPackages = map[string]string{
-	"3.1": "ruby_3_1",
-	"3.0": "ruby_3_0",
-	"2.7": "ruby",
-}
-
-const defaultPkg = "ruby_3_1"
-
 var rubyVersionRegex = regexp.MustCompile(`ruby\s+"(<|>|<=|>=|~>|=|)\s*([\d|\\.]+)"`)
 
 func (r *Recommender) IsRelevant() bool {
-	return fileutil.Exists(filepath.Join(r.SrcDir, "Gemfile"))
+	return fileutil.Exists(filepath.Join(r.SrcDir, "Gemfile")) ||
+		fileutil.Exists(filepath.Join(r.SrcDir, ".ruby-version"))
 }
 
 func (r *Recommender) Packages() []string {
-	gemfile := filepath.Join(r.SrcDir, "Gemfile")
-	v := parseRubyVersion(gemfile)
-	pkg, ok := nixPackages[semver.MajorMinor(v)]
-	if !ok {
-		pkg = defaultPkg
+	v := analyzer.ReadVersionFile(filepath.Join(r.SrcDir, ".ruby-version"))
+	if v == "" {
+		v = parseRubyVersion(filepath.Join(r.SrcDir, "Gemfile"))
 	}
 	return []string{
-		pkg,
+		recommenders.Versioned("ruby", v),
 		"gcc",     // for rails
 		"gnumake", // for rails
 	}
@@ -55,6 +45,7 @@ func parseRubyVersion(gemfile string) string {
 	if err != nil {
 		return ""
 	}
+	defer f.Close()
 	s := bufio.NewScanner(f)
 	for s.Scan() {
 		line := s.Text()
@@ -4,9 +4,13 @@
 package rust
 
 import (
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/pelletier/go-toml/v2"
+
 	"go.jetpack.io/devbox/internal/fileutil"
 	"go.jetpack.io/devbox/internal/initrec/recommenders"
 )
@@ -22,13 +26,66 @@ type Recommender struct {
 var _ recommenders.Recommender = (*Recommender)(nil)
 
 func (r *Recommender) IsRelevant() bool {
-	return cargoTomlPath(r.SrcDir) != ""
+	return cargoTomlPath(r.SrcDir) != "" || toolchainPath(r.SrcDir) != ""
 }
 
+// Packages recommends rustc and cargo when the toolchain file pins a numeric
+// version, e.g. channel = "1.72.0". For other channels, like stable or
+// nightly-2023-08-01, rustup is recommended because it reads the toolchain
+// file itself.
 func (r *Recommender) Packages() []string {
+	if v := toolchainVersion(r.SrcDir); v != "" {
+		return []string{
+			recommenders.Versioned("rustc", v),
+			recommenders.Versioned("cargo", v),
+		}
+	}
 	return []string{"rustup"}
 }
 
+var numericChannelRegex = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+type toolchainFile struct {
+	Toolchain struct {
+		Channel string `toml:"channel"`
+	} `toml:"toolchain"`
+}
+
+// toolchainPath returns the path of rust-toolchain.toml or the legacy
+// rust-toolchain file, or "" if there is neither.
+func toolchainPath(srcDir string) string {
+	for _, name := range []string{"rust-toolchain.toml", "rust-toolchain"} {
+		path := filepath.Join(srcDir, name)
+		if fileutil.Exists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func toolchainVersion(srcDir string) string {
+	path := toolchainPath(srcDir)
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	channel := ""
+	tf := toolchainFile{}
+	if err := toml.Unmarshal(content, &tf); err == nil {
+		channel = tf.Toolchain.Channel
+	} else {
+		// The legacy rust-toolchain file contains only the channel name.
+		channel = strings.TrimSpace(string(content))
+	}
+	if !numericChannelRegex.MatchString(channel) {
+		return ""
+	}
+	return channel
+}
+
 // Tries to find Cargo.toml or cargo.toml. Returns the path with srcDir if found
 // and empty-string if not found.
 //
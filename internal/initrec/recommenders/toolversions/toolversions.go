@@ -0,0 +1,64 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package toolversions
+
+import (
+	"path/filepath"
+
+	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/initrec/analyzer"
+	"go.jetpack.io/devbox/internal/initrec/recommenders"
+)
+
+// asdfPackages maps asdf plugin names to devbox packages. Tools that aren't in
+// the map are not recommended because asdf and nixpkgs names often differ.
+var asdfPackages = map[string]string{
+	"bun":       "bun",
+	"deno":      "deno",
+	"elixir":    "elixir",
+	"erlang":    "erlang",
+	"golang":    "go",
+	"helm":      "kubernetes-helm",
+	"java":      "jdk",
+	"jq":        "jq",
+	"kubectl":   "kubectl",
+	"nodejs":    "nodejs",
+	"php":       "php",
+	"pnpm":      "pnpm",
+	"poetry":    "poetry",
+	"python":    "python",
+	"ruby":      "ruby",
+	"rust":      "rustc",
+	"terraform": "terraform",
+	"yarn":      "yarn",
+	"zig":       "zig",
+}
+
+// Recommender recommends the tools pinned in an asdf .tool-versions file.
+type Recommender struct {
+	SrcDir string
+}
+
+// implements interface recommenders.Recommender (compile-time check)
+var _ recommenders.Recommender = (*Recommender)(nil)
+
+func (r *Recommender) IsRelevant() bool {
+	return fileutil.Exists(filepath.Join(r.SrcDir, ".tool-versions"))
+}
+
+func (r *Recommender) Packages() []string {
+	pkgs := []string{}
+	for tool, version := range analyzer.ToolVersions(r.SrcDir) {
+		if name, ok := PackageName(tool); ok {
+			pkgs = append(pkgs, recommenders.Versioned(name, version))
+		}
+	}
+	return pkgs
+}
+
+// PackageName returns the devbox package for an asdf tool.
+func PackageName(tool string) (string, bool) {
+	name, ok := asdfPackages[tool]
+	return name, ok
+}
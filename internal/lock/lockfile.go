@@ -32,7 +32,10 @@ type File struct {
 }
 
 type Package struct {
-	LastModified  string `json:"last_modified,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// OverrideHash is the hash of the package's entry in the devbox.json
+	// overrides block, if any.
+	OverrideHash  string `json:"override_hash,omitempty"`
 	PluginVersion string `json:"plugin_version,omitempty"`
 	Resolved      string `json:"resolved,omitempty"`
 	Version       string `json:"version,omitempty"`
@@ -153,6 +156,22 @@ func (l *File) Save() error {
 	return cuecfg.WriteFile(lockFilePath(l.devboxProject), l)
 }
 
+// Path returns the path of the lockfile on disk.
+func (l *File) Path() string {
+	return lockFilePath(l.devboxProject)
+}
+
+// Reload discards in-memory changes and re-reads the lockfile from disk.
+func (l *File) Reload() error {
+	l.LockFileVersion = lockFileVersion
+	l.Packages = map[string]*Package{}
+	err := cuecfg.ParseFile(lockFilePath(l.devboxProject), l)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
 func (l *File) LegacyNixpkgsPath(pkg string) string {
 	return fmt.Sprintf(
 		"github:NixOS/nixpkgs/%s#%s",
@@ -0,0 +1,106 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"encoding/json"
+	"os/exec"
+	"os/user"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"go.jetpack.io/devbox/internal/redact"
+)
+
+// CacheFlags returns the flags that add extra binary caches and the public
+// keys used to verify them. They are passed to every nix command that may
+// build or substitute packages.
+func CacheFlags(substituters, trustedPublicKeys []string) []string {
+	flags := []string{}
+	if len(substituters) > 0 {
+		flags = append(flags, "--extra-substituters", strings.Join(substituters, " "))
+	}
+	if len(trustedPublicKeys) > 0 {
+		flags = append(flags, "--extra-trusted-public-keys", strings.Join(trustedPublicKeys, " "))
+	}
+	return flags
+}
+
+type nixConfigSetting struct {
+	Value any `json:"value"`
+}
+
+// UntrustedCaches returns the substituters and keys that the nix daemon will
+// ignore. The daemon only accepts extra substituters and keys from trusted
+// users, unless they are already listed in its own configuration.
+func UntrustedCaches(substituters, trustedPublicKeys []string) ([]string, []string, error) {
+	if len(substituters) == 0 && len(trustedPublicKeys) == 0 {
+		return nil, nil, nil
+	}
+
+	cmd := exec.Command("nix", "show-config", "--json")
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, nil, redact.Errorf("error running \"nix show-config\": %w", err)
+	}
+	config := map[string]nixConfigSetting{}
+	if err := json.Unmarshal(out, &config); err != nil {
+		return nil, nil, redact.Errorf("error parsing \"nix show-config\" output: %w", err)
+	}
+
+	if isTrustedUser(configList(config, "trusted-users")) {
+		return nil, nil, nil
+	}
+
+	knownSubstituters := append(
+		configList(config, "substituters"),
+		configList(config, "trusted-substituters")...,
+	)
+	untrustedSubstituters := lo.Filter(substituters, func(s string, _ int) bool {
+		return !lo.Contains(knownSubstituters, s)
+	})
+	knownKeys := configList(config, "trusted-public-keys")
+	untrustedKeys := lo.Filter(trustedPublicKeys, func(k string, _ int) bool {
+		return !lo.Contains(knownKeys, k)
+	})
+	return untrustedSubstituters, untrustedKeys, nil
+}
+
+func configList(config map[string]nixConfigSetting, name string) []string {
+	switch v := config[name].Value.(type) {
+	case []any:
+		return lo.FilterMap(v, func(item any, _ int) (string, bool) {
+			s, ok := item.(string)
+			return s, ok
+		})
+	case string:
+		return strings.Fields(v)
+	}
+	return nil
+}
+
+// isTrustedUser checks the current user against nix's trusted-users setting,
+// which may contain user names, `@group` names and `*`.
+func isTrustedUser(trustedUsers []string) bool {
+	u, err := user.Current()
+	if err != nil {
+		return false
+	}
+	if lo.Contains(trustedUsers, "*") || lo.Contains(trustedUsers, u.Username) {
+		return true
+	}
+	groupIDs, err := u.GroupIds()
+	if err != nil {
+		return false
+	}
+	for _, gid := range groupIDs {
+		group, err := user.LookupGroupId(gid)
+		if err == nil && lo.Contains(trustedUsers, "@"+group.Name) {
+			return true
+		}
+	}
+	return false
+}
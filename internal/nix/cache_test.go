@@ -0,0 +1,37 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFlags(t *testing.T) {
+	require.Empty(t, CacheFlags(nil, nil))
+	require.Equal(t,
+		[]string{
+			"--extra-substituters", "https://a.example.com s3://b?region=us-east-1",
+			"--extra-trusted-public-keys", "a.example.com-1:abc=",
+		},
+		CacheFlags(
+			[]string{"https://a.example.com", "s3://b?region=us-east-1"},
+			[]string{"a.example.com-1:abc="},
+		),
+	)
+}
+
+func TestConfigList(t *testing.T) {
+	config := map[string]nixConfigSetting{}
+	err := json.Unmarshal([]byte(`{
+		"substituters": {"value": ["https://cache.nixos.org/"]},
+		"trusted-users": {"value": "root @wheel"}
+	}`), &config)
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://cache.nixos.org/"}, configList(config, "substituters"))
+	require.Equal(t, []string{"root", "@wheel"}, configList(config, "trusted-users"))
+	require.Nil(t, configList(config, "trusted-public-keys"))
+}
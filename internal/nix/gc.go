@@ -0,0 +1,150 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/redact"
+)
+
+// ProfileGenerations returns the generations of the profile, oldest first.
+// Each generation is a <profile>-<N>-link symlink next to the profile, which
+// nix keeps as a GC root.
+func ProfileGenerations(profilePath string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Dir(profilePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []int{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	prefix := filepath.Base(profilePath)
+	generations := []int{}
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		matches := generationLinkRegex.FindStringSubmatch(rest)
+		if matches == nil || "-"+matches[1]+"-link" != rest {
+			continue
+		}
+		n, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		generations = append(generations, n)
+	}
+	sort.Ints(generations)
+	return generations, nil
+}
+
+// ProfileGenerationPath returns the path of the link to a generation of the
+// profile.
+func ProfileGenerationPath(profilePath string, generation int) string {
+	return profilePath + "-" + strconv.Itoa(generation) + "-link"
+}
+
+// DeleteProfileGeneration removes the link to a generation of the profile so
+// that the store paths that only it uses can be garbage collected. It's
+// like `nix profile wipe-history`, but for a single generation.
+func DeleteProfileGeneration(profilePath string, generation int) error {
+	current, err := ProfileGeneration(profilePath)
+	if err != nil {
+		return err
+	}
+	if generation == current {
+		return redact.Errorf("can't delete generation %d of %s because it is the current one", generation, profilePath)
+	}
+	return errors.WithStack(os.Remove(ProfileGenerationPath(profilePath, generation)))
+}
+
+// autoGCRootsDir is where nix registers the symlinks outside the store that
+// are GC roots, such as project profiles.
+var autoGCRootsDir = "/nix/var/nix/gcroots/auto"
+
+// GCRoot is a symlink in the nix auto GC roots directory that points to a
+// GC root outside the store, such as a profile generation.
+type GCRoot struct {
+	// Link is the path of the symlink in the GC roots directory.
+	Link string
+	// Target is the path of the GC root it points to.
+	Target string
+}
+
+// AutoGCRoots returns the GC roots that nix registered outside the store.
+func AutoGCRoots() ([]*GCRoot, error) {
+	entries, err := os.ReadDir(autoGCRootsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*GCRoot{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	roots := []*GCRoot{}
+	for _, entry := range entries {
+		link := filepath.Join(autoGCRootsDir, entry.Name())
+		target, err := os.Readlink(link)
+		if err != nil {
+			continue
+		}
+		roots = append(roots, &GCRoot{Link: link, Target: target})
+	}
+	return roots, nil
+}
+
+// Remove unregisters the GC root.
+func (r *GCRoot) Remove() error {
+	return errors.WithStack(os.Remove(r.Link))
+}
+
+// StoreGCResult is what `nix store gc` deleted.
+type StoreGCResult struct {
+	Paths int
+	// Freed is the number of bytes freed.
+	Freed int64
+}
+
+// StoreGC runs `nix store gc`, which deletes the store paths that aren't
+// reachable from a GC root. Its output is written to w.
+func StoreGC(ctx context.Context, w io.Writer) (*StoreGCResult, error) {
+	cmd := exec.CommandContext(ctx, "nix", "store", "gc")
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	out := &strings.Builder{}
+	cmd.Stdout = io.MultiWriter(w, out)
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "Command: %s", cmd)
+	}
+	return parseStoreGCOutput(out.String()), nil
+}
+
+// storeGCSummaryRegex matches the summary that `nix store gc` prints last,
+// e.g. "123 store paths deleted, 456.78 MiB freed".
+var storeGCSummaryRegex = regexp.MustCompile(`(\d+) store paths? deleted, ([\d.]+) MiB freed`)
+
+func parseStoreGCOutput(out string) *StoreGCResult {
+	result := &StoreGCResult{}
+	matches := storeGCSummaryRegex.FindAllStringSubmatch(out, -1)
+	if len(matches) == 0 {
+		return result
+	}
+	last := matches[len(matches)-1]
+	result.Paths, _ = strconv.Atoi(last[1])
+	mib, _ := strconv.ParseFloat(last[2], 64)
+	result.Freed = int64(mib * 1024 * 1024)
+	return result
+}
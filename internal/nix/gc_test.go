@@ -0,0 +1,79 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// makeProfile creates the links of a profile with the given generations that
+// points to the current one.
+func makeProfile(t *testing.T, generations []int, current int) string {
+	profile := filepath.Join(t.TempDir(), "default")
+	for _, g := range generations {
+		require.NoError(t, os.Symlink("/nix/store/a-profile", ProfileGenerationPath(profile, g)))
+	}
+	require.NoError(t, os.Symlink(filepath.Base(ProfileGenerationPath(profile, current)), profile))
+	return profile
+}
+
+func TestProfileGenerations(t *testing.T) {
+	profile := makeProfile(t, []int{10, 2, 3}, 10)
+	// Other profiles in the same directory aren't generations.
+	require.NoError(t, os.Symlink("/nix/store/b", filepath.Join(filepath.Dir(profile), "other-1-link")))
+	require.NoError(t, os.Symlink("/nix/store/c", filepath.Join(filepath.Dir(profile), "default-old-1-link")))
+
+	generations, err := ProfileGenerations(profile)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3, 10}, generations)
+
+	generations, err = ProfileGenerations(filepath.Join(t.TempDir(), "missing", "default"))
+	require.NoError(t, err)
+	require.Empty(t, generations)
+}
+
+func TestDeleteProfileGeneration(t *testing.T) {
+	profile := makeProfile(t, []int{1, 2}, 2)
+
+	require.NoError(t, DeleteProfileGeneration(profile, 1))
+	require.Error(t, DeleteProfileGeneration(profile, 2), "the current generation can't be deleted")
+
+	generations, err := ProfileGenerations(profile)
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, generations)
+}
+
+func TestAutoGCRoots(t *testing.T) {
+	dir := t.TempDir()
+	old := autoGCRootsDir
+	autoGCRootsDir = dir
+	t.Cleanup(func() { autoGCRootsDir = old })
+
+	target := "/home/user/project/.devbox/nix/profile/default-1-link"
+	require.NoError(t, os.Symlink(target, filepath.Join(dir, "abc")))
+
+	roots, err := AutoGCRoots()
+	require.NoError(t, err)
+	require.Equal(t, []*GCRoot{{Link: filepath.Join(dir, "abc"), Target: target}}, roots)
+
+	require.NoError(t, roots[0].Remove())
+	roots, err = AutoGCRoots()
+	require.NoError(t, err)
+	require.Empty(t, roots)
+}
+
+func TestParseStoreGCOutput(t *testing.T) {
+	out := "finding garbage collector roots...\n" +
+		"deleting '/nix/store/a-hello-2.12.1'\n" +
+		"deleting unused links...\n" +
+		"note: currently hard linking saves 1.50 MiB\n" +
+		"42 store paths deleted, 123.45 MiB freed\n"
+	require.Equal(t, &StoreGCResult{Paths: 42, Freed: 129446707}, parseStoreGCOutput(out))
+	require.Equal(t, &StoreGCResult{Paths: 1, Freed: 0}, parseStoreGCOutput("1 store path deleted, 0.00 MiB freed"))
+	require.Equal(t, &StoreGCResult{}, parseStoreGCOutput(""))
+}
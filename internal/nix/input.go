@@ -17,6 +17,7 @@ import (
 
 	"go.jetpack.io/devbox/internal/boxcli/usererr"
 	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/devpkg"
 	"go.jetpack.io/devbox/internal/lock"
 )
 
@@ -41,6 +42,10 @@ type Package struct {
 	//    example: github:nixos/nixpkgs/5233fd2ba76a3accb5aaa999c00509a11fd0793c#hello
 	Raw string
 
+	// Outputs are the package outputs selected with `^` in the config
+	// (e.g. `openssl@3^dev,out`). If empty, nix installs the default outputs.
+	Outputs []string
+
 	normalizedPackageAttributePathCache string // memoized value from normalizedPackageAttributePath()
 }
 
@@ -59,7 +64,8 @@ func PackageFromStrings(rawNames []string, l lock.Locker) []*Package {
 func PackageFromString(raw string, locker lock.Locker) *Package {
 	// TODO: We should handle this error
 	// TODO: URL might not be best representation since most packages are not urls
-	pkgURL, _ := url.Parse(raw)
+	withoutOutputs, outputs := devpkg.ParseOutputs(raw)
+	pkgURL, _ := url.Parse(withoutOutputs)
 
 	// This handles local flakes in a relative path.
 	// `raw` will be of the form `path:./local_flake_subdir#myPackage`
@@ -73,7 +79,7 @@ func PackageFromString(raw string, locker lock.Locker) *Package {
 		}
 		pkgURL, _ = url.Parse(normalizedURL)
 	}
-	return &Package{*pkgURL, locker, raw, ""}
+	return &Package{URL: *pkgURL, lockfile: locker, Raw: raw, Outputs: outputs}
 }
 
 // PackageFromProfileItem constructs a package using the the unlocked reference
@@ -155,13 +161,21 @@ func (p *Package) URLForInstall() (string, error) {
 		if err != nil {
 			return "", err
 		}
-		return entry.Resolved, nil
+		return entry.Resolved + p.outputsSuffix(), nil
 	}
 	attrPath, err := p.FullPackageAttributePath()
 	if err != nil {
 		return "", err
 	}
-	return p.urlWithoutFragment() + "#" + attrPath, nil
+	return p.urlWithoutFragment() + "#" + attrPath + p.outputsSuffix(), nil
+}
+
+// outputsSuffix returns the nix installable output selector, e.g. `^dev,out`.
+func (p *Package) outputsSuffix() string {
+	if len(p.Outputs) == 0 {
+		return ""
+	}
+	return "^" + strings.Join(p.Outputs, ",")
 }
 
 func (p *Package) normalizedDevboxPackageReference() (string, error) {
@@ -14,6 +14,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"go.jetpack.io/devbox/internal/build"
+	"go.jetpack.io/devbox/internal/cuecfg"
 	"go.jetpack.io/devbox/internal/debug"
 )
 
@@ -32,9 +34,21 @@ type Variable struct {
 }
 
 type PrintDevEnvArgs struct {
+	// ExtraFlags are appended to the nix command, e.g. binary cache flags.
+	ExtraFlags           []string
 	FlakesFilePath       string
 	PrintDevEnvCachePath string
-	UsePrintDevEnvCache  bool
+	// UsePrintDevEnvCache reuses the output saved in PrintDevEnvCachePath if
+	// it was saved for the current flake and flake.lock. Otherwise, nix
+	// print-dev-env always runs.
+	UsePrintDevEnvCache bool
+}
+
+// printDevEnvCache is the output of nix print-dev-env saved along with the
+// key of the flake that it was computed from.
+type printDevEnvCache struct {
+	Key string          `json:"key"`
+	Out *PrintDevEnvOut `json:"out"`
 }
 
 // PrintDevEnv calls `nix print-dev-env -f <path>` and returns its output. The output contains
@@ -42,54 +56,94 @@ type PrintDevEnvArgs struct {
 func (*Nix) PrintDevEnv(ctx context.Context, args *PrintDevEnvArgs) (*PrintDevEnvOut, error) {
 	defer trace.StartRegion(ctx, "nixPrintDevEnv").End()
 
-	var data []byte
-	var err error
-	var out PrintDevEnvOut
-
 	if args.UsePrintDevEnvCache {
-		data, err = os.ReadFile(args.PrintDevEnvCachePath)
-		if err == nil {
-			if err := json.Unmarshal(data, &out); err != nil {
-				return nil, errors.WithStack(err)
-			}
-		} else if !errors.Is(err, fs.ErrNotExist) {
-			return nil, errors.WithStack(err)
+		out, err := readPrintDevEnvCache(args)
+		if err != nil || out != nil {
+			return out, err
 		}
 	}
 
-	if len(data) == 0 {
-		cmd := exec.CommandContext(
-			ctx,
-			"nix", "print-dev-env",
-			args.FlakesFilePath,
-		)
-		cmd.Args = append(cmd.Args, ExperimentalFlags()...)
-		cmd.Args = append(cmd.Args, "--json")
-		debug.Log("Running print-dev-env cmd: %s\n", cmd)
-		data, err = cmd.Output()
-		if err != nil {
-			return nil, errors.Wrapf(err, "Command: %s", cmd)
-		}
+	cmd := exec.CommandContext(
+		ctx,
+		"nix", "print-dev-env",
+		args.FlakesFilePath,
+	)
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	cmd.Args = append(cmd.Args, args.ExtraFlags...)
+	cmd.Args = append(cmd.Args, "--json")
+	debug.Log("Running print-dev-env cmd: %s\n", cmd)
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Command: %s", cmd)
+	}
 
-		if err := json.Unmarshal(data, &out); err != nil {
-			return nil, errors.WithStack(err)
-		}
+	var out PrintDevEnvOut
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-		if err = savePrintDevEnvCache(args.PrintDevEnvCachePath, out); err != nil {
-			return nil, errors.WithStack(err)
-		}
+	if err = savePrintDevEnvCache(args, &out); err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return &out, nil
 }
 
-func savePrintDevEnvCache(path string, out PrintDevEnvOut) error {
-	data, err := json.Marshal(out)
+// printDevEnvCacheKey identifies the inputs of nix print-dev-env: the flake,
+// its lock, and the version of devbox that generated them. It doesn't depend
+// on the parts of devbox.json that aren't in the flake, such as env and
+// scripts, so changing those doesn't require evaluating the flake again.
+func printDevEnvCacheKey(flakePath string) (string, error) {
+	flakeHash, err := cuecfg.FileHash(flakePath)
+	if err != nil {
+		return "", err
+	}
+	// nix writes flake.lock the first time it evaluates the flake, so it
+	// may be missing.
+	lockHash, err := cuecfg.FileHash(filepath.Join(filepath.Dir(flakePath), "flake.lock"))
+	if err != nil {
+		return "", err
+	}
+	return cuecfg.Hash([]string{build.Version, flakeHash, lockHash})
+}
+
+// readPrintDevEnvCache returns the cached output of nix print-dev-env, or
+// nil if there is none for the current flake.
+func readPrintDevEnvCache(args *PrintDevEnvArgs) (*PrintDevEnvOut, error) {
+	data, err := os.ReadFile(args.PrintDevEnvCachePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	key, err := printDevEnvCacheKey(args.FlakesFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var cache printDevEnvCache
+	// A cache that can't be read, such as one saved by an older version of
+	// devbox, is recomputed.
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Key != key || cache.Out == nil {
+		debug.Log("print-dev-env cache is stale")
+		return nil, nil
+	}
+	return cache.Out, nil
+}
+
+func savePrintDevEnvCache(args *PrintDevEnvArgs, out *PrintDevEnvOut) error {
+	// The key is computed after running nix, which may have written
+	// flake.lock.
+	key, err := printDevEnvCacheKey(args.FlakesFilePath)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(printDevEnvCache{Key: key, Out: out})
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	_ = os.WriteFile(path, data, 0644)
+	_ = os.WriteFile(args.PrintDevEnvCachePath, data, 0644)
 	return nil
 }
 
@@ -0,0 +1,86 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPrintDevEnvArgs(t testing.TB) *PrintDevEnvArgs {
+	dir := t.TempDir()
+	args := &PrintDevEnvArgs{
+		FlakesFilePath:       filepath.Join(dir, "flake.nix"),
+		PrintDevEnvCachePath: filepath.Join(dir, ".nix-print-dev-env-cache"),
+		UsePrintDevEnvCache:  true,
+	}
+	require.NoError(t, os.WriteFile(args.FlakesFilePath, []byte(`{ outputs = { }; }`), 0644))
+	return args
+}
+
+func TestPrintDevEnvCache(t *testing.T) {
+	args := testPrintDevEnvArgs(t)
+	out := &PrintDevEnvOut{Variables: map[string]Variable{
+		"PATH": {Type: "exported", Value: "/nix/store/a-go-1.20.5/bin"},
+	}}
+	require.NoError(t, savePrintDevEnvCache(args, out))
+
+	// A cache for the current flake is used without running nix.
+	cached, err := (&Nix{}).PrintDevEnv(context.Background(), args)
+	require.NoError(t, err)
+	require.Equal(t, out, cached)
+
+	// Creating flake.lock or changing the flake makes the cache stale.
+	flakeLock := filepath.Join(filepath.Dir(args.FlakesFilePath), "flake.lock")
+	require.NoError(t, os.WriteFile(flakeLock, []byte(`{}`), 0644))
+	cached, err = readPrintDevEnvCache(args)
+	require.NoError(t, err)
+	require.Nil(t, cached)
+
+	require.NoError(t, savePrintDevEnvCache(args, out))
+	require.NoError(t, os.WriteFile(args.FlakesFilePath, []byte(`{ outputs = { x = 1; }; }`), 0644))
+	cached, err = readPrintDevEnvCache(args)
+	require.NoError(t, err)
+	require.Nil(t, cached)
+}
+
+func TestPrintDevEnvCacheOldFormat(t *testing.T) {
+	args := testPrintDevEnvArgs(t)
+	// Older versions saved the output without a key.
+	require.NoError(t, os.WriteFile(args.PrintDevEnvCachePath, []byte(`{"Variables": {}}`), 0644))
+
+	cached, err := readPrintDevEnvCache(args)
+	require.NoError(t, err)
+	require.Nil(t, cached)
+}
+
+// BenchmarkPrintDevEnvCached measures getting the Nix environment from the
+// cache, which devbox does every time it starts a shell or runs a script in a
+// project whose packages haven't changed.
+func BenchmarkPrintDevEnvCached(b *testing.B) {
+	args := testPrintDevEnvArgs(b)
+	out := &PrintDevEnvOut{Variables: map[string]Variable{}}
+	// A print-dev-env of a few packages has a few hundred variables.
+	for i := 0; i < 300; i++ {
+		out.Variables[fmt.Sprintf("VAR_%d", i)] = Variable{
+			Type:  "exported",
+			Value: fmt.Sprintf("/nix/store/%032d-pkg-%d/bin", i, i),
+		}
+	}
+	require.NoError(b, savePrintDevEnvCache(args, out))
+
+	ctx := context.Background()
+	n := &Nix{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := n.PrintDevEnv(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package nix
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,11 +12,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 
 	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/ux/stepper"
 	"go.jetpack.io/devbox/internal/xdg"
 )
 
@@ -26,22 +29,12 @@ func ensureNixpkgsPrefetched(w io.Writer, commit string) error {
 	if err != nil {
 		return err
 	}
-
-	// Check if this nixpkgs.Commit is located in the local /nix/store
-	location, isPresent := commitToLocation[commit]
-	if isPresent {
-		if fi, err := os.Stat(location); err == nil && fi.IsDir() {
-			// The nixpkgs for this commit hash is present, so we don't need to prefetch
-			return nil
-		}
+	if isNixpkgsPrefetched(commit, commitToLocation) {
+		return nil
 	}
 
 	fmt.Fprintf(w, "Ensuring nixpkgs registry is downloaded.\n")
-	cmd := exec.Command(
-		"nix", "flake", "prefetch",
-		FlakeNixpkgs(commit),
-	)
-	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	cmd := nixpkgsPrefetchCmd(commit)
 	cmd.Stdout = w
 	cmd.Stderr = cmd.Stdout
 	if err := cmd.Run(); err != nil {
@@ -55,6 +48,55 @@ func ensureNixpkgsPrefetched(w io.Writer, commit string) error {
 	return saveToNixpkgsCommitFile(commit, commitToLocation)
 }
 
+// ensureNixpkgsPrefetchedWithStep is like ensureNixpkgsPrefetched, but reports
+// to step instead of printing, so that nix's output doesn't garble the step's
+// spinner. The step is failed if the prefetch fails.
+func ensureNixpkgsPrefetchedWithStep(step *stepper.Step, commit string) error {
+	commitToLocation, err := nixpkgsCommitFileContents()
+	if err != nil {
+		step.Fail(err.Error())
+		return err
+	}
+	if isNixpkgsPrefetched(commit, commitToLocation) {
+		return nil
+	}
+
+	step.Update("downloading nixpkgs", 0, 0)
+	cmd := nixpkgsPrefetchCmd(commit)
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		step.Fail(strings.TrimSpace(out.String()))
+		return errors.Wrapf(err, "Command: %s", cmd)
+	}
+	if err := saveToNixpkgsCommitFile(commit, commitToLocation); err != nil {
+		step.Fail(err.Error())
+		return err
+	}
+	return nil
+}
+
+// isNixpkgsPrefetched reports whether the nixpkgs for commit is in the local
+// /nix/store.
+func isNixpkgsPrefetched(commit string, commitToLocation map[string]string) bool {
+	location, isPresent := commitToLocation[commit]
+	if !isPresent {
+		return false
+	}
+	fi, err := os.Stat(location)
+	return err == nil && fi.IsDir()
+}
+
+func nixpkgsPrefetchCmd(commit string) *exec.Cmd {
+	cmd := exec.Command(
+		"nix", "flake", "prefetch",
+		FlakeNixpkgs(commit),
+	)
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	return cmd
+}
+
 func nixpkgsCommitFileContents() (map[string]string, error) {
 	path := nixpkgsCommitFilePath()
 	if !fileutil.Exists(path) {
@@ -110,3 +152,28 @@ func nixpkgsCommitFilePath() string {
 	cacheDir := xdg.CacheSubpath("devbox")
 	return filepath.Join(cacheDir, "nixpkgs.json")
 }
+
+// PruneNixpkgsCommitFile removes the nixpkgs commits whose store paths no
+// longer exist from the cache of prefetched commits, and returns how many it
+// removed.
+func PruneNixpkgsCommitFile() (int, error) {
+	commitToLocation, err := nixpkgsCommitFileContents()
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for commit, location := range commitToLocation {
+		if !fileutil.Exists(location) {
+			delete(commitToLocation, commit)
+			pruned++
+		}
+	}
+	if pruned == 0 {
+		return 0, nil
+	}
+	serialized, err := json.Marshal(commitToLocation)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return pruned, errors.WithStack(os.WriteFile(nixpkgsCommitFilePath(), serialized, 0644))
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -21,6 +22,7 @@ import (
 
 	"go.jetpack.io/devbox/internal/lock"
 	"go.jetpack.io/devbox/internal/redact"
+	"go.jetpack.io/devbox/internal/ux/stepper"
 )
 
 const DefaultPriority = 5
@@ -205,17 +207,29 @@ type ProfileInstallArgs struct {
 	Lockfile          *lock.File
 	Package           string
 	ProfilePath       string
-	Writer            io.Writer
+	// Step, if set, reports the progress of the install instead of printing
+	// nix's output to Writer.
+	Step   *stepper.Step
+	Writer io.Writer
 }
 
 // ProfileInstall calls nix profile install with default profile
 func ProfileInstall(args *ProfileInstallArgs) error {
 	input := PackageFromString(args.Package, args.Lockfile)
+	if args.Step != nil {
+		if IsGithubNixpkgsURL(input.URLForFlakeInput()) {
+			if err := ensureNixpkgsPrefetchedWithStep(args.Step, input.hashFromNixPkgsURL()); err != nil {
+				return err
+			}
+		}
+		return profileInstallWithProgress(args)
+	}
 	if IsGithubNixpkgsURL(input.URLForFlakeInput()) {
 		if err := ensureNixpkgsPrefetched(args.Writer, input.hashFromNixPkgsURL()); err != nil {
 			return err
 		}
 	}
+
 	stepMsg := args.Package
 	if args.CustomStepMessage != "" {
 		stepMsg = args.CustomStepMessage
@@ -229,19 +243,7 @@ func ProfileInstall(args *ProfileInstallArgs) error {
 		return err
 	}
 
-	cmd := exec.Command(
-		"nix", "profile", "install",
-		"--profile", args.ProfilePath,
-		"--impure", // for NIXPKGS_ALLOW_UNFREE
-		// Using an arbitrary priority to avoid conflicts with other packages.
-		// Note that this is not really the priority we care about, since we
-		// use the flake.nix to specify the priority.
-		"--priority", nextPriority(args.ProfilePath),
-		urlForInstall,
-	)
-	cmd.Env = allowUnfreeEnv()
-	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
-	cmd.Args = append(cmd.Args, args.ExtraFlags...)
+	cmd := profileInstallCmd(args, urlForInstall)
 
 	// If nix profile install runs as tty, the output is much nicer. If we ever
 	// need to change this to our own writers, consider that you may need
@@ -261,6 +263,90 @@ func ProfileInstall(args *ProfileInstallArgs) error {
 	return nil
 }
 
+type RealiseArgs struct {
+	ExtraFlags []string
+	Lockfile   *lock.File
+	Package    string
+	// Step reports the progress of fetching or building the package. It is
+	// only ended if Realise fails, so that the caller can go on to install
+	// the package in the same step.
+	Step *stepper.Step
+}
+
+// Realise fetches or builds the package into the nix store without adding it
+// to a profile. Since it doesn't modify the profile, it can run concurrently
+// for several packages, which makes a later ProfileInstall of each of them
+// fast.
+func Realise(args *RealiseArgs) error {
+	input := PackageFromString(args.Package, args.Lockfile)
+	urlForInstall, err := input.URLForInstall()
+	if err != nil {
+		args.Step.Fail(err.Error())
+		return err
+	}
+
+	cmd := exec.Command(
+		"nix", "build",
+		"--no-link",
+		"--impure", // for NIXPKGS_ALLOW_UNFREE
+		"--log-format", "internal-json",
+		urlForInstall,
+	)
+	cmd.Env = allowUnfreeEnv()
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	cmd.Args = append(cmd.Args, args.ExtraFlags...)
+	progress := NewProgressWriter(args.Step)
+	cmd.Stdout = progress
+	cmd.Stderr = progress
+
+	if err := cmd.Run(); err != nil {
+		args.Step.Fail(strings.Join(progress.Errors(), "\n"))
+		return redact.Errorf("error running \"nix build\": %w", err)
+	}
+	return nil
+}
+
+// profileInstallWithProgress runs nix profile install with structured logs
+// and reports its progress to args.Step.
+func profileInstallWithProgress(args *ProfileInstallArgs) error {
+	input := PackageFromString(args.Package, args.Lockfile)
+	urlForInstall, err := input.URLForInstall()
+	if err != nil {
+		args.Step.Fail(err.Error())
+		return err
+	}
+
+	cmd := profileInstallCmd(args, urlForInstall)
+	cmd.Args = append(cmd.Args, "--log-format", "internal-json")
+	progress := NewProgressWriter(args.Step)
+	cmd.Stdout = progress
+	cmd.Stderr = progress
+
+	if err := cmd.Run(); err != nil {
+		args.Step.Fail(strings.Join(progress.Errors(), "\n"))
+		return redact.Errorf("error running \"nix profile install\": %w", err)
+	}
+	args.Step.Success()
+	return nil
+}
+
+func profileInstallCmd(args *ProfileInstallArgs, urlForInstall string) *exec.Cmd {
+	cmd := exec.Command(
+		"nix", "profile", "install",
+		"--profile", args.ProfilePath,
+		"--impure", // for NIXPKGS_ALLOW_UNFREE
+		// Using an arbitrary priority to avoid conflicts with other packages.
+		// Note that this is not really the priority we care about, since we
+		// use the flake.nix to specify the priority.
+		"--priority", nextPriority(args.ProfilePath),
+		urlForInstall,
+	)
+	cmd.Env = allowUnfreeEnv()
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	cmd.Args = append(cmd.Args, args.ExtraFlags...)
+	return cmd
+}
+
 // ProfileRemoveItems removes the items from the profile, in a single call, using their indexes.
 // It is up to the caller to ensure that the underlying profile has not changed since the items
 // were queried.
@@ -345,3 +431,36 @@ func nextPriority(profilePath string) string {
 	// behaves
 	return fmt.Sprintf("%d", max+1)
 }
+
+var generationLinkRegex = regexp.MustCompile(`-(\d+)-link$`)
+
+// ProfileGeneration returns the generation the profile currently points to,
+// or 0 if the profile doesn't exist yet.
+func ProfileGeneration(profilePath string) (int, error) {
+	target, err := os.Readlink(profilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	matches := generationLinkRegex.FindStringSubmatch(target)
+	if matches == nil {
+		return 0, redact.Errorf("unexpected nix profile link %s", target)
+	}
+	return strconv.Atoi(matches[1])
+}
+
+// ProfileRollback switches the profile back to a previous generation.
+func ProfileRollback(profilePath string, generation int) error {
+	cmd := exec.Command("nix", "profile", "rollback",
+		"--profile", profilePath,
+		"--to", strconv.Itoa(generation),
+	)
+	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return redact.Errorf("error running \"nix profile rollback\": %s: %w", out, err)
+	}
+	return nil
+}
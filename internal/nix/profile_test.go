@@ -5,6 +5,8 @@ package nix
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -90,3 +92,27 @@ func testItem(t *testing.T, line string, expected expectedTestData) {
 		t.Errorf("expected attribute path %s but got %s", expected.attrPath, gotAttrPath)
 	}
 }
+
+func TestProfileGeneration(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "default")
+
+	gen, err := ProfileGeneration(profile)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if gen != 0 {
+		t.Errorf("expected generation 0 for missing profile but got %d", gen)
+	}
+
+	if err := os.Symlink("default-12-link", profile); err != nil {
+		t.Fatal(err)
+	}
+	gen, err = ProfileGeneration(profile)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if gen != 12 {
+		t.Errorf("expected generation 12 but got %d", gen)
+	}
+}
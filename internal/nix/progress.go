@@ -0,0 +1,213 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.jetpack.io/devbox/internal/debug"
+	"go.jetpack.io/devbox/internal/ux/stepper"
+)
+
+// Activity and result types of nix --log-format internal-json. See
+// ActivityType and ResultType in nix's src/libutil/logging.hh.
+const (
+	actCopyPath     = 100
+	actFileTransfer = 101
+	actBuild        = 105
+	actSubstitute   = 108
+
+	resProgress = 105
+)
+
+// Log levels of nix. Messages up to lvlWarn are shown to the user.
+const (
+	lvlError = 0
+	lvlWarn  = 1
+)
+
+// logEvent is a line of nix --log-format internal-json output, without its
+// "@nix " prefix.
+type logEvent struct {
+	Action string `json:"action"`
+	ID     int64  `json:"id"`
+	Level  int    `json:"level"`
+	Type   int    `json:"type"`
+	Text   string `json:"text"`
+	Msg    string `json:"msg"`
+	Fields []any  `json:"fields"`
+}
+
+type transfer struct {
+	done, expected int64
+}
+
+// ProgressWriter parses the output of nix --log-format internal-json and
+// reports download progress, the current activity and messages to a step.
+type ProgressWriter struct {
+	step *stepper.Step
+
+	mu  sync.Mutex
+	buf []byte
+	// activities are the descriptions of running activities, by id.
+	activities map[int64]string
+	// current is the id of the most recently started activity that is still
+	// running.
+	current int64
+	// transfers are the downloads of this run, by activity id. They are kept
+	// after the download finishes so that the total stays correct.
+	transfers map[int64]*transfer
+	// baseDone and baseExpected are the bytes the step downloaded before
+	// this writer was created, such as when a package is realised and then
+	// installed in the same step.
+	baseDone, baseExpected int64
+	// errors are the error messages printed by nix, which explain why it
+	// failed.
+	errors []string
+}
+
+func NewProgressWriter(step *stepper.Step) *ProgressWriter {
+	w := &ProgressWriter{
+		step:       step,
+		activities: map[int64]string{},
+		transfers:  map[int64]*transfer{},
+	}
+	w.baseDone, w.baseExpected = step.Bytes()
+	return w
+}
+
+func (w *ProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.handleLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Errors returns the error messages that nix printed.
+func (w *ProgressWriter) Errors() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.errors
+}
+
+func (w *ProgressWriter) handleLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	data, ok := strings.CutPrefix(line, "@nix ")
+	if !ok {
+		// Not all output is JSON, such as warnings printed before the logger
+		// is set up.
+		if strings.TrimSpace(line) != "" {
+			w.step.Log(line)
+		}
+		return
+	}
+	event := &logEvent{}
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		debug.Log("Error parsing nix log line %q: %v", line, err)
+		return
+	}
+
+	switch event.Action {
+	case "msg":
+		if event.Level > lvlWarn {
+			return
+		}
+		msg := stripANSI(event.Msg)
+		if event.Level == lvlError {
+			w.errors = append(w.errors, msg)
+			return
+		}
+		w.step.Log(msg)
+	case "start":
+		if text := describeActivity(event); text != "" {
+			w.activities[event.ID] = text
+			w.current = event.ID
+			w.update()
+		}
+		if event.Type == actFileTransfer {
+			w.transfers[event.ID] = &transfer{}
+		}
+	case "stop":
+		if _, ok := w.activities[event.ID]; !ok {
+			return
+		}
+		delete(w.activities, event.ID)
+		if w.current == event.ID {
+			w.current = 0
+			for id := range w.activities {
+				if id > w.current {
+					w.current = id
+				}
+			}
+		}
+		w.update()
+	case "result":
+		t, ok := w.transfers[event.ID]
+		if !ok || event.Type != resProgress || len(event.Fields) < 2 {
+			return
+		}
+		t.done = fieldInt(event.Fields[0])
+		t.expected = fieldInt(event.Fields[1])
+		w.update()
+	}
+}
+
+func (w *ProgressWriter) update() {
+	done, expected := w.baseDone, w.baseExpected
+	for _, t := range w.transfers {
+		done += t.done
+		expected += t.expected
+	}
+	w.step.Update(w.activities[w.current], done, expected)
+}
+
+var storePathRegex = regexp.MustCompile(`/nix/store/[0-9a-z]{32}-([^'"\s]+)`)
+
+// describeActivity returns a short description of the activities that are
+// shown to the user, or "" for other activities. File transfers aren't shown
+// since they only name the URL, and are reported as bytes of the fetch that
+// started them instead.
+func describeActivity(event *logEvent) string {
+	var verb string
+	switch event.Type {
+	case actBuild:
+		verb = "building"
+	case actSubstitute, actCopyPath:
+		verb = "fetching"
+	default:
+		return ""
+	}
+	if m := storePathRegex.FindStringSubmatch(event.Text); m != nil {
+		return fmt.Sprintf("%s %s", verb, strings.TrimSuffix(m[1], ".drv"))
+	}
+	return verb
+}
+
+func fieldInt(field any) int64 {
+	// encoding/json decodes numbers in []any as float64.
+	if f, ok := field.(float64); ok {
+		return int64(f)
+	}
+	return 0
+}
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripANSI(s string) string {
+	return ansiRegex.ReplaceAllString(s, "")
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package nix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/ux/stepper"
+)
+
+const nixInternalJSONLog = `@nix {"action":"start","id":1,"level":4,"parent":0,"text":"querying info about missing paths","type":0}
+@nix {"action":"stop","id":1}
+@nix {"action":"start","id":2,"level":4,"parent":0,"text":"copying path '/nix/store/0c5ghm8h2r8qk6p5hjvzfg4bq6jyw3lg-go-1.21.0' from 'https://cache.nixos.org'","type":108}
+@nix {"action":"start","id":3,"level":4,"parent":2,"text":"downloading 'https://cache.nixos.org/nar/abc.nar.xz'","type":101}
+@nix {"action":"result","fields":[1024,4096,0,0],"id":3,"type":105}
+@nix {"action":"msg","level":1,"msg":"\u001b[35;1mwarning:\u001b[0m ignoring untrusted substituter"}
+@nix {"action":"result","fields":[4096,4096,0,0],"id":3,"type":105}
+@nix {"action":"stop","id":3}
+@nix {"action":"stop","id":2}
+@nix {"action":"msg","level":3,"msg":"this is too verbose to show"}
+@nix {"action":"msg","level":0,"msg":"error: collision between two packages"}
+`
+
+func TestProgressWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	progress := stepper.NewProgressWithMode(out, 1, stepper.ModeJSON)
+	step := progress.Start("go@1.21")
+	w := NewProgressWriter(step)
+
+	// Write in small chunks to check that lines are buffered.
+	for i := 0; i < len(nixInternalJSONLog); i += 7 {
+		end := i + 7
+		if end > len(nixInternalJSONLog) {
+			end = len(nixInternalJSONLog)
+		}
+		_, err := w.Write([]byte(nixInternalJSONLog[i:end]))
+		require.NoError(t, err)
+	}
+	step.Fail("failed")
+
+	events := []*stepper.Event{}
+	s := bufio.NewScanner(out)
+	for s.Scan() {
+		data, ok := bytes.CutPrefix(s.Bytes(), []byte(stepper.EventPrefix))
+		require.True(t, ok)
+		e := &stepper.Event{}
+		require.NoError(t, json.Unmarshal(data, e))
+		events = append(events, e)
+	}
+
+	require.Equal(t, "start", events[0].Type)
+	// The first progress event is printed, later ones are throttled.
+	require.Equal(t, "progress", events[1].Type)
+	require.Equal(t, "fetching go-1.21.0", events[1].Activity)
+
+	logs := []string{}
+	for _, e := range events {
+		if e.Type == "log" {
+			logs = append(logs, e.Message)
+		}
+	}
+	require.Equal(t, []string{"warning: ignoring untrusted substituter"}, logs)
+
+	last := events[len(events)-1]
+	require.Equal(t, "fail", last.Type)
+	require.Equal(t, int64(4096), last.BytesDone)
+	require.Equal(t, int64(4096), last.BytesExpected)
+	require.Equal(t, "", last.Activity)
+	require.Equal(t, []string{"error: collision between two packages"}, w.Errors())
+}
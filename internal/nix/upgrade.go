@@ -10,7 +10,7 @@ import (
 	"go.jetpack.io/devbox/internal/ux"
 )
 
-func ProfileUpgrade(ProfileDir string, pkg *Package, lock *lock.File) error {
+func ProfileUpgrade(ProfileDir string, pkg *Package, lock *lock.File, extraFlags ...string) error {
 	idx, err := ProfileListIndex(&ProfileListIndexArgs{
 		Lockfile:   lock,
 		Writer:     os.Stderr,
@@ -25,6 +25,7 @@ func ProfileUpgrade(ProfileDir string, pkg *Package, lock *lock.File) error {
 		fmt.Sprintf("%d", idx),
 	)
 	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	cmd.Args = append(cmd.Args, extraFlags...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return redact.Errorf(
@@ -33,10 +34,11 @@ func ProfileUpgrade(ProfileDir string, pkg *Package, lock *lock.File) error {
 	return nil
 }
 
-func FlakeUpdate(ProfileDir string) error {
+func FlakeUpdate(ProfileDir string, extraFlags ...string) error {
 	ux.Finfo(os.Stderr, "Running \"nix flake update\"\n")
 	cmd := exec.Command("nix", "flake", "update", ProfileDir)
 	cmd.Args = append(cmd.Args, ExperimentalFlags()...)
+	cmd.Args = append(cmd.Args, extraFlags...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return redact.Errorf(
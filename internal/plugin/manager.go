@@ -4,6 +4,10 @@
 package plugin
 
 import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
 	"go.jetpack.io/devbox/internal/lock"
 	"go.jetpack.io/devbox/internal/nix"
 )
@@ -58,3 +62,34 @@ func (m *Manager) PluginInputs(inputs []*nix.Package) ([]*nix.Package, error) {
 	}
 	return result, nil
 }
+
+// Contents returns the config of each plugin that pkgs and includes use, with
+// the project's values filled in, so that callers can tell when what the
+// plugins run changes.
+func (m *Manager) Contents(pkgs []*nix.Package, includes []string) ([][]byte, error) {
+	allPkgs := append([]*nix.Package(nil), pkgs...)
+	for _, include := range includes {
+		pkg, err := m.parseInclude(include)
+		if err != nil {
+			return nil, err
+		}
+		allPkgs = append(allPkgs, pkg)
+	}
+
+	contents := [][]byte{}
+	for _, pkg := range allPkgs {
+		cfg, err := getConfigIfAny(pkg, m.ProjectDir())
+		if err != nil {
+			return nil, err
+		}
+		if cfg == nil {
+			continue
+		}
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		contents = append(contents, data)
+	}
+	return contents, nil
+}
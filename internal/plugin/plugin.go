@@ -43,6 +43,8 @@ type config struct {
 	Packages    []string          `json:"packages"`
 	Env         map[string]string `json:"env"`
 	Readme      string            `json:"readme"`
+	// Ports are the TCP ports the plugin's services listen on by default.
+	Ports []int `json:"ports,omitempty"`
 
 	Shell struct {
 		// InitHook contains commands that will run at shell startup.
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/samber/lo"
+	"golang.org/x/exp/slices"
+
 	"go.jetpack.io/devbox/internal/nix"
 	"go.jetpack.io/devbox/internal/services"
 )
@@ -47,3 +50,33 @@ func (m *Manager) GetServices(
 
 	return allSvcs, nil
 }
+
+// ServicePorts returns the ports that the services of the plugins for pkgs
+// and includes listen on, sorted and without duplicates.
+func (m *Manager) ServicePorts(
+	pkgs []*nix.Package,
+	includes []string,
+) ([]int, error) {
+	allPkgs := append([]*nix.Package(nil), pkgs...)
+	for _, include := range includes {
+		name, err := m.parseInclude(include)
+		if err != nil {
+			return nil, err
+		}
+		allPkgs = append(allPkgs, name)
+	}
+
+	ports := []int{}
+	for _, pkg := range allPkgs {
+		conf, err := getConfigIfAny(pkg, m.ProjectDir())
+		if err != nil {
+			return nil, err
+		}
+		if conf != nil {
+			ports = append(ports, conf.Ports...)
+		}
+	}
+	ports = lo.Uniq(ports)
+	slices.Sort(ports)
+	return ports, nil
+}
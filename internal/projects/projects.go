@@ -0,0 +1,297 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+// Package projects keeps a registry of the devbox projects on this machine.
+//
+// Devbox records a project every time it opens it, so that commands can work
+// across projects, e.g. to list them, stop all their services or garbage
+// collect what they no longer use. Projects are keyed by their absolute
+// directory.
+package projects
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/xdg"
+)
+
+// recordInterval is how often the last use of a project is updated. Devbox
+// opens the project every time the shell prompt is shown, so it doesn't
+// write the registry every time.
+const recordInterval = time.Minute
+
+// lockTimeout is how long to wait for another devbox command that is updating
+// the registry.
+const lockTimeout = 5 * time.Second
+
+// Project is a devbox project in the registry.
+type Project struct {
+	// Dir is the absolute directory of the project.
+	Dir        string    `json:"-"`
+	LastUsed   time.Time `json:"last_used"`
+	ConfigHash string    `json:"config_hash"`
+	// ServicesRunning is whether the project's services were running when
+	// devbox last checked.
+	ServicesRunning bool `json:"services_running,omitempty"`
+	// Missing is whether the project no longer exists. It's set by the
+	// callers that check.
+	Missing bool `json:"-"`
+}
+
+// registry maps project directories to projects.
+type registry map[string]*Project
+
+// Record notes that the project in dir, whose config has the given hash, was
+// used now.
+func Record(dir, configHash string) error {
+	return record(dir, configHash, update)
+}
+
+// TryRecord is like Record, but skips the update instead of waiting if another
+// devbox command is updating the registry. The shell hook uses it, since it
+// runs every time the prompt is shown.
+func TryRecord(dir, configHash string) error {
+	return record(dir, configHash, tryUpdate)
+}
+
+func record(dir, configHash string, update func(func(registry) bool) error) error {
+	// The project has usually been recorded recently, so check without
+	// taking the lock first.
+	projects, err := load()
+	if err != nil {
+		return err
+	}
+	if isRecorded(projects[key(dir)], configHash) {
+		return nil
+	}
+
+	return update(func(projects registry) bool {
+		k := key(dir)
+		p := projects[k]
+		if p == nil {
+			p = &Project{}
+			projects[k] = p
+		}
+		if isRecorded(p, configHash) {
+			return false
+		}
+		p.LastUsed = time.Now().UTC()
+		p.ConfigHash = configHash
+		return true
+	})
+}
+
+// isRecorded reports whether p has the given config hash and was used within
+// the last recordInterval.
+func isRecorded(p *Project, configHash string) bool {
+	return p != nil && p.ConfigHash == configHash && time.Since(p.LastUsed) < recordInterval
+}
+
+// SetServicesRunning records whether the services of the project in dir are
+// running. It does nothing if the project isn't in the registry.
+func SetServicesRunning(dir string, running bool) error {
+	return update(func(projects registry) bool {
+		p := projects[key(dir)]
+		if p == nil || p.ServicesRunning == running {
+			return false
+		}
+		p.ServicesRunning = running
+		return true
+	})
+}
+
+// List returns the projects in the registry, most recently used first.
+func List() ([]*Project, error) {
+	projects, err := load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Project, 0, len(projects))
+	for dir, p := range projects {
+		p.Dir = dir
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].LastUsed.Equal(list[j].LastUsed) {
+			return list[i].LastUsed.After(list[j].LastUsed)
+		}
+		return list[i].Dir < list[j].Dir
+	})
+	return list, nil
+}
+
+// Prune removes the projects for which keep returns false, and returns them.
+func Prune(keep func(*Project) bool) ([]*Project, error) {
+	removed := []*Project{}
+	err := update(func(projects registry) bool {
+		for dir, p := range projects {
+			p.Dir = dir
+			if !keep(p) {
+				removed = append(removed, p)
+				delete(projects, dir)
+			}
+		}
+		return len(removed) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Dir < removed[j].Dir })
+	return removed, nil
+}
+
+func key(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return dir
+}
+
+// update loads the registry, lets fn change it, and saves it if fn returns
+// true. The registry is locked until it's saved, so that devbox commands
+// running at the same time don't undo each other's changes.
+func update(fn func(registry) bool) error {
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return loadAndSave(fn)
+}
+
+// tryUpdate is like update, but does nothing if another devbox command holds
+// the lock on the registry.
+func tryUpdate(fn func(registry) bool) error {
+	unlock, err := tryLock()
+	if err != nil || unlock == nil {
+		return err
+	}
+	defer unlock()
+	return loadAndSave(fn)
+}
+
+func loadAndSave(fn func(registry) bool) error {
+	projects, err := load()
+	if err != nil {
+		return err
+	}
+	if !fn(projects) {
+		return nil
+	}
+	return save(projects)
+}
+
+// lock takes an exclusive lock on the registry, and returns the function that
+// releases it. The lock is on a separate file, since saving the registry
+// replaces its file.
+func lock() (unlock func(), err error) {
+	file, err := openLockFile()
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(chan error, 1)
+	go func() {
+		locked <- syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+	}()
+	select {
+	case err := <-locked:
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrapf(err, "failed to lock %s", file.Name())
+		}
+	case <-time.After(lockTimeout):
+		file.Close()
+		return nil, errors.Errorf("timed out after %s waiting for the lock on %s", lockTimeout, file.Name())
+	}
+	// Closing the file releases the lock.
+	return func() { file.Close() }, nil
+}
+
+// tryLock is like lock, but returns a nil unlock function instead of waiting
+// if the registry is already locked.
+func tryLock() (unlock func(), err error) {
+	file, err := openLockFile()
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		file.Close()
+		return nil, nil
+	}
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "failed to lock %s", file.Name())
+	}
+	return func() { file.Close() }, nil
+}
+
+func openLockFile() (*os.File, error) {
+	path := registryPath() + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	return file, errors.WithStack(err)
+}
+
+func load() (registry, error) {
+	projects := registry{}
+	data, err := os.ReadFile(registryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return projects, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", registryPath())
+	}
+	for dir, p := range projects {
+		if p == nil {
+			delete(projects, dir)
+		}
+	}
+	return projects, nil
+}
+
+func save(projects registry) error {
+	data, err := json.MarshalIndent(projects, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	path := registryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	// Write to a temporary file and rename it so that devbox commands
+	// running at the same time never read a partially written file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp.Name(), path))
+}
+
+func registryPath() string {
+	return xdg.StateSubpath(filepath.FromSlash("devbox/projects.json"))
+}
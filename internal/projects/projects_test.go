@@ -0,0 +1,154 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package projects
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+func TestRecord(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+
+	req.NoError(Record(dir, "hash1"))
+	list, err := List()
+	req.NoError(err)
+	req.Len(list, 1)
+	req.Equal(key(dir), list[0].Dir)
+	req.Equal("hash1", list[0].ConfigHash)
+	firstUse := list[0].LastUsed
+
+	// The last use isn't updated more than once per recordInterval, without
+	// waiting for the lock, but a changed config is always recorded.
+	unlock, err := lock()
+	req.NoError(err)
+	req.NoError(Record(dir, "hash1"))
+	unlock()
+	list, err = List()
+	req.NoError(err)
+	req.Equal(firstUse, list[0].LastUsed)
+	req.NoError(Record(dir, "hash2"))
+	list, err = List()
+	req.NoError(err)
+	req.Equal("hash2", list[0].ConfigHash)
+
+	// Relative paths and symlinks are the same project.
+	link := filepath.Join(t.TempDir(), "link")
+	req.NoError(os.Symlink(dir, link))
+	req.NoError(Record(link, "hash2"))
+	list, err = List()
+	req.NoError(err)
+	req.Len(list, 1)
+}
+
+func TestRecordConcurrently(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	root := t.TempDir()
+
+	// Devbox commands running at the same time record every project, instead
+	// of overwriting each other's registry.
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, Record(filepath.Join(root, fmt.Sprint(i)), "hash"))
+		}(i)
+	}
+	wg.Wait()
+
+	list, err := List()
+	require.NoError(t, err)
+	require.Len(t, list, 20)
+}
+
+func TestTryRecord(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+
+	// The shell hook doesn't wait for another devbox command that is
+	// updating the registry.
+	unlock, err := lock()
+	req.NoError(err)
+	req.NoError(TryRecord(dir, "hash"))
+	list, err := List()
+	req.NoError(err)
+	req.Empty(list)
+
+	unlock()
+	req.NoError(TryRecord(dir, "hash"))
+	list, err = List()
+	req.NoError(err)
+	req.Len(list, 1)
+}
+
+func TestListOrder(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	now := time.Now().UTC()
+	req.NoError(save(registry{
+		"/b":   {LastUsed: now},
+		"/old": {LastUsed: now.Add(-time.Hour)},
+		"/a":   {LastUsed: now},
+	}))
+
+	list, err := List()
+	req.NoError(err)
+	dirs := []string{}
+	for _, p := range list {
+		dirs = append(dirs, p.Dir)
+	}
+	req.Equal([]string{"/a", "/b", "/old"}, dirs)
+}
+
+func TestSetServicesRunning(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	dir := t.TempDir()
+
+	// Projects that aren't in the registry are ignored.
+	req.NoError(SetServicesRunning(dir, true))
+	list, err := List()
+	req.NoError(err)
+	req.Empty(list)
+
+	req.NoError(Record(dir, "hash"))
+	req.NoError(SetServicesRunning(dir, true))
+	list, err = List()
+	req.NoError(err)
+	req.True(list[0].ServicesRunning)
+	req.NoError(SetServicesRunning(dir, false))
+	list, err = List()
+	req.NoError(err)
+	req.False(list[0].ServicesRunning)
+}
+
+func TestPrune(t *testing.T) {
+	req := require.New(t)
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	kept, pruned := t.TempDir(), t.TempDir()
+	req.NoError(Record(kept, "hash"))
+	req.NoError(Record(pruned, "hash"))
+
+	removed, err := Prune(func(p *Project) bool { return p.Dir == key(kept) })
+	req.NoError(err)
+	req.Len(removed, 1)
+	req.Equal(key(pruned), removed[0].Dir)
+
+	list, err := List()
+	req.NoError(err)
+	req.Len(list, 1)
+	req.Equal(key(kept), list[0].Dir)
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/pullbox/git"
+)
+
+// Puller fetches a global config from where it is stored.
+type Puller interface {
+	// Pull returns a directory with the config's files, or the path of a
+	// single config file. Pulls into temporary files are removed by calling
+	// cleanup once the caller is done with them.
+	Pull(ctx context.Context) (path string, cleanup func(), err error)
+}
+
+// Pusher stores the files of a global config.
+type Pusher interface {
+	Push(ctx context.Context, dir string) error
+}
+
+// backend is where a global config is stored. Backends are chosen by the
+// scheme of the URL that is pushed to or pulled from.
+type backend interface {
+	Puller
+	Pusher
+}
+
+// newBackend returns the backend for rawURL:
+//
+//   - "" is the user's devbox cloud profile
+//   - git@host:repo, ssh://, https://...git and git+ URLs are git
+//     repositories, see git.ParseURL
+//   - s3:// URLs are objects in any S3-compatible store
+//   - http:// and https:// URLs are plain HTTP servers that support GET and PUT
+//   - file:// URLs and paths are local files or directories
+func newBackend(rawURL, profile string) (backend, error) {
+	if rawURL == "" {
+		return &cloudBackend{profile: profile}, nil
+	}
+	if git.IsRepoURL(rawURL) {
+		return newGitBackend(rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// Paths without a scheme, including Windows paths such as C:\ whose
+		// drive letter parses as a scheme, are local.
+		return &localBackend{path: rawURL}, nil
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "s3":
+		return newS3Backend(u, profile)
+	case "http", "https":
+		return &httpBackend{url: rawURL}, nil
+	case "file":
+		return &localBackend{path: u.Path}, nil
+	}
+	return nil, usererr.New("Unsupported URL scheme %q in %s", u.Scheme, rawURL)
+}
+
+// pulledToTmp returns the result of pulling into the temporary dir with
+// the function that removes it.
+func pulledToTmp(dir string, err error) (string, func(), error) {
+	if err != nil {
+		return "", nil, err
+	}
+	return dir, func() { os.RemoveAll(dir) }, nil
+}
+
+// noCleanup is the cleanup function for a Pull that didn't create files.
+func noCleanup() {}
@@ -0,0 +1,131 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/pullbox/git"
+)
+
+func TestNewBackend(t *testing.T) {
+	tests := []struct {
+		url  string
+		want backend
+	}{
+		{"", &cloudBackend{profile: "work"}},
+		{"git@github.com:user/config.git", &gitBackend{repo: &git.Repo{URL: "git@github.com:user/config.git"}}},
+		{"https://github.com/user/config.git", &gitBackend{repo: &git.Repo{URL: "https://github.com/user/config.git"}}},
+		{
+			"https://github.com/user/dotfiles.git?ref=main&dir=devbox",
+			&gitBackend{repo: &git.Repo{URL: "https://github.com/user/dotfiles.git", Ref: "main", Dir: "devbox"}},
+		},
+		{"git+file:///tmp/config", &gitBackend{repo: &git.Repo{URL: "file:///tmp/config"}}},
+		{"https://example.com/config.tar.gz", &httpBackend{url: "https://example.com/config.tar.gz"}},
+		{"file:///tmp/config", &localBackend{path: "/tmp/config"}},
+		{"/tmp/config.tar.gz", &localBackend{path: "/tmp/config.tar.gz"}},
+		{"relative/dir", &localBackend{path: "relative/dir"}},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			got, err := newBackend(test.url, "work")
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+
+	got, err := newBackend("s3://bucket/configs?endpoint=http://localhost:9000", "work")
+	require.NoError(t, err)
+	require.IsType(t, &s3Backend{}, got)
+
+	_, err = newBackend("ftp://example.com/config", "work")
+	require.Error(t, err)
+}
+
+func TestLocalBackend(t *testing.T) {
+	ctx := context.Background()
+	src := newConfigDir(t)
+
+	for _, target := range []string{"dir", "config.tar.gz"} {
+		t.Run(target, func(t *testing.T) {
+			b := &localBackend{path: filepath.Join(t.TempDir(), target)}
+			require.NoError(t, b.Push(ctx, src))
+			pulled, cleanup, err := b.Pull(ctx)
+			require.NoError(t, err)
+			requireConfigDir(t, pulled)
+
+			// Only archives are extracted to a temporary directory.
+			cleanup()
+			if target == "dir" {
+				require.DirExists(t, pulled)
+			} else {
+				require.FileExists(t, b.path)
+				require.NoDirExists(t, pulled)
+			}
+		})
+	}
+}
+
+func TestHTTPBackend(t *testing.T) {
+	ctx := context.Background()
+	mu := sync.Mutex{}
+	stored := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			stored[r.URL.Path] = data
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := stored[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			// Some servers don't set a content type for archives.
+			w.Header().Set("Content-Type", "")
+			_, _ = w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	b := &httpBackend{url: server.URL + "/profiles/work.tar.gz"}
+	require.NoError(t, b.Push(ctx, newConfigDir(t)))
+	pulled, cleanup, err := b.Pull(ctx)
+	require.NoError(t, err)
+	requireConfigDir(t, pulled)
+	cleanup()
+	require.NoDirExists(t, pulled)
+
+	_, _, err = (&httpBackend{url: server.URL + "/missing.tar.gz"}).Pull(ctx)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func newConfigDir(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "devbox.json"), []byte(`{"packages": ["go@1.21"]}`), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "scripts"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scripts", "setup.sh"), []byte("echo hi"), 0o644))
+	return dir
+}
+
+func requireConfigDir(t *testing.T, dir string) {
+	data, err := os.ReadFile(filepath.Join(dir, "devbox.json"))
+	require.NoError(t, err)
+	require.Equal(t, `{"packages": ["go@1.21"]}`, string(data))
+	require.FileExists(t, filepath.Join(dir, "scripts", "setup.sh"))
+}
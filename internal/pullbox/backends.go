@@ -0,0 +1,86 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"go.jetpack.io/devbox/internal/auth"
+	"go.jetpack.io/devbox/internal/pullbox/git"
+	"go.jetpack.io/devbox/internal/pullbox/s3"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+// cloudBackend stores the config in the user's devbox cloud profile.
+type cloudBackend struct {
+	profile string
+}
+
+func (b *cloudBackend) Pull(ctx context.Context) (string, func(), error) {
+	user, err := auth.GetUser()
+	if err != nil {
+		return "", nil, err
+	}
+	return pulledToTmp(s3.PullToTmp(ctx, user, b.profile))
+}
+
+func (b *cloudBackend) Push(ctx context.Context, dir string) error {
+	user, err := auth.GetUser()
+	if err != nil {
+		return err
+	}
+	ux.Finfo(
+		os.Stderr,
+		"Logged in as %s, pushing to to devbox cloud (profile: %s)\n",
+		user.Email(),
+		b.profile,
+	)
+	return s3.Push(ctx, user, dir, b.profile)
+}
+
+// gitBackend stores the config in a git repository, optionally in a branch
+// or subdirectory of it.
+type gitBackend struct {
+	repo *git.Repo
+}
+
+func newGitBackend(rawURL string) (*gitBackend, error) {
+	repo, err := git.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &gitBackend{repo: repo}, nil
+}
+
+func (b *gitBackend) Pull(ctx context.Context) (string, func(), error) {
+	return git.Pull(ctx, b.repo)
+}
+
+func (b *gitBackend) Push(ctx context.Context, dir string) error {
+	return git.Push(ctx, dir, b.repo)
+}
+
+// s3Backend stores the config as an archive in an S3-compatible store, using
+// the credentials from the environment.
+type s3Backend struct {
+	location *s3.Location
+}
+
+func newS3Backend(u *url.URL, profile string) (*s3Backend, error) {
+	location, err := s3.ParseURL(u, profile)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Backend{location: location}, nil
+}
+
+func (b *s3Backend) Pull(ctx context.Context) (string, func(), error) {
+	return pulledToTmp(s3.PullFrom(ctx, b.location))
+}
+
+func (b *s3Backend) Push(ctx context.Context, dir string) error {
+	return s3.PushTo(ctx, b.location, dir)
+}
@@ -5,46 +5,17 @@ package pullbox
 
 import (
 	"net/url"
-	"os"
 	"path/filepath"
 
 	"go.jetpack.io/devbox/internal/cuecfg"
-	"go.jetpack.io/devbox/internal/devconfig"
-	"go.jetpack.io/devbox/internal/fileutil"
 )
 
-func (p *pullbox) IsTextDevboxConfig() bool {
-	if u, err := url.Parse(p.url); err == nil {
+func isTextDevboxConfig(rawURL string) bool {
+	if u, err := url.Parse(rawURL); err == nil {
 		ext := filepath.Ext(u.Path)
 		return cuecfg.IsSupportedExtension(ext)
 	}
 	// For invalid URLS, just look at the extension
-	ext := filepath.Ext(p.url)
+	ext := filepath.Ext(rawURL)
 	return cuecfg.IsSupportedExtension(ext)
 }
-
-func (p *pullbox) pullTextDevboxConfig() error {
-	if p.isLocalConfig() {
-		return p.copyToProfile(p.url)
-	}
-
-	cfg, err := devconfig.LoadConfigFromURL(p.url)
-	if err != nil {
-		return err
-	}
-
-	tmpDir, err := fileutil.CreateDevboxTempDir()
-	if err != nil {
-		return err
-	}
-	if err = cfg.SaveTo(tmpDir); err != nil {
-		return err
-	}
-
-	return p.copyToProfile(tmpDir)
-}
-
-func (p *pullbox) isLocalConfig() bool {
-	_, err := os.Stat(p.url)
-	return err == nil
-}
@@ -4,27 +4,45 @@
 package pullbox
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
-// Download downloads a file from the specified URL
-func download(url string) ([]byte, error) {
-	response, err := http.Get(url)
+// httpClient is the client for pulling and pushing configs over HTTP. Its
+// timeout keeps an unresponsive server from hanging devbox.
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+// download downloads a file from the specified URL and returns it with its
+// content type. If the server doesn't have the file, the error wraps
+// fs.ErrNotExist.
+func download(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", errors.WithStack(err)
+	}
+	response, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotFound {
+		return nil, "", fmt.Errorf("failed to download file: %s: %w", response.Status, fs.ErrNotExist)
+	}
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download file: %s", response.Status)
+		return nil, "", fmt.Errorf("failed to download file: %s", response.Status)
 	}
 
 	data, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return data, nil
+	return data, response.Header.Get("Content-Type"), nil
 }
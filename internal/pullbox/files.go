@@ -5,15 +5,14 @@ package pullbox
 
 import (
 	"io/fs"
-	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/pkg/errors"
 	"go.jetpack.io/devbox/internal/cmdutil"
 	"go.jetpack.io/devbox/internal/devconfig"
 	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/pullbox/sign"
 )
 
 func (p *pullbox) copyToProfile(src string) error {
@@ -44,6 +43,11 @@ func (p *pullbox) copyToProfile(src string) error {
 	}
 
 	for _, srcFile := range srcFiles {
+		// The signature was verified when pulling, and is only valid for
+		// the pulled files.
+		if srcFile.Name() == sign.SignatureFile && srcFileInfo.IsDir() {
+			continue
+		}
 		srcPath := src
 		if srcFileInfo.IsDir() {
 			srcPath = filepath.Join(src, srcFile.Name())
@@ -62,7 +66,7 @@ func profileIsNotEmpty(path string) (bool, error) {
 		return false, errors.WithStack(err)
 	}
 	for _, entry := range entries {
-		if entry.Name() != devconfig.DefaultName ||
+		if !devconfig.IsConfigName(entry.Name()) ||
 			isModifiedConfig(filepath.Join(path, entry.Name())) {
 			return true, nil
 		}
@@ -71,7 +75,7 @@ func profileIsNotEmpty(path string) (bool, error) {
 }
 
 func isModifiedConfig(path string) bool {
-	if filepath.Base(path) == devconfig.DefaultName {
+	if devconfig.IsConfigName(filepath.Base(path)) {
 		cfg, err := devconfig.Load(path)
 		if err != nil {
 			return false
@@ -80,16 +84,3 @@ func isModifiedConfig(path string) bool {
 	}
 	return false
 }
-
-// urlIsArchive checks if a file URL points to an archive file
-func urlIsArchive(url string) (bool, error) {
-	response, err := http.Head(url)
-	if err != nil {
-		return false, err
-	}
-	defer response.Body.Close()
-	contentType := response.Header.Get("Content-Type")
-	return strings.Contains(contentType, "tar") ||
-		strings.Contains(contentType, "zip") ||
-		strings.Contains(contentType, "octet-stream"), nil
-}
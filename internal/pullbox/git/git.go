@@ -4,35 +4,102 @@
 package git
 
 import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/trace"
 	"strings"
 
 	"github.com/pkg/errors"
 
-	"go.jetpack.io/devbox/internal/cmdutil"
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
 	"go.jetpack.io/devbox/internal/fileutil"
 )
 
-func CloneToTmp(repo string) (string, error) {
+// Pull clones the repository and returns the directory that holds the
+// config, and the function that removes the clone. If the repository doesn't
+// have the ref or the directory of the config yet, the error wraps
+// fs.ErrNotExist.
+func Pull(ctx context.Context, repo *Repo) (string, func(), error) {
+	defer trace.StartRegion(ctx, "Pull").End()
+
+	tmpDir, err := CloneToTmp(repo)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+	if repo.Dir != "" {
+		dir := repo.configDir(tmpDir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			cleanup()
+			return "", nil, usererr.WithUserMessage(
+				fs.ErrNotExist, "Directory %s not found in %s", repo.Dir, repo.URL)
+		}
+		return dir, cleanup, nil
+	}
+	// Remove the .git directory, we don't want to keep state
+	if err := os.RemoveAll(filepath.Join(tmpDir, ".git")); err != nil {
+		cleanup()
+		return "", nil, errors.WithStack(err)
+	}
+	return tmpDir, cleanup, nil
+}
+
+// CloneToTmp clones the repository into a temporary directory, and checks out
+// its ref or rev.
+func CloneToTmp(repo *Repo) (string, error) {
 	tmpDir, err := fileutil.CreateDevboxTempDir()
 	if err != nil {
 		return "", err
 	}
 
 	if err := clone(repo, tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
 		return "", err
 	}
+	if repo.Rev != "" {
+		cmd := repo.command(tmpDir, "checkout", "--quiet", repo.Rev)
+		if err := cmd.Run(); err != nil {
+			os.RemoveAll(tmpDir)
+			return "", usererr.WithUserMessage(
+				err, "Could not check out commit %s of %s", repo.Rev, repo.URL)
+		}
+	}
 	return tmpDir, nil
 }
 
 func IsRepoURL(url string) bool {
-	// For now only support ssh
+	if strings.HasPrefix(url, "git+") {
+		return true
+	}
+	url, _, _ = strings.Cut(url, "?")
 	return strings.HasPrefix(url, "git@") ||
+		strings.HasPrefix(url, "ssh://") ||
 		(strings.HasPrefix(url, "https://") && strings.HasSuffix(url, ".git"))
 }
 
-func clone(repo, dir string) error {
-	cmd := cmdutil.CommandTTY("git", "clone", repo, dir)
-	cmd.Dir = dir
+func clone(repo *Repo, dir string) error {
+	args := []string{"clone"}
+	if repo.Ref != "" {
+		args = append(args, "--branch", repo.Ref)
+	}
+	cmd := repo.command(dir, append(args, repo.URL, dir)...)
 	err := cmd.Run()
+	if err != nil && repo.Ref != "" && !hasBranch(repo, dir) {
+		return usererr.WithUserMessage(
+			fs.ErrNotExist, "Branch %s not found in %s", repo.Ref, repo.URL)
+	}
 	return errors.WithStack(err)
 }
+
+// hasBranch reports whether the repository has the branch repo.Ref. It's only
+// false if git could reach the repository and the branch isn't there.
+func hasBranch(repo *Repo, dir string) bool {
+	cmd := repo.command(dir, "ls-remote", "--exit-code", "--heads", repo.URL, repo.Ref)
+	cmd.Stdout = nil
+	var exitErr *exec.ExitError
+	// ls-remote exits with 2 when no refs match.
+	return !errors.As(cmd.Run(), &exitErr) || exitErr.ExitCode() != 2
+}
@@ -5,55 +5,111 @@ package git
 
 import (
 	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
 	"runtime/trace"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
 	"go.jetpack.io/devbox/internal/cmdutil"
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/debug"
+	"go.jetpack.io/devbox/internal/devconfig"
 	"go.jetpack.io/devbox/internal/fileutil"
 )
 
 const nothingToCommitErrorText = "nothing to commit"
 
-func Push(ctx context.Context, dir, url string) error {
+// Push commits the files in dir to the repository's config directory, and
+// pushes them to its ref. The rest of the repository is left as it is.
+func Push(ctx context.Context, dir string, repo *Repo) error {
 	defer trace.StartRegion(ctx, "Push").End()
 
+	if repo.Rev != "" {
+		return usererr.New("Can't push to a pinned commit. Remove rev from the git URL.")
+	}
+
 	tmpDir, err := fileutil.CreateDevboxTempDir()
 	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(tmpDir)
 
-	if err := cloneGitHistory(url, tmpDir); err != nil {
+	if err := errors.WithStack(repo.command(tmpDir, "clone", repo.URL, tmpDir).Run()); err != nil {
 		return err
 	}
+	if repo.Ref != "" {
+		if err := checkoutBranch(repo, tmpDir); err != nil {
+			return err
+		}
+	}
 
-	if err := fileutil.CopyAll(dir, tmpDir); err != nil {
+	if err := clearConfigDir(repo, tmpDir); err != nil {
+		return err
+	}
+	if err := fileutil.CopyAll(dir, repo.configDir(tmpDir)); err != nil {
 		return err
 	}
 
-	if err := createCommit(tmpDir); err != nil {
+	if err := createCommit(repo, tmpDir); err != nil {
 		return err
 	}
 
-	return push(tmpDir)
+	return push(repo, tmpDir)
 }
 
-func cloneGitHistory(url, dst string) error {
-	// See https://stackoverflow.com/questions/38999901/clone-only-the-git-directory-of-a-git-repo
-	cmd := cmdutil.CommandTTY("git", "clone", "--no-checkout", url, dst)
-	cmd.Dir = dst
-	return errors.WithStack(cmd.Run())
+// checkoutBranch checks out the repository's ref, creating the branch if it
+// doesn't exist yet.
+func checkoutBranch(repo *Repo, dir string) error {
+	verify := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+repo.Ref)
+	verify.Dir = dir
+	// The remote branch is named explicitly, since a bare "checkout <ref>"
+	// is ambiguous if the repository also has a file named ref.
+	args := []string{"checkout", "--quiet", "-B", repo.Ref, "origin/" + repo.Ref}
+	if verify.Run() != nil {
+		args = []string{"checkout", "--quiet", "-b", repo.Ref}
+	}
+	return errors.WithStack(repo.command(dir, args...).Run())
+}
+
+// clearConfigDir removes the files of the previous config, so that files
+// that were deleted locally are deleted from the repository too. At the root
+// of the repository, the .git directory is kept.
+func clearConfigDir(repo *Repo, root string) error {
+	if repo.Dir != "" {
+		return fileutil.ClearDir(repo.configDir(root))
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, entry.Name())); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
 }
 
-func createCommit(dir string) error {
-	cmd := cmdutil.CommandTTY("git", "add", ".")
+func createCommit(repo *Repo, dir string) error {
+	pathspec := "."
+	if repo.Dir != "" {
+		pathspec = repo.Dir
+	}
+	cmd := cmdutil.CommandTTY("git", "add", "--all", "--", pathspec)
 	cmd.Dir = dir
 	if err := cmd.Run(); err != nil {
 		return errors.WithStack(err)
 	}
 	cmd, buf := cmdutil.CommandTTYWithBuffer(
-		"git", "commit", "-m", "devbox commit")
+		"git", "commit", "-m", commitMessage(repo, dir))
 	cmd.Dir = dir
 	err := cmd.Run()
 	if strings.Contains(buf.String(), nothingToCommitErrorText) {
@@ -62,9 +118,40 @@ func createCommit(dir string) error {
 	return errors.WithStack(err)
 }
 
-func push(dir string) error {
-	cmd := cmdutil.CommandTTY("git", "push")
-	cmd.Dir = dir
+func push(repo *Repo, dir string) error {
+	cmd := repo.command(dir, "push", "origin", "HEAD")
 	err := cmd.Run()
 	return errors.WithStack(err)
 }
+
+// commitMessage describes the packages and env that changed in the repo's
+// devbox config since the last commit.
+func commitMessage(repo *Repo, dir string) string {
+	previous := &devconfig.Config{}
+	// The repo may be empty or not have a config yet, in which case every
+	// package is new. The config may also have been in another format.
+	for _, name := range devconfig.FileNames {
+		configPath := path.Join(repo.Dir, name)
+		cmd := exec.Command("git", "show", "HEAD:"+configPath)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		if err := cuecfg.UnmarshalByJSON(out, path.Ext(name), previous); err != nil {
+			debug.Log("Error parsing previous %s: %v", configPath, err)
+		}
+		break
+	}
+
+	var current *devconfig.Config
+	configPath, err := devconfig.Find(repo.configDir(dir))
+	if err == nil {
+		current, err = devconfig.Load(configPath)
+	}
+	if err != nil {
+		debug.Log("Error loading the devbox config in %s: %v", repo.configDir(dir), err)
+		current = previous
+	}
+	return devconfig.DiffConfigs(previous, current).CommitMessage()
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+)
+
+func TestPushCommitMessage(t *testing.T) {
+	ctx := context.Background()
+	repo := &Repo{URL: newBareRepo(t)}
+
+	dir := t.TempDir()
+	cfg := &devconfig.Config{Packages: []string{"go@1.20", "ripgrep"}}
+	require.NoError(t, cfg.SaveTo(dir))
+	require.NoError(t, Push(ctx, dir, repo))
+	require.Equal(t,
+		"devbox: add go@1.20, ripgrep\n\n+ go@1.20\n+ ripgrep",
+		runGit(t, repo.URL, "log", "-1", "--format=%B"),
+	)
+
+	cfg.Packages = []string{"go@1.21", "ripgrep"}
+	require.NoError(t, cfg.SaveTo(dir))
+	require.NoError(t, Push(ctx, dir, repo))
+	require.Equal(t,
+		"devbox: add go@1.21; remove go@1.20\n\n+ go@1.21\n- go@1.20",
+		runGit(t, repo.URL, "log", "-1", "--format=%B"),
+	)
+
+	// Configs in other formats are compared too, even if the format changed.
+	require.NoError(t, os.Remove(filepath.Join(dir, "devbox.json")))
+	writeFile(t, filepath.Join(dir, "devbox.yaml"), "packages:\n  - go@1.21\n  - ripgrep\n  - jq\n")
+	require.NoError(t, Push(ctx, dir, repo))
+	require.Equal(t,
+		"devbox: add jq\n\n+ jq",
+		runGit(t, repo.URL, "log", "-1", "--format=%B"),
+	)
+}
+
+func TestPushSubdirectory(t *testing.T) {
+	ctx := context.Background()
+	bare := newBareRepo(t)
+
+	// A dotfiles repo with other configs on its main branch.
+	work := t.TempDir()
+	runGit(t, "", "clone", bare, work)
+	runGit(t, work, "checkout", "-b", "main")
+	writeFile(t, filepath.Join(work, "README.md"), "dotfiles")
+	writeFile(t, filepath.Join(work, "vim", "vimrc"), "set number")
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-m", "Add dotfiles")
+	runGit(t, work, "push", "origin", "main")
+
+	repo := &Repo{URL: bare, Ref: "main", Dir: "devbox"}
+	dir := t.TempDir()
+	require.NoError(t, (&devconfig.Config{Packages: []string{"go@1.20"}}).SaveTo(dir))
+	writeFile(t, filepath.Join(dir, "scripts", "setup.sh"), "echo hi")
+	require.NoError(t, Push(ctx, dir, repo))
+	pinned := runGit(t, bare, "rev-parse", "main")
+
+	// Files deleted locally are deleted from the subdirectory, and the rest
+	// of the repo is left as it is.
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "scripts")))
+	require.NoError(t, (&devconfig.Config{Packages: []string{"go@1.21"}}).SaveTo(dir))
+	require.NoError(t, Push(ctx, dir, repo))
+	require.Equal(t,
+		"README.md\ndevbox/devbox.json\nvim/vimrc",
+		runGit(t, bare, "ls-tree", "-r", "--name-only", "main"),
+	)
+
+	pulled, cleanup, err := Pull(ctx, repo)
+	require.NoError(t, err)
+	requirePackages(t, pulled, "go@1.21")
+	require.NoFileExists(t, filepath.Join(pulled, "scripts", "setup.sh"))
+	cleanup()
+	require.NoDirExists(t, pulled)
+
+	// Pulls can be pinned to a commit, but pushes can't.
+	pinnedRepo := &Repo{URL: bare, Ref: "main", Dir: "devbox", Rev: pinned}
+	pulled, cleanup, err = Pull(ctx, pinnedRepo)
+	require.NoError(t, err)
+	defer cleanup()
+	requirePackages(t, pulled, "go@1.20")
+	require.FileExists(t, filepath.Join(pulled, "scripts", "setup.sh"))
+	require.Error(t, Push(ctx, dir, pinnedRepo))
+
+	// Pushing to a new branch creates it.
+	require.NoError(t, Push(ctx, dir, &Repo{URL: bare, Ref: "laptop", Dir: "devbox"}))
+	pulled, cleanup, err = Pull(ctx, &Repo{URL: bare, Ref: "laptop", Dir: "devbox"})
+	require.NoError(t, err)
+	defer cleanup()
+	requirePackages(t, pulled, "go@1.21")
+
+	// Nothing has been pushed to a missing directory or branch yet.
+	_, _, err = Pull(ctx, &Repo{URL: bare, Ref: "main", Dir: "missing"})
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	_, _, err = Pull(ctx, &Repo{URL: bare, Ref: "missing", Dir: "devbox"})
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestPushBranchNamedLikeFile(t *testing.T) {
+	ctx := context.Background()
+	bare := newBareRepo(t)
+	work := t.TempDir()
+	runGit(t, "", "clone", bare, work)
+	writeFile(t, filepath.Join(work, "devbox", "devbox.json"), "{}")
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-m", "Add devbox directory")
+	runGit(t, work, "push", "origin", "HEAD")
+
+	repo := &Repo{URL: bare, Ref: "devbox"}
+	dir := t.TempDir()
+	for _, pkg := range []string{"go@1.20", "go@1.21"} {
+		require.NoError(t, (&devconfig.Config{Packages: []string{pkg}}).SaveTo(dir))
+		require.NoError(t, Push(ctx, dir, repo))
+	}
+	pulled, cleanup, err := Pull(ctx, repo)
+	require.NoError(t, err)
+	defer cleanup()
+	requirePackages(t, pulled, "go@1.21")
+}
+
+func newBareRepo(t *testing.T) string {
+	t.Setenv("GIT_AUTHOR_NAME", "devbox")
+	t.Setenv("GIT_AUTHOR_EMAIL", "devbox@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "devbox")
+	t.Setenv("GIT_COMMITTER_EMAIL", "devbox@example.com")
+	repo := filepath.Join(t.TempDir(), "config.git")
+	runGit(t, "", "init", "--bare", repo)
+	return repo
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	require.NoError(t, err, "git %s", strings.Join(args, " "))
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func requirePackages(t *testing.T, dir string, pkgs ...string) {
+	cfg, err := devconfig.Load(filepath.Join(dir, devconfig.DefaultName))
+	require.NoError(t, err)
+	require.Equal(t, pkgs, cfg.Packages)
+}
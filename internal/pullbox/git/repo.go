@@ -0,0 +1,135 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package git
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/cmdutil"
+)
+
+// Repo is a git repository that stores a global config, and where in the
+// repository the config is.
+type Repo struct {
+	// URL is the repository to clone.
+	URL string
+	// Ref is the branch to pull from and push to. It defaults to the
+	// repository's default branch.
+	Ref string
+	// Rev pins pulls to a commit.
+	Rev string
+	// Dir is the subdirectory of the repository that holds the config, in
+	// slash-separated form. It defaults to the root of the repository.
+	Dir string
+	// SSHKey is the path of a private key to authenticate with, instead of
+	// the keys of the ssh agent.
+	SSHKey string
+}
+
+// ParseURL parses a repository URL. Like the git URLs of nix flakes, it
+// accepts the query parameters ref, rev and dir, as well as ssh_key:
+//
+//	git@github.com:user/dotfiles.git?ref=main&dir=devbox
+//
+// A git+ prefix, as in git+file:///path/to/repo, marks URLs that are git
+// repositories but don't look like one.
+func ParseURL(rawURL string) (*Repo, error) {
+	rawURL = strings.TrimPrefix(rawURL, "git+")
+	repoURL, rawQuery, _ := strings.Cut(rawURL, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, usererr.WithUserMessage(err, "Invalid git URL %s", rawURL)
+	}
+	repo := &Repo{URL: repoURL}
+	for key := range query {
+		value := query.Get(key)
+		switch key {
+		case "ref":
+			repo.Ref = value
+		case "rev":
+			repo.Rev = value
+		case "dir":
+			repo.Dir = value
+		case "ssh_key":
+			repo.SSHKey = value
+		default:
+			return nil, usererr.New(
+				"Unknown option %q in git URL %s. Supported options are ref, rev, dir and ssh_key.",
+				key, rawURL)
+		}
+	}
+	return repo, repo.validate()
+}
+
+func (r *Repo) validate() error {
+	if r.Dir == "" {
+		return nil
+	}
+	dir := path.Clean(r.Dir)
+	if path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+		return usererr.New("The git directory %q must be relative to the root of the repository", r.Dir)
+	}
+	if dir == "." {
+		dir = ""
+	}
+	r.Dir = dir
+	return nil
+}
+
+// String returns the URL of the repository with its options as query
+// parameters, in the form accepted by ParseURL.
+func (r *Repo) String() string {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"ref": r.Ref, "rev": r.Rev, "dir": r.Dir, "ssh_key": r.SSHKey,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if len(query) == 0 {
+		return r.URL
+	}
+	return r.URL + "?" + query.Encode()
+}
+
+// configDir returns the directory of the config in a clone of the repository
+// at root.
+func (r *Repo) configDir(root string) string {
+	return filepath.Join(root, filepath.FromSlash(r.Dir))
+}
+
+// command returns a git command that runs in dir and authenticates with the
+// repository's ssh key.
+func (r *Repo) command(dir string, args ...string) *exec.Cmd {
+	cmd := cmdutil.CommandTTY("git", args...)
+	cmd.Dir = dir
+	if r.SSHKey != "" {
+		cmd.Env = append(os.Environ(), "GIT_SSH_COMMAND=ssh -i "+
+			shellQuote(expandHome(r.SSHKey))+" -o IdentitiesOnly=yes")
+	}
+	return cmd
+}
+
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
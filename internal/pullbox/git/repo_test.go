@@ -0,0 +1,58 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want *Repo
+	}{
+		{"git@github.com:user/config.git", &Repo{URL: "git@github.com:user/config.git"}},
+		{
+			"git@github.com:user/dotfiles.git?ref=main&dir=devbox/",
+			&Repo{URL: "git@github.com:user/dotfiles.git", Ref: "main", Dir: "devbox"},
+		},
+		{
+			"https://github.com/user/dotfiles.git?rev=0a1b2c&ssh_key=~/.ssh/deploy",
+			&Repo{URL: "https://github.com/user/dotfiles.git", Rev: "0a1b2c", SSHKey: "~/.ssh/deploy"},
+		},
+		{"git+file:///tmp/config?dir=.", &Repo{URL: "file:///tmp/config"}},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			got, err := ParseURL(test.url)
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+
+			// String returns a URL that parses to the same repo.
+			again, err := ParseURL(got.String())
+			require.NoError(t, err)
+			require.Equal(t, got, again)
+		})
+	}
+
+	for _, url := range []string{
+		"git@github.com:user/config.git?branch=main",
+		"git@github.com:user/config.git?dir=../other",
+		"git@github.com:user/config.git?dir=/etc",
+	} {
+		_, err := ParseURL(url)
+		require.Error(t, err, url)
+	}
+}
+
+func TestIsRepoURL(t *testing.T) {
+	require.True(t, IsRepoURL("git@github.com:user/config.git"))
+	require.True(t, IsRepoURL("ssh://git@example.com/config"))
+	require.True(t, IsRepoURL("https://github.com/user/config.git?ref=main"))
+	require.True(t, IsRepoURL("git+file:///tmp/config"))
+	require.False(t, IsRepoURL("https://example.com/config.tar.gz"))
+	require.False(t, IsRepoURL("/tmp/config"))
+}
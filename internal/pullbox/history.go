@@ -0,0 +1,111 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/xdg"
+)
+
+// maxPulledHistory is the number of pulled configs that are kept per remote.
+const maxPulledHistory = 20
+
+const historyTimeFormat = "20060102T150405.000000000Z"
+
+// history is the local record of syncs with a remote. It keeps the configs
+// that were pulled from the remote, and the base: the config that the last
+// sync left both sides with, which the next sync merges against.
+type history struct {
+	dir string
+}
+
+// newHistory returns the history of syncing the global profile with the
+// remote at url. Each remote has its own history so that syncing with a
+// different remote doesn't merge against an unrelated base.
+func newHistory(profile, url string) *history {
+	sum := sha256.Sum256([]byte(url))
+	name := profile + "-" + hex.EncodeToString(sum[:])[:12]
+	return &history{dir: xdg.StateSubpath(filepath.Join("devbox", "global-sync", name))}
+}
+
+// base returns the config of the last sync, or nil if there hasn't been one.
+func (h *history) base() (*devconfig.Config, error) {
+	cfg := &devconfig.Config{}
+	err := cuecfg.ParseFile(h.basePath(), cfg)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return cfg, errors.WithStack(err)
+}
+
+func (h *history) setBase(cfg *devconfig.Config) error {
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+	return cuecfg.WriteFile(h.basePath(), cfg)
+}
+
+// recordPull saves a config pulled from the remote and removes the oldest
+// ones beyond maxPulledHistory.
+func (h *history) recordPull(cfg *devconfig.Config) error {
+	dir := h.pulledDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+	name := time.Now().UTC().Format(historyTimeFormat) + ".json"
+	if err := cuecfg.WriteFile(filepath.Join(dir, name), cfg); err != nil {
+		return err
+	}
+
+	pulled, err := h.pulled()
+	if err != nil {
+		return err
+	}
+	for len(pulled) > maxPulledHistory {
+		if err := os.Remove(pulled[0]); err != nil {
+			return errors.WithStack(err)
+		}
+		pulled = pulled[1:]
+	}
+	return nil
+}
+
+// pulled returns the paths of the pulled configs, oldest first.
+func (h *history) pulled() ([]string, error) {
+	entries, err := os.ReadDir(h.pulledDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".json" {
+			paths = append(paths, filepath.Join(h.pulledDir(), entry.Name()))
+		}
+	}
+	// The names are timestamps, so sorting them sorts by time.
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (h *history) basePath() string {
+	return filepath.Join(h.dir, "base.json")
+}
+
+func (h *history) pulledDir() string {
+	return filepath.Join(h.dir, "pulled")
+}
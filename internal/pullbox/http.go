@@ -0,0 +1,95 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/pullbox/tar"
+)
+
+// httpBackend stores the config as an archive on an HTTP server that supports
+// GET and PUT. Credentials in the URL are sent with basic auth. Pulling also
+// supports URLs of a single devbox.json.
+type httpBackend struct {
+	url string
+}
+
+func (b *httpBackend) Pull(ctx context.Context) (string, func(), error) {
+	if isTextDevboxConfig(b.url) {
+		return pulledToTmp(pullTextDevboxConfig(b.url))
+	}
+
+	data, contentType, err := download(ctx, b.url)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isArchive(contentType, data) {
+		return "", nil, usererr.New("Could not determine how to pull %s", b.url)
+	}
+	return pulledToTmp(tar.Extract(data))
+}
+
+func (b *httpBackend) Push(ctx context.Context, dir string) error {
+	archivePath, err := tar.Compress(dir)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(filepath.Dir(archivePath))
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, b.url, bytes.NewReader(data))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return usererr.New("Failed to push to %s: %s", b.url, resp.Status)
+	}
+	return nil
+}
+
+func pullTextDevboxConfig(url string) (string, error) {
+	cfg, err := devconfig.LoadConfigFromURL(url)
+	if err != nil {
+		return "", err
+	}
+
+	tmpDir, err := fileutil.CreateDevboxTempDir()
+	if err != nil {
+		return "", err
+	}
+	if err := cfg.SaveTo(tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+	return tmpDir, nil
+}
+
+// isArchive checks if downloaded data is an archive, by its content type or,
+// for servers that don't set one, by the gzip magic number.
+func isArchive(contentType string, data []byte) bool {
+	return strings.Contains(contentType, "tar") ||
+		strings.Contains(contentType, "zip") ||
+		strings.Contains(contentType, "octet-stream") ||
+		bytes.HasPrefix(data, []byte{0x1f, 0x8b})
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/pullbox/tar"
+)
+
+// localBackend stores the config in a local directory, or in a local archive
+// if the path ends in .tar.gz or .tgz. Pulling also supports the path of a
+// single devbox.json.
+type localBackend struct {
+	path string
+}
+
+func (b *localBackend) Pull(ctx context.Context) (string, func(), error) {
+	info, err := os.Stat(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil, usererr.WithUserMessage(err, "Could not determine how to pull %s", b.path)
+	}
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	if info.IsDir() || isTextDevboxConfig(b.path) {
+		return b.path, noCleanup, nil
+	}
+	if !isArchivePath(b.path) {
+		return "", nil, usererr.New("Could not determine how to pull %s", b.path)
+	}
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	return pulledToTmp(tar.Extract(data))
+}
+
+func (b *localBackend) Push(ctx context.Context, dir string) error {
+	if isArchivePath(b.path) {
+		archivePath, err := tar.Compress(dir)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(filepath.Dir(archivePath))
+		if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+		data, err := os.ReadFile(archivePath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(os.WriteFile(b.path, data, 0644))
+	}
+
+	// The directory isn't cleared first, since the user may have pointed at
+	// a directory with other files in it.
+	if err := os.MkdirAll(b.path, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	return fileutil.CopyAll(dir, b.path)
+}
+
+func isArchivePath(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
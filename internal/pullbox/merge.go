@@ -0,0 +1,152 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/devpkg"
+)
+
+// merge does a three-way merge of the packages and env of the local and
+// remote configs, where base is the config they were last synced to. A change
+// made on only one side since base is kept, so packages added on either side
+// are added and packages removed on either side are removed. If both sides
+// changed the same package or variable differently, the local change wins and
+// is reported as a conflict.
+//
+// base is nil on the first sync, in which case the packages and env of both
+// sides are combined. All other fields are taken from local.
+func merge(base, local, remote *devconfig.Config) (*devconfig.Config, []string) {
+	merged := *local
+	var conflicts []string
+
+	basePkgs := packagesByName(base)
+	localPkgs := packagesByName(local)
+	remotePkgs := packagesByName(remote)
+	merged.Packages = []string{}
+	for _, name := range packageNames(local, remote) {
+		pkg, conflict := mergeValue(
+			lookup(basePkgs, name), lookup(localPkgs, name), lookup(remotePkgs, name))
+		if conflict {
+			conflicts = append(conflicts, fmt.Sprintf(
+				"package %s is %s locally and %s remotely, keeping the local change",
+				name, describePackage(localPkgs, name), describePackage(remotePkgs, name)))
+		}
+		if pkg.ok {
+			merged.Packages = append(merged.Packages, pkg.value)
+		}
+	}
+
+	var baseEnv map[string]string
+	if base != nil {
+		baseEnv = base.Env
+	}
+	merged.Env = map[string]string{}
+	for _, k := range envNames(local, remote) {
+		v, conflict := mergeValue(lookup(baseEnv, k), lookup(local.Env, k), lookup(remote.Env, k))
+		if conflict {
+			conflicts = append(conflicts, fmt.Sprintf(
+				"env %s is %s locally and %s remotely, keeping the local change",
+				k, describeEnv(local.Env, k), describeEnv(remote.Env, k)))
+		}
+		if v.ok {
+			merged.Env[k] = v.value
+		}
+	}
+	if len(merged.Env) == 0 {
+		merged.Env = nil
+	}
+	return &merged, conflicts
+}
+
+// entry is a package or env variable, which may not be set.
+type entry struct {
+	value string
+	ok    bool
+}
+
+func lookup(m map[string]string, key string) entry {
+	v, ok := m[key]
+	return entry{v, ok}
+}
+
+// mergeValue merges a package or env variable.
+func mergeValue(base, local, remote entry) (merged entry, conflict bool) {
+	switch {
+	case local == remote, remote == base:
+		return local, false
+	case local == base:
+		return remote, false
+	default:
+		return local, true
+	}
+}
+
+func describePackage(pkgs map[string]string, name string) string {
+	if pkg, ok := pkgs[name]; ok {
+		return pkg
+	}
+	return "removed"
+}
+
+func describeEnv(env map[string]string, k string) string {
+	if v, ok := env[k]; ok {
+		return fmt.Sprintf("%q", v)
+	}
+	return "unset"
+}
+
+// packagesByName maps the name of each package in cfg (e.g. `go` for
+// `go@1.21`) to the package as written, so that a version change is merged
+// as a change to the same package.
+func packagesByName(cfg *devconfig.Config) map[string]string {
+	pkgs := map[string]string{}
+	if cfg == nil {
+		return pkgs
+	}
+	for _, pkg := range cfg.Packages {
+		pkgs[packageName(pkg)] = pkg
+	}
+	return pkgs
+}
+
+// packageNames returns the names of the packages in local, in order, followed
+// by the names of packages that are only in remote.
+func packageNames(local, remote *devconfig.Config) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, pkg := range append(append([]string{}, local.Packages...), remote.Packages...) {
+		name := packageName(pkg)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func packageName(pkg string) string {
+	name, _ := devpkg.ParseOutputs(pkg)
+	name, _, _ = strings.Cut(name, "@")
+	return name
+}
+
+func envNames(local, remote *devconfig.Config) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, env := range []map[string]string{local.Env, remote.Env} {
+		for k := range env {
+			if !seen[k] {
+				seen[k] = true
+				names = append(names, k)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
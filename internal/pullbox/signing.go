@@ -0,0 +1,87 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/cmdutil"
+	"go.jetpack.io/devbox/internal/debug"
+	"go.jetpack.io/devbox/internal/fileutil"
+	"go.jetpack.io/devbox/internal/pullbox/sign"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+// localStateDir is the directory of a devbox project that devbox generates.
+// It isn't part of signed bundles, since it differs on each machine.
+const localStateDir = ".devbox"
+
+// bundleDir returns the directory to push. If the user has a signing key, it
+// is a copy of the project's files with a signature, which is compressed with
+// the files by the backends that push archives. Otherwise it is the project
+// directory itself.
+func (p *pullbox) bundleDir() (string, error) {
+	key, err := sign.SigningKey()
+	if err != nil || key == nil {
+		return p.ProjectDir(), err
+	}
+
+	dir, err := fileutil.CreateDevboxTempDir()
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(p.ProjectDir())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == localStateDir || entry.Name() == sign.SignatureFile {
+			continue
+		}
+		cmd := cmdutil.CommandTTY("cp", "-rf", filepath.Join(p.ProjectDir(), entry.Name()), dir)
+		if err := cmd.Run(); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	if err := sign.SignDir(dir, key); err != nil {
+		return "", err
+	}
+	ux.Finfo(os.Stderr, "Signed global config with key %s\n", key.ID)
+	return dir, nil
+}
+
+// verify checks the signature of a pulled config. Verification is enabled
+// once the user trusts a key, after which configs that aren't signed by a
+// trusted key are refused unless allowUnsigned is set.
+func (p *pullbox) verify(src string) error {
+	trusted, err := sign.TrustedKeys()
+	if err != nil {
+		return err
+	}
+	if len(trusted) == 0 {
+		debug.Log("Not verifying %s because no signing keys are trusted", src)
+		return nil
+	}
+	key, err := sign.VerifyDir(src, trusted)
+	if err == nil {
+		ux.Finfo(os.Stderr, "Verified signature of key %s\n", key.ID)
+		return nil
+	}
+	if !p.allowUnsigned {
+		return err
+	}
+	switch {
+	case errors.Is(err, sign.ErrUnsigned):
+		ux.Fwarning(os.Stderr, "The config is not signed. Pulling it anyway because of --allow-unsigned.\n")
+	case errors.Is(err, sign.ErrInvalid):
+		ux.Fwarning(os.Stderr, "The config's signature could not be verified. "+
+			"Pulling it anyway because of --allow-unsigned.\n")
+	default:
+		return err
+	}
+	return nil
+}
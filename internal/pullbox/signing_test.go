@@ -0,0 +1,56 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/pullbox/sign"
+)
+
+func TestSignedPushAndPull(t *testing.T) {
+	t.Setenv(envir.XDGConfigHome, t.TempDir())
+	ctx := context.Background()
+	src := newConfigDir(t)
+	// Local state isn't part of the bundle.
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".devbox", "nix"), 0o755))
+
+	unsigned := filepath.Join(t.TempDir(), "unsigned.tar.gz")
+	require.NoError(t, New(testProject(src), "default", devopt.PullboxOpts{URL: unsigned}).Push(ctx))
+
+	pub, err := sign.GenerateSigningKey(false)
+	require.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.tar.gz")
+	require.NoError(t, New(testProject(src), "default", devopt.PullboxOpts{URL: signed}).Push(ctx))
+	require.NoFileExists(t, filepath.Join(src, sign.SignatureFile))
+
+	pull := func(url string, allowUnsigned bool) (string, error) {
+		dst := t.TempDir()
+		opts := devopt.PullboxOpts{URL: url, Overwrite: true, AllowUnsigned: allowUnsigned}
+		return dst, New(testProject(dst), "default", opts).Pull(ctx)
+	}
+
+	// Without trusted keys, nothing is verified.
+	_, err = pull(unsigned, false)
+	require.NoError(t, err)
+
+	require.NoError(t, sign.Trust(pub))
+	dst, err := pull(signed, false)
+	require.NoError(t, err)
+	requireConfigDir(t, dst)
+	require.NoDirExists(t, filepath.Join(dst, ".devbox"))
+	require.NoFileExists(t, filepath.Join(dst, sign.SignatureFile))
+
+	_, err = pull(unsigned, false)
+	require.ErrorIs(t, err, sign.ErrUnsigned)
+	_, err = pull(unsigned, true)
+	require.NoError(t, err)
+}
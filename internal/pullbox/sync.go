@@ -0,0 +1,156 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime/trace"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+	"go.jetpack.io/devbox/internal/pullbox/s3"
+	"go.jetpack.io/devbox/internal/ux"
+)
+
+// Sync merges the packages and env of the config stored at the url with the
+// project's config, and updates both sides with the result. It reports
+// whether the project's devbox.json changed.
+func (p *pullbox) Sync(ctx context.Context, opts devopt.SyncOpts) (bool, error) {
+	defer trace.StartRegion(ctx, "Sync").End()
+
+	if p.url != "" {
+		ux.Finfo(os.Stderr, "Syncing global config with %s\n", p.url)
+	} else {
+		ux.Finfo(os.Stderr, "Syncing global config\n")
+	}
+
+	b, err := newBackend(p.url, p.profile)
+	if err != nil {
+		return false, err
+	}
+	remote, err := p.pullConfig(ctx, b)
+	if err != nil {
+		return false, err
+	}
+	localPath, err := devconfig.Find(p.ProjectDir())
+	if err != nil {
+		return false, err
+	}
+	local, err := devconfig.Load(localPath)
+	if err != nil {
+		return false, err
+	}
+
+	h := newHistory(p.profile, p.url)
+	if remote != nil {
+		if err := h.recordPull(remote); err != nil {
+			return false, err
+		}
+	}
+	base, err := h.base()
+	if err != nil {
+		return false, err
+	}
+
+	// If nothing has been pushed yet, the local config is pushed as it is.
+	// Merging with an empty remote would instead remove every package that
+	// was synced before.
+	merged, conflicts := local, []string(nil)
+	if remote != nil {
+		merged, conflicts = merge(base, local, remote)
+	}
+	localDiff := devconfig.DiffConfigs(local, merged)
+	// A missing remote is pushed even if the local config is empty, so that
+	// the next sync finds it.
+	pushRemote := remote == nil || !devconfig.DiffConfigs(remote, merged).IsEmpty()
+
+	for _, conflict := range conflicts {
+		ux.Fwarning(os.Stderr, "%s\n", conflict)
+	}
+	if localDiff.IsEmpty() && !pushRemote {
+		ux.Fsuccess(os.Stderr, "Global config is already in sync\n")
+		return false, h.setBase(merged)
+	}
+	printSyncDiff(localDiff, devconfig.DiffConfigs(remote, merged))
+	if opts.DryRun {
+		return false, nil
+	}
+	if opts.Confirm != nil {
+		ok, err := opts.Confirm()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if !localDiff.IsEmpty() {
+		if err := merged.SaveTo(p.ProjectDir()); err != nil {
+			return false, err
+		}
+	}
+	if pushRemote {
+		dir, err := p.bundleDir()
+		if err != nil {
+			return !localDiff.IsEmpty(), err
+		}
+		if err := b.Push(ctx, dir); err != nil {
+			return !localDiff.IsEmpty(), err
+		}
+	}
+	return !localDiff.IsEmpty(), h.setBase(merged)
+}
+
+// pullConfig pulls the config stored by b, or returns nil if nothing has been
+// pushed there yet.
+func (p *pullbox) pullConfig(ctx context.Context, b backend) (*devconfig.Config, error) {
+	src, cleanup, err := b.Pull(ctx)
+	if errors.Is(err, s3.ErrProfileNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if err := p.verify(src); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	path := src
+	if info.IsDir() {
+		if path, err = devconfig.Find(src); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	cfg, err := devconfig.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return cfg, err
+}
+
+func printSyncDiff(local, remote *devconfig.Diff) {
+	for _, side := range []struct {
+		name string
+		diff *devconfig.Diff
+	}{{"local", local}, {"remote", remote}} {
+		if side.diff.IsEmpty() {
+			fmt.Fprintf(os.Stderr, "No changes to the %s config\n", side.name)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Changes to the %s config:\n", side.name)
+		for _, line := range strings.Split(strings.TrimSuffix(side.diff.String(), "\n"), "\n") {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package pullbox
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/impl/devopt"
+)
+
+type testProject string
+
+func (p testProject) ProjectDir() string { return string(p) }
+
+func TestSync(t *testing.T) {
+	t.Setenv(envir.XDGConfigHome, t.TempDir())
+	remote := filepath.Join(t.TempDir(), "remote")
+	laptop := newMachine(t, "go@1.21")
+	desktop := newMachine(t, "ripgrep")
+	yes := devopt.SyncOpts{Confirm: func() (bool, error) { return true, nil }}
+
+	// The first sync pushes the laptop's config as it is.
+	require.False(t, laptop.sync(t, remote, yes))
+	requirePackages(t, remote, "go@1.21")
+
+	// The desktop's packages are combined with the pushed ones.
+	require.True(t, desktop.sync(t, remote, yes))
+	requirePackages(t, desktop.dir, "ripgrep", "go@1.21")
+	requirePackages(t, remote, "ripgrep", "go@1.21")
+
+	// Removing a package on the desktop removes it everywhere, instead of
+	// the laptop adding it back.
+	desktop.setPackages(t, "ripgrep")
+	require.False(t, desktop.sync(t, remote, yes))
+	requirePackages(t, remote, "ripgrep")
+	require.True(t, laptop.sync(t, remote, yes))
+	requirePackages(t, laptop.dir, "ripgrep")
+
+	// A dry run or a declined sync changes nothing.
+	laptop.setPackages(t, "ripgrep", "jq")
+	no := devopt.SyncOpts{Confirm: func() (bool, error) { return false, nil }}
+	for _, opts := range []devopt.SyncOpts{{DryRun: true}, no} {
+		require.False(t, laptop.sync(t, remote, opts))
+		requirePackages(t, remote, "ripgrep")
+	}
+
+	// The first sync had nothing to pull.
+	t.Setenv(envir.XDGStateHome, laptop.state)
+	pulled, err := newHistory("default", remote).pulled()
+	require.NoError(t, err)
+	require.Len(t, pulled, 3)
+}
+
+// TestSyncFirstPush checks that every backend treats a config that hasn't
+// been pushed yet as nothing to pull, so that the first sync pushes.
+func TestSyncFirstPush(t *testing.T) {
+	t.Setenv(envir.XDGConfigHome, t.TempDir())
+	server := newObjectServer(t)
+	tests := map[string]string{
+		"local":         filepath.Join(t.TempDir(), "remote"),
+		"local archive": filepath.Join(t.TempDir(), "remote.tar.gz"),
+		"http":          server.URL + "/profiles/work.tar.gz",
+		"s3":            "s3://configs/team?endpoint=" + server.URL,
+		"git":           "git+file://" + newGitRepo(t),
+		"git dir":       "git+file://" + newGitRepo(t, "README.md") + "?dir=devbox",
+		"git branch":    "git+file://" + newGitRepo(t, "README.md") + "?ref=devbox",
+	}
+	yes := devopt.SyncOpts{Confirm: func() (bool, error) { return true, nil }}
+	for name, remote := range tests {
+		t.Run(name, func(t *testing.T) {
+			laptop := newMachine(t, "go@1.21")
+			require.False(t, laptop.sync(t, remote, yes))
+
+			desktop := newMachine(t, "ripgrep")
+			require.True(t, desktop.sync(t, remote, yes))
+			requirePackages(t, desktop.dir, "ripgrep", "go@1.21")
+		})
+	}
+}
+
+// newObjectServer returns a server that stores the objects that are PUT to
+// it. A GET of a missing object fails like an S3 store does, so that the
+// server works for both the http and the s3 backends.
+func newObjectServer(t *testing.T) *httptest.Server {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	mu := sync.Mutex{}
+	stored := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			stored[r.URL.Path] = data
+		case http.MethodGet:
+			data, ok := stored[r.URL.Path]
+			if !ok {
+				w.Header().Set("Content-Type", "application/xml")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+				return
+			}
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newGitRepo returns a bare repository with a commit of files on its default
+// branch, or an empty one if there are no files.
+func newGitRepo(t *testing.T, files ...string) string {
+	t.Setenv("GIT_AUTHOR_NAME", "devbox")
+	t.Setenv("GIT_AUTHOR_EMAIL", "devbox@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "devbox")
+	t.Setenv("GIT_COMMITTER_EMAIL", "devbox@example.com")
+	bare := filepath.Join(t.TempDir(), "config.git")
+	runGit(t, "", "init", "--bare", bare)
+	if len(files) == 0 {
+		return bare
+	}
+	work := t.TempDir()
+	runGit(t, "", "clone", bare, work)
+	for _, file := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(work, file), []byte(file), 0o644))
+	}
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-m", "Add files")
+	runGit(t, work, "push", "origin", "HEAD")
+	return bare
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+}
+
+// machine is a global profile with its own sync history.
+type machine struct {
+	dir   string
+	state string
+}
+
+func newMachine(t *testing.T, pkgs ...string) *machine {
+	m := &machine{dir: t.TempDir(), state: t.TempDir()}
+	m.setPackages(t, pkgs...)
+	return m
+}
+
+func (m *machine) setPackages(t *testing.T, pkgs ...string) {
+	require.NoError(t, (&devconfig.Config{Packages: pkgs}).SaveTo(m.dir))
+}
+
+func (m *machine) sync(t *testing.T, remote string, opts devopt.SyncOpts) bool {
+	t.Setenv(envir.XDGStateHome, m.state)
+	updated, err := New(testProject(m.dir), "default", devopt.PullboxOpts{URL: remote}).
+		Sync(context.Background(), opts)
+	require.NoError(t, err)
+	return updated
+}
+
+func requirePackages(t *testing.T, dir string, pkgs ...string) {
+	cfg, err := devconfig.Load(filepath.Join(dir, devconfig.DefaultName))
+	require.NoError(t, err)
+	require.Equal(t, pkgs, cfg.Packages)
+}
@@ -37,6 +37,7 @@ func Extract(data []byte) (string, error) {
 	cmd := exec.Command("tar", "-xf", tempFile.Name(), "-C", tempDir)
 
 	if err = cmd.Run(); err != nil {
+		os.RemoveAll(tempDir)
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			waitStatus := exitErr.Sys().(syscall.WaitStatus)
@@ -61,7 +62,7 @@ func Compress(dir string) (string, error) {
 
 	cmd.Dir = dir
 
-	if err = cmd.Start(); err != nil {
+	if err = cmd.Run(); err != nil {
 		return "", errors.WithStack(err)
 	}
 
@@ -64,6 +64,13 @@ func (c *client) Resolve(pkg string) (*lock.Package, error) {
 	if version == "" {
 		return nil, usererr.New("No version specified for %q.", name)
 	}
+	if devpkg.IsVersionConstraint(version) {
+		resolved, err := c.resolveConstraint(name, version)
+		if err != nil {
+			return nil, err
+		}
+		version = resolved
+	}
 	result, err := c.Search(name, WithVersion(version))
 	if err != nil {
 		return nil, err
@@ -97,6 +104,50 @@ func (c *client) Resolve(pkg string) (*lock.Package, error) {
 	}, nil
 }
 
+// Versions returns all the versions of the package with the given name that
+// the search API knows about.
+func (c *client) Versions(name string) ([]string, error) {
+	result, err := c.Search(name)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := lo.Find(result.Results, func(r Result) bool { return r.Name == name })
+	if !ok {
+		return nil, nix.ErrPackageNotFound
+	}
+	return lo.Uniq(lo.Map(r.Packages, func(p *NixPackageInfo, _ int) string {
+		return p.Version
+	})), nil
+}
+
+// resolveConstraint returns the highest version of the package that satisfies
+// the version constraint (e.g. ^18 or >=1.20 <1.22).
+func (c *client) resolveConstraint(name, constraint string) (string, error) {
+	vc, err := devpkg.ParseVersionConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+	versions, err := c.Versions(name)
+	if err != nil {
+		return "", err
+	}
+	best := ""
+	for _, v := range versions {
+		if vc.Check(v) && (best == "" || devpkg.CompareVersions(v, best) > 0) {
+			best = v
+		}
+	}
+	if best == "" {
+		return "", usererr.WithUserMessage(
+			nix.ErrPackageNotFound,
+			"No version of %s satisfies %q.",
+			name,
+			constraint,
+		)
+	}
+	return best, nil
+}
+
 // resolvePackageSystemInfoIfAny is temporary, until the search API returns
 // the "system info" like the store-hash. This uses the /pkg api that is
 // for nixhub.io as a temporary workaround.
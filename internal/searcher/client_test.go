@@ -0,0 +1,74 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package searcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+// newTestSearchServer serves a search API that knows the given versions of
+// nodejs.
+func newTestSearchServer(t *testing.T, versions ...string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := &SearchResult{}
+		if r.URL.Query().Get("q") == "nodejs" {
+			packages := NixPackageInfoList{}
+			for _, v := range versions {
+				if want := r.URL.Query().Get("v"); want != "" && want != v {
+					continue
+				}
+				packages = append(packages, &NixPackageInfo{
+					AttributePath: "nodejs",
+					NixpkgCommit:  "commit-" + v,
+					PName:         "nodejs",
+					Version:       v,
+				})
+			}
+			if len(packages) > 0 {
+				result.Results = []Result{{Name: "nodejs", Packages: packages}}
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(result))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv(envir.DevboxSearchHost, server.URL)
+}
+
+func TestResolveConstraint(t *testing.T) {
+	newTestSearchServer(t, "20.5.0", "18.19.0", "18.17.1", "18.9.0", "16.20.2")
+
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"nodejs@^18", "18.19.0"},
+		{"nodejs@^18.17", "18.19.0"},
+		{"nodejs@~18.17", "18.17.1"},
+		{"nodejs@~16", "16.20.2"},
+		{"nodejs@>=18.10", "20.5.0"},
+		{"nodejs@>=18.10 <20", "18.19.0"},
+		{"nodejs@>16, <18.10", "18.9.0"},
+		{"nodejs@18.17.1", "18.17.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.pkg, func(t *testing.T) {
+			pkg, err := Client().Resolve(test.pkg)
+			require.NoError(t, err)
+			require.Equal(t, test.want, pkg.Version)
+			require.Equal(t, "github:NixOS/nixpkgs/commit-"+test.want+"#nodejs", pkg.Resolved)
+		})
+	}
+
+	for _, pkg := range []string{"nodejs@^21", "nodejs@~18.18", "nodejs@<16"} {
+		_, err := Client().Resolve(pkg)
+		require.Error(t, err, pkg)
+	}
+}
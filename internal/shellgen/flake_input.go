@@ -2,10 +2,12 @@ package shellgen
 
 import (
 	"context"
+	"fmt"
 	"runtime/trace"
 	"strings"
 
 	"github.com/samber/lo"
+	"go.jetpack.io/devbox/internal/devconfig"
 	"go.jetpack.io/devbox/internal/goutil"
 	"go.jetpack.io/devbox/internal/nix"
 )
@@ -14,6 +16,14 @@ type flakeInput struct {
 	Name     string
 	Packages []string
 	URL      string
+
+	// Outputs maps a package attribute path to its selected outputs. Packages
+	// without an entry use their default outputs.
+	Outputs map[string][]string
+
+	// Overrides maps a package attribute path to the override from the
+	// devbox.json overrides block.
+	Overrides map[string]*devconfig.Override
 }
 
 // IsNixpkgs returns true if the input is a nixpkgs flake of the form:
@@ -41,20 +51,64 @@ func (f *flakeInput) URLWithCaching() string {
 	return getNixpkgsInfo(hash).URL
 }
 
+// TarURL returns the tarball URL of a nixpkgs input, which shell.nix can use
+// with fetchTarball.
+func (f *flakeInput) TarURL() string {
+	return fmt.Sprintf("https://github.com/nixos/nixpkgs/archive/%s.tar.gz", f.HashFromNixPkgsURL())
+}
+
 func (f *flakeInput) PkgImportName() string {
 	return f.Name + "-pkgs"
 }
 
 func (f *flakeInput) BuildInputs() []string {
-	if !f.IsNixpkgs() {
-		return lo.Map(f.Packages, func(pkg string, _ int) string {
-			return f.Name + "." + pkg
-		})
-	}
-	return lo.Map(f.Packages, func(pkg string, _ int) string {
+	return lo.FlatMap(f.Packages, f.buildInputs)
+}
+
+// OverriddenBuildInputs returns the build inputs of packages that have an
+// override. They aren't installed in the nix profile, so devbox needs to know
+// which store paths to keep in PATH.
+func (f *flakeInput) OverriddenBuildInputs() []string {
+	overridden := lo.Filter(f.Packages, func(pkg string, _ int) bool {
+		return f.Overrides[pkg] != nil
+	})
+	return lo.FlatMap(overridden, f.buildInputs)
+}
+
+// SystemBuildInputs is like BuildInputs, but selects packages from flakes
+// other than nixpkgs for the system that the nix expression `system` evaluates
+// to, instead of the system devbox resolved them for. Standalone nix files use
+// it so that they work on any system.
+func (f *flakeInput) SystemBuildInputs(system string) []string {
+	return lo.FlatMap(f.Packages, func(pkg string, _ int) []string {
+		attrPath := pkg
+		parts := strings.SplitN(pkg, ".", 3)
+		if len(parts) == 3 && (parts[0] == "packages" || parts[0] == "legacyPackages") {
+			attrPath = parts[0] + ".${" + system + "}." + parts[2]
+		}
+		return f.attrPathBuildInputs(pkg, attrPath)
+	})
+}
+
+func (f *flakeInput) buildInputs(pkg string, _ int) []string {
+	return f.attrPathBuildInputs(pkg, pkg)
+}
+
+// attrPathBuildInputs returns the build inputs of pkg, referring to it in the
+// flake by attrPath.
+func (f *flakeInput) attrPathBuildInputs(pkg, attrPath string) []string {
+	buildInput := f.Name + "." + attrPath
+	if f.IsNixpkgs() {
 		parts := strings.Split(pkg, ".")
 		// Ugh, not sure if this is reliable?
-		return f.PkgImportName() + "." + strings.Join(parts[2:], ".")
+		buildInput = f.PkgImportName() + "." + strings.Join(parts[2:], ".")
+	}
+	buildInput = f.Overrides[pkg].Apply(buildInput)
+	if len(f.Outputs[pkg]) == 0 {
+		return []string{buildInput}
+	}
+	return lo.Map(f.Outputs[pkg], func(output string, _ int) string {
+		return buildInput + "." + output
 	})
 }
 
@@ -86,18 +140,26 @@ func flakeInputs(ctx context.Context, devbox devboxer) ([]*flakeInput, error) {
 		if err != nil {
 			return nil, err
 		}
-		if flkInput, ok := flakeInputs[input.URLForFlakeInput()]; !ok {
+		flkInput, ok := flakeInputs[input.URLForFlakeInput()]
+		if !ok {
 			order = append(order, input.URLForFlakeInput())
-			flakeInputs[input.URLForFlakeInput()] = &flakeInput{
-				Name:     input.FlakeInputName(),
-				URL:      input.URLForFlakeInput(),
-				Packages: []string{AttributePath},
+			flkInput = &flakeInput{
+				Name:      input.FlakeInputName(),
+				URL:       input.URLForFlakeInput(),
+				Outputs:   map[string][]string{},
+				Overrides: map[string]*devconfig.Override{},
 			}
-		} else {
-			flkInput.Packages = lo.Uniq(
-				append(flakeInputs[input.URLForFlakeInput()].Packages, AttributePath),
+			flakeInputs[input.URLForFlakeInput()] = flkInput
+		}
+		flkInput.Packages = lo.Uniq(append(flkInput.Packages, AttributePath))
+		if len(input.Outputs) > 0 {
+			flkInput.Outputs[AttributePath] = lo.Uniq(
+				append(flkInput.Outputs[AttributePath], input.Outputs...),
 			)
 		}
+		if override := devbox.Config().OverrideFor(input.Raw); override != nil {
+			flkInput.Overrides[AttributePath] = override
+		}
 	}
 
 	return goutil.PickByKeysSorted(flakeInputs, order), nil
@@ -0,0 +1,61 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package shellgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/devconfig"
+)
+
+func TestBuildInputsWithOutputs(t *testing.T) {
+	input := &flakeInput{
+		Name: "nixpkgs-abcdef",
+		URL:  "github:NixOS/nixpkgs/abcdef",
+		Packages: []string{
+			"legacyPackages.x86_64-linux.openssl",
+			"legacyPackages.x86_64-linux.hello",
+		},
+		Outputs: map[string][]string{
+			"legacyPackages.x86_64-linux.openssl": {"out", "dev"},
+		},
+	}
+	require.Equal(t, []string{
+		"nixpkgs-abcdef-pkgs.openssl.out",
+		"nixpkgs-abcdef-pkgs.openssl.dev",
+		"nixpkgs-abcdef-pkgs.hello",
+	}, input.BuildInputs())
+
+	flake := &flakeInput{
+		Name:     "gh-foo",
+		URL:      "github:foo/bar",
+		Packages: []string{"packages.x86_64-linux.default"},
+		Outputs:  map[string][]string{"packages.x86_64-linux.default": {"lib"}},
+	}
+	require.Equal(t, []string{"gh-foo.packages.x86_64-linux.default.lib"}, flake.BuildInputs())
+}
+
+func TestBuildInputsWithOverrides(t *testing.T) {
+	input := &flakeInput{
+		Name: "nixpkgs-abcdef",
+		URL:  "github:NixOS/nixpkgs/abcdef",
+		Packages: []string{
+			"legacyPackages.x86_64-linux.vim",
+			"legacyPackages.x86_64-linux.hello",
+		},
+		Outputs: map[string][]string{
+			"legacyPackages.x86_64-linux.vim": {"out"},
+		},
+		Overrides: map[string]*devconfig.Override{
+			"legacyPackages.x86_64-linux.vim": {
+				Override: map[string]string{"guiSupport": `"none"`},
+			},
+		},
+	}
+	overridden := `(nixpkgs-abcdef-pkgs.vim.override { guiSupport = "none"; }).out`
+	require.Equal(t, []string{overridden, "nixpkgs-abcdef-pkgs.hello"}, input.BuildInputs())
+	require.Equal(t, []string{overridden}, input.OverriddenBuildInputs())
+}
@@ -70,7 +70,12 @@ var (
 )
 
 func writeFromTemplate(path string, plan any, tmplName string) error {
-	tmplKey := tmplName + ".tmpl"
+	return writeTemplate(filepath.Join(path, tmplName), plan, tmplName+".tmpl")
+}
+
+// writeTemplate executes the template tmplKey in the tmpl directory and
+// writes the result to outPath.
+func writeTemplate(outPath string, plan any, tmplKey string) error {
 	tmpl := tmplCache[tmplKey]
 	if tmpl == nil {
 		tmpl = template.New(tmplKey)
@@ -93,13 +98,12 @@ func writeFromTemplate(path string, plan any, tmplName string) error {
 	// changed. Blindly overwriting the file could invalidate Nix's cache
 	// every time, slowing down evaluation considerably.
 	var (
-		outPath = filepath.Join(path, tmplName)
-		flag    = os.O_RDWR | os.O_CREATE
-		perm    = fs.FileMode(0644)
+		flag = os.O_RDWR | os.O_CREATE
+		perm = fs.FileMode(0644)
 	)
 	outFile, err := os.OpenFile(outPath, flag, perm)
 	if errors.Is(err, fs.ErrNotExist) {
-		if err := os.MkdirAll(path, 0755); err != nil {
+		if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
 			return errors.WithStack(err)
 		}
 		outFile, err = os.OpenFile(outPath, flag, perm)
@@ -143,9 +147,10 @@ func toJSON(a any) string {
 }
 
 var templateFuncs = template.FuncMap{
-	"json":     toJSON,
-	"contains": strings.Contains,
-	"debug":    debug.IsEnabled,
+	"json":              toJSON,
+	"contains":          strings.Contains,
+	"debug":             debug.IsEnabled,
+	"nixIndentedString": nixIndentedString,
 }
 
 func makeFlakeFile(d devboxer, outPath string, plan *flakePlan) error {
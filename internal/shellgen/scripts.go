@@ -79,7 +79,17 @@ func WriteScriptsToFiles(devbox devboxer) error {
 }
 
 func WriteScriptFile(d devboxer, name string, body string) (err error) {
-	script, err := os.Create(ScriptPath(d.ProjectDir(), name))
+	if featureflag.ScriptExitOnError.Enabled() {
+		body = fmt.Sprintf("set -e\n\n%s", body)
+	}
+	// Scripts are written every time devbox runs, so leave unchanged ones
+	// alone rather than truncating a script that another shell is running.
+	path := ScriptPath(d.ProjectDir(), name)
+	if old, err := os.ReadFile(path); err == nil && string(old) == body {
+		return nil
+	}
+
+	script, err := os.Create(path)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -94,9 +104,6 @@ func WriteScriptFile(d devboxer, name string, body string) (err error) {
 		return errors.WithStack(err)
 	}
 
-	if featureflag.ScriptExitOnError.Enabled() {
-		body = fmt.Sprintf("set -e\n\n%s", body)
-	}
 	_, err = script.WriteString(body)
 	return errors.WithStack(err)
 }
@@ -0,0 +1,85 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package shellgen
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime/trace"
+	"strings"
+
+	"github.com/samber/lo"
+	"golang.org/x/exp/slices"
+)
+
+// standalonePlan is the data for a flake.nix and shell.nix that don't depend
+// on devbox.
+type standalonePlan struct {
+	*flakePlan
+	// ShellHook exports the env from devbox.json and runs the init hook.
+	ShellHook []string
+}
+
+// GenerateStandalone writes a flake.nix and shell.nix to dir that create the
+// same shell environment as the devbox project, but don't need devbox.
+func GenerateStandalone(ctx context.Context, devbox devboxer, dir string) error {
+	defer trace.StartRegion(ctx, "generateStandalone").End()
+
+	plan, err := newFlakePlan(ctx, devbox)
+	if err != nil {
+		return err
+	}
+	standalone := &standalonePlan{
+		flakePlan: plan,
+		ShellHook: shellHook(devbox),
+	}
+	for _, name := range []string{"flake.nix", "shell.nix"} {
+		outPath := filepath.Join(dir, name)
+		if err := writeTemplate(outPath, standalone, "standalone-"+name+".tmpl"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func shellHook(devbox devboxer) []string {
+	cfg := devbox.Config()
+	lines := []string{}
+	keys := lo.Keys(cfg.Env)
+	slices.Sort(keys)
+	for _, k := range keys {
+		// Double quotes so that values like $PWD/bin are expanded like they
+		// are by devbox.
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`").Replace(cfg.Env[k])
+		lines = append(lines, fmt.Sprintf(`export %s="%s"`, k, v))
+	}
+	if hook := cfg.InitHook(); hook != nil {
+		lines = append(lines, hook.Cmds...)
+	}
+	return lines
+}
+
+// nixIndentedString returns a nix indented string containing lines, indented by
+// indent spaces. The closing quotes are indented by two fewer spaces.
+func nixIndentedString(lines []string, indent int) string {
+	if len(lines) == 0 {
+		return `""`
+	}
+	escape := strings.NewReplacer("''", "'''", "${", "''${")
+	b := strings.Builder{}
+	b.WriteString("''\n")
+	for _, line := range lines {
+		if line != "" {
+			b.WriteString(strings.Repeat(" ", indent))
+			b.WriteString(escape.Replace(line))
+		}
+		b.WriteString("\n")
+	}
+	if indent >= 2 {
+		b.WriteString(strings.Repeat(" ", indent-2))
+	}
+	b.WriteString("''")
+	return b.String()
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package shellgen
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteStandaloneTemplates(t *testing.T) {
+	plan := &standalonePlan{
+		flakePlan: &flakePlan{
+			NixpkgsInfo: getNixpkgsInfo("b9c00c1d41ccd6385da243415299b39aa73357be"),
+			FlakeInputs: []*flakeInput{
+				{
+					Name: "nixpkgs-b9c00c",
+					URL:  "github:NixOS/nixpkgs/b9c00c1d41ccd6385da243415299b39aa73357be",
+					Packages: []string{
+						"legacyPackages.x86_64-linux.go",
+						"legacyPackages.x86_64-linux.ripgrep",
+					},
+				},
+				{
+					Name:     "gh-foo-bar",
+					URL:      "github:foo/bar",
+					Packages: []string{"packages.x86_64-linux.default"},
+				},
+			},
+		},
+		ShellHook: []string{
+			`export GOPATH="$PWD/.go"`,
+			"echo ''quoted'' ${HOME}",
+		},
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"flake.nix", "shell.nix"} {
+		t.Run(name, func(t *testing.T) {
+			outPath := filepath.Join(dir, name)
+			if err := writeTemplate(outPath, plan, "standalone-"+name+".tmpl"); err != nil {
+				t.Fatal("got error writing template:", err)
+			}
+			cmpGoldenFile(t, outPath, "testdata/standalone-"+name+".golden")
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/fileutil"
+)
+
+// downloadTimeout bounds how long downloading a template archive can take, so
+// that an unresponsive server doesn't hang devbox create.
+const downloadTimeout = 2 * time.Minute
+
+// fetch downloads the template into a temporary directory and returns the
+// directory that contains the template files. cleanup removes any temporary
+// files and is safe to call even if fetch fails.
+func fetch(ctx context.Context, w io.Writer, src *source) (dir string, cleanup func(), err error) {
+	cleanup = func() {}
+	if src.local != "" {
+		dir, err = resolveSubdir(src.local, src.subdir)
+		return dir, cleanup, err
+	}
+
+	tmp, err := fileutil.CreateDevboxTempDir()
+	if err != nil {
+		return "", cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+
+	if src.gitURL != "" {
+		if err := fetchGit(ctx, w, src, tmp); err != nil {
+			return "", cleanup, err
+		}
+		dir, err = resolveSubdir(tmp, src.subdir)
+		return dir, cleanup, err
+	}
+
+	if err := fetchArchive(ctx, w, src.archive, tmp); err != nil {
+		return "", cleanup, err
+	}
+	// Archives such as GitHub's usually have a single top-level directory.
+	root := tmp
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		return "", cleanup, errors.WithStack(err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(tmp, entries[0].Name())
+	}
+	dir, err = resolveSubdir(root, src.subdir)
+	return dir, cleanup, err
+}
+
+// fetchGit does a shallow fetch of a single ref and, if the template is in a
+// subdirectory, a sparse checkout of only that directory. This is much faster
+// than cloning large repositories.
+func fetchGit(ctx context.Context, w io.Writer, src *source, dir string) error {
+	ref := src.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", src.gitURL},
+	}
+	if src.subdir != "" {
+		steps = append(steps, []string{"sparse-checkout", "set", src.subdir})
+	}
+	steps = append(steps,
+		[]string{"fetch", "--quiet", "--depth", "1", "--filter=blob:none", "origin", ref},
+		[]string{"checkout", "--quiet", "FETCH_HEAD"},
+	)
+	for _, args := range steps {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.Stdout = w
+		cmd.Stderr = w
+		fmt.Fprintf(w, "%s\n", cmd)
+		if err := cmd.Run(); err != nil {
+			return usererr.WithUserMessage(
+				errors.WithStack(err),
+				"Failed to fetch template from %s", src.gitURL,
+			)
+		}
+	}
+	return nil
+}
+
+func fetchArchive(ctx context.Context, w io.Writer, archive, dir string) error {
+	var r io.ReadCloser
+	if strings.HasPrefix(archive, "http://") || strings.HasPrefix(archive, "https://") {
+		fmt.Fprintf(w, "Downloading %s\n", archive)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, archive, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		client := &http.Client{Timeout: downloadTimeout}
+		resp, err := client.Do(req)
+		if err != nil {
+			return usererr.WithUserMessage(
+				errors.WithStack(err),
+				"Failed to download template %s", archive,
+			)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return usererr.New("Failed to download template %s: %s", archive, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(archive)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		r = f
+	}
+	defer r.Close()
+	return errors.WithStack(fileutil.Untar(r, dir))
+}
+
+func resolveSubdir(root, subdir string) (string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(subdir))
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", usererr.New("template subdirectory %q is outside the template", subdir)
+	}
+	if !fileutil.IsDir(dir) {
+		return "", usererr.New("template directory %q does not exist", subdir)
+	}
+	return dir, nil
+}
+
+// copyTemplate copies the files in src to dst, skipping the .git directory.
+func copyTemplate(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		switch {
+		case entry.IsDir():
+			return errors.WithStack(os.MkdirAll(target, 0755))
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return errors.WithStack(os.Symlink(link, target))
+		default:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			return errors.WithStack(os.WriteFile(target, data, info.Mode().Perm()))
+		}
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package templates
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.jetpack.io/devbox/internal/boxcli/usererr"
+	"go.jetpack.io/devbox/internal/fileutil"
+)
+
+const devboxRepo = "https://github.com/jetpack-io/devbox.git"
+
+// source is where a template is fetched from. Exactly one of gitURL, archive
+// or local is set.
+type source struct {
+	// gitURL is a git repository, fetched with a shallow, sparse checkout.
+	gitURL string
+	// ref is the branch, tag or commit of gitURL to use. Defaults to HEAD.
+	ref string
+	// archive is the URL or path of a .tar.gz file.
+	archive string
+	// local is a directory on disk.
+	local string
+	// subdir is the directory within the repo or archive that contains the
+	// template.
+	subdir string
+}
+
+// parseSource parses a template reference. It accepts:
+//
+//	go                                          built-in template name
+//	./path/to/template                          local directory
+//	https://example.com/template.tar.gz         tarball URL or path
+//	https://github.com/org/repo.git             git repository
+//	git@github.com:org/repo.git//templates/go   subdirectory of a repository
+//	https://github.com/org/repo.git?ref=v1.2.0  branch, tag or commit
+//
+// The `//subdir` and `?ref=` suffixes follow the same format as Terraform
+// module sources.
+func parseSource(template string) (*source, error) {
+	if path, ok := templates[template]; ok {
+		return &source{gitURL: devboxRepo, subdir: strings.Trim(path, "/")}, nil
+	}
+	if fileutil.IsDir(template) {
+		return &source{local: template}, nil
+	}
+
+	location, ref := splitRef(template)
+	location, subdir := splitSubdir(location)
+
+	switch {
+	case isArchive(location):
+		if ref != "" {
+			return nil, usererr.New("?ref= is only supported for git templates")
+		}
+		return &source{archive: location, subdir: subdir}, nil
+	case fileutil.IsDir(location):
+		return &source{local: location, subdir: subdir}, nil
+	case isGitURL(location):
+		return &source{gitURL: location, ref: ref, subdir: subdir}, nil
+	}
+	return nil, usererr.New(
+		"unknown template %q. Use a template name, a local directory, a "+
+			".tar.gz file or URL, or a git repository URL",
+		template,
+	)
+}
+
+// splitRef removes the `ref` query parameter from a URL.
+func splitRef(location string) (string, string) {
+	base, query, found := strings.Cut(location, "?")
+	if !found {
+		return location, ""
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil || !values.Has("ref") {
+		return location, ""
+	}
+	ref := values.Get("ref")
+	values.Del("ref")
+	if len(values) > 0 {
+		base += "?" + values.Encode()
+	}
+	return base, ref
+}
+
+// splitSubdir splits `<location>//<subdir>`, ignoring the `//` of a scheme.
+func splitSubdir(location string) (string, string) {
+	start := 0
+	if i := strings.Index(location, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	i := strings.Index(location[start:], "//")
+	if i < 0 {
+		return location, ""
+	}
+	return location[:start+i], strings.Trim(location[start+i+2:], "/")
+}
+
+func isArchive(location string) bool {
+	path := location
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" {
+		path = u.Path
+	}
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+func isGitURL(location string) bool {
+	for _, prefix := range []string{"git@", "https://", "http://", "ssh://", "git://", "file://"} {
+		if strings.HasPrefix(location, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DirName returns a directory name for a project created from the template
+// when the user doesn't specify one, e.g. `go` for
+// `git@github.com:org/templates.git//go?ref=v1`.
+func DirName(template string) string {
+	if _, ok := templates[template]; ok {
+		return template
+	}
+	location, _ := splitRef(template)
+	location, subdir := splitSubdir(location)
+	if subdir != "" {
+		return path.Base(subdir)
+	}
+	name := path.Base(strings.TrimRight(filepath.ToSlash(location), "/"))
+	for _, suffix := range []string{".git", ".tar.gz", ".tgz"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
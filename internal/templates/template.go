@@ -4,10 +4,10 @@
 package templates
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/pkg/errors"
@@ -17,38 +17,42 @@ import (
 	"go.jetpack.io/devbox/internal/boxcli/usererr"
 )
 
-func Init(w io.Writer, template, dir string) error {
+// Init creates a devbox project in dir from a template. The template can be a
+// built-in template name, a local directory, a tarball, or a git repository
+// (see parseSource). vars sets the values of the template's variables; any
+// variables not set are prompted for.
+func Init(ctx context.Context, w io.Writer, template, dir string, vars map[string]string) error {
+	src, err := parseSource(template)
+	if err != nil {
+		return err
+	}
+
 	if err := createDirAndEnsureEmpty(dir); err != nil {
 		return err
 	}
 
-	templatePath, ok := templates[template]
-	if !ok {
-		return usererr.New("unknown template %q", template)
+	templateDir, cleanup, err := fetch(ctx, w, src)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if err := copyTemplate(templateDir, dir); err != nil {
+		return err
 	}
 
-	tmp, err := os.MkdirTemp("", "devbox-template")
+	m, err := readManifest(dir)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	cmd := exec.Command(
-		"git", "clone", "https://github.com/jetpack-io/devbox.git", tmp,
-	)
-	fmt.Fprintf(w, "%s\n", cmd)
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	if err = cmd.Run(); err != nil {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
 		return errors.WithStack(err)
 	}
-
-	cmd = exec.Command(
-		"sh", "-c",
-		fmt.Sprintf("cp -r %s %s", filepath.Join(tmp, templatePath, "*"), dir),
-	)
-	fmt.Fprintf(w, "%s\n", cmd)
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	return errors.WithStack(cmd.Run())
+	values, err := resolveVariables(m, absDir, vars)
+	if err != nil {
+		return err
+	}
+	return renderPlaceholders(dir, values)
 }
 
 func List(w io.Writer, showAll bool) {
@@ -3,11 +3,15 @@
 package templates
 
 import (
+	"context"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTemplatesExist(t *testing.T) {
@@ -26,3 +30,99 @@ func TestTemplatesExist(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSource(t *testing.T) {
+	localDir := t.TempDir()
+	cases := []struct {
+		template string
+		expected source
+	}{
+		{"go", source{gitURL: devboxRepo, subdir: "examples/development/go/hello-world"}},
+		{localDir, source{local: localDir}},
+		{localDir + "//sub", source{local: localDir, subdir: "sub"}},
+		{"https://example.com/t.tar.gz", source{archive: "https://example.com/t.tar.gz"}},
+		{"https://example.com/t.tgz//go", source{archive: "https://example.com/t.tgz", subdir: "go"}},
+		{"https://github.com/org/repo.git", source{gitURL: "https://github.com/org/repo.git"}},
+		{
+			"git@github.com:org/repo.git//templates/go?ref=v1.2.0",
+			source{gitURL: "git@github.com:org/repo.git", ref: "v1.2.0", subdir: "templates/go"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.template, func(t *testing.T) {
+			src, err := parseSource(tc.template)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, *src)
+		})
+	}
+
+	_, err := parseSource("not-a-template")
+	require.Error(t, err)
+}
+
+func TestDirName(t *testing.T) {
+	require.Equal(t, "go", DirName("go"))
+	require.Equal(t, "repo", DirName("https://github.com/org/repo.git"))
+	require.Equal(t, "go", DirName("git@github.com:org/repo.git//templates/go?ref=v1"))
+	require.Equal(t, "starter", DirName("https://example.com/starter.tar.gz"))
+}
+
+func TestInitFromLocalDir(t *testing.T) {
+	req := require.New(t)
+	template := t.TempDir()
+	writeFile(t, filepath.Join(template, "devbox.json"), `{"packages": [], "env": {"PORT": "{{ devbox.port }}"}}`)
+	writeFile(t, filepath.Join(template, "README.md"), "# {{devbox.project_name}} ${{ github.sha }} {{ devbox.unknown }}")
+	writeFile(t, filepath.Join(template, manifestName), `{"variables": [{"name": "port", "default": "8080"}]}`)
+	writeFile(t, filepath.Join(template, ".git", "HEAD"), "ref: refs/heads/main")
+
+	dir := filepath.Join(t.TempDir(), "my-app")
+	req.NoError(Init(context.Background(), io.Discard, template, dir, nil))
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	req.NoError(err)
+	req.Equal("# my-app ${{ github.sha }} {{ devbox.unknown }}", string(readme))
+	cfg, err := os.ReadFile(filepath.Join(dir, "devbox.json"))
+	req.NoError(err)
+	req.Contains(string(cfg), `"PORT": "8080"`)
+	req.NoFileExists(filepath.Join(dir, manifestName))
+	req.NoDirExists(filepath.Join(dir, ".git"))
+
+	dir = filepath.Join(t.TempDir(), "other")
+	req.NoError(Init(context.Background(), io.Discard, template, dir, map[string]string{"port": "3000"}))
+	cfg, err = os.ReadFile(filepath.Join(dir, "devbox.json"))
+	req.NoError(err)
+	req.Contains(string(cfg), `"PORT": "3000"`)
+}
+
+func TestInitFromGitSubdir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	req := require.New(t)
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, "templates", "go", "devbox.json"), `{"packages": ["go@1.20"]}`)
+	writeFile(t, filepath.Join(repo, "other", "devbox.json"), `{"packages": []}`)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		req.NoError(err, string(out))
+	}
+
+	dir := filepath.Join(t.TempDir(), "app")
+	req.NoError(Init(context.Background(), io.Discard, "file://"+repo+"//templates/go?ref=v1", dir, nil))
+	req.FileExists(filepath.Join(dir, "devbox.json"))
+	req.NoDirExists(filepath.Join(dir, "other"))
+	req.NoDirExists(filepath.Join(dir, "templates"))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
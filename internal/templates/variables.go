@@ -0,0 +1,128 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package templates
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/cuecfg"
+)
+
+// manifestName is an optional file at the root of a template that declares
+// the variables the template uses. It is removed from the created project.
+//
+//	{
+//	  "variables": [
+//	    {"name": "port", "prompt": "Port for the dev server", "default": "8080"}
+//	  ]
+//	}
+const manifestName = "devbox-template.json"
+
+// projectNameVar is always available and defaults to the project directory name.
+const projectNameVar = "project_name"
+
+type manifest struct {
+	Variables []variable `json:"variables,omitempty"`
+}
+
+type variable struct {
+	Name    string `json:"name"`
+	Prompt  string `json:"prompt,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+// placeholderRegex matches placeholders like {{ devbox.project_name }}. The
+// devbox. prefix avoids clashing with other templating syntaxes, such as
+// GitHub Actions expressions, that templates may contain.
+var placeholderRegex = regexp.MustCompile(`\{\{\s*devbox\.([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// readManifest reads and removes the template manifest from dir, if present.
+func readManifest(dir string) (*manifest, error) {
+	m := &manifest{}
+	path := filepath.Join(dir, manifestName)
+	err := cuecfg.ParseFile(path, m)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, errors.WithStack(os.Remove(path))
+}
+
+// resolveVariables returns the value of every variable in the manifest. Values
+// passed in vars take precedence. Otherwise the user is prompted if stdin is a
+// terminal, and the default is used if not.
+func resolveVariables(m *manifest, dir string, vars map[string]string) (map[string]string, error) {
+	values := map[string]string{projectNameVar: filepath.Base(dir)}
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	interactive := isatty.IsTerminal(os.Stdin.Fd())
+	for _, v := range m.Variables {
+		if _, ok := vars[v.Name]; ok {
+			continue
+		}
+		value := v.Default
+		if v.Name == projectNameVar && value == "" {
+			value = values[projectNameVar]
+		}
+		if interactive {
+			prompt := &survey.Input{
+				Message: v.Prompt,
+				Default: value,
+			}
+			if prompt.Message == "" {
+				prompt.Message = v.Name
+			}
+			if err := survey.AskOne(prompt, &value); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		values[v.Name] = value
+	}
+	return values, nil
+}
+
+// renderPlaceholders replaces placeholders in every text file in dir.
+// Placeholders for unknown variables are left as is.
+func renderPlaceholders(dir string, values map[string]string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		// Skip binary files.
+		if bytes.IndexByte(data, 0) != -1 || !placeholderRegex.Match(data) {
+			return nil
+		}
+		rendered := placeholderRegex.ReplaceAllFunc(data, func(match []byte) []byte {
+			name := string(placeholderRegex.FindSubmatch(match)[1])
+			if value, ok := values[name]; ok {
+				return []byte(value)
+			}
+			return match
+		})
+		info, err := entry.Info()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(os.WriteFile(path, rendered, info.Mode().Perm()))
+	})
+}
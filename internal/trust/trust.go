@@ -0,0 +1,245 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+// Package trust records which projects the user has allowed devbox to activate.
+//
+// A project's devbox.json can set environment variables and run arbitrary
+// init_hook commands, so devbox only activates projects whose config the user
+// has trusted. Trust is keyed by the project directory and the hash of its
+// config file and the plugins and local flakes it uses, so any change to them
+// has to be trusted again.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.jetpack.io/devbox/internal/envir"
+	"go.jetpack.io/devbox/internal/xdg"
+)
+
+type Status int
+
+const (
+	// Untrusted means the project has never been trusted.
+	Untrusted Status = iota
+	// Trusted means the project's current config has been trusted.
+	Trusted
+	// Changed means the project was trusted, but its config has changed
+	// since.
+	Changed
+)
+
+// Message explains why a project with the given status isn't activated.
+func Message(status Status) string {
+	if status == Changed {
+		return "This project's devbox.json, or the plugins or local flakes it uses, " +
+			"have changed since you trusted it"
+	}
+	return "This project's devbox.json is not trusted"
+}
+
+// lockTimeout is how long to wait for another devbox command that is updating
+// the trusted projects.
+const lockTimeout = 5 * time.Second
+
+type entry struct {
+	Hash      string    `json:"hash"`
+	TrustedAt time.Time `json:"trusted_at"`
+}
+
+// db maps absolute project directories to their trusted config.
+type db map[string]entry
+
+// Hash returns the hash of the config file at path that trust is recorded
+// for. deps are the contents of what else the config runs, such as the plugins
+// and local flakes that it uses, so that changing them has to be trusted again
+// too.
+func Hash(configPath string, deps ...[]byte) (string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	sum := sha256.Sum256(data)
+	if len(deps) == 0 {
+		return hex.EncodeToString(sum[:]), nil
+	}
+
+	h := sha256.New()
+	h.Write(sum[:])
+	for _, dep := range deps {
+		// Prefix each dependency with its length so that moving bytes
+		// between them changes the hash.
+		_ = binary.Write(h, binary.BigEndian, uint64(len(dep)))
+		h.Write(dep)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// TrustAll reports whether the user trusts every project by setting
+// DEVBOX_TRUST_ALL, which is meant for CI and other throwaway environments.
+func TrustAll() bool {
+	trustAll, _ := strconv.ParseBool(os.Getenv(envir.DevboxTrustAll))
+	return trustAll
+}
+
+// Check returns whether the config with the given hash in projectDir is
+// trusted.
+func Check(projectDir, hash string) (Status, error) {
+	trusted, err := load()
+	if err != nil {
+		return Untrusted, err
+	}
+	e, ok := trusted[key(projectDir)]
+	switch {
+	case !ok:
+		return Untrusted, nil
+	case e.Hash != hash:
+		return Changed, nil
+	default:
+		return Trusted, nil
+	}
+}
+
+// Add trusts the config with the given hash in projectDir, replacing any
+// previously trusted config.
+func Add(projectDir, hash string) error {
+	return update(func(trusted db) bool {
+		trusted[key(projectDir)] = entry{Hash: hash, TrustedAt: time.Now().UTC()}
+		return true
+	})
+}
+
+// Remove revokes trust in projectDir. It returns false if the project wasn't
+// trusted.
+func Remove(projectDir string) (bool, error) {
+	removed := false
+	err := update(func(trusted db) bool {
+		k := key(projectDir)
+		if _, ok := trusted[k]; !ok {
+			return false
+		}
+		delete(trusted, k)
+		removed = true
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+	return removed, nil
+}
+
+func key(projectDir string) string {
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		projectDir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(projectDir); err == nil {
+		projectDir = resolved
+	}
+	return projectDir
+}
+
+// update loads the trusted projects, lets fn change them, and saves them if
+// fn returns true. The file is locked until it's saved, so that devbox
+// commands running at the same time don't undo each other's changes.
+func update(fn func(db) bool) error {
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	trusted, err := load()
+	if err != nil {
+		return err
+	}
+	if !fn(trusted) {
+		return nil
+	}
+	return save(trusted)
+}
+
+// lock takes an exclusive lock on the trusted projects, and returns the
+// function that releases it. The lock is on a separate file, since saving
+// replaces the trusted projects' file.
+func lock() (unlock func(), err error) {
+	path := dbPath() + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	locked := make(chan error, 1)
+	go func() {
+		locked <- syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+	}()
+	select {
+	case err := <-locked:
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrapf(err, "failed to lock %s", path)
+		}
+	case <-time.After(lockTimeout):
+		file.Close()
+		return nil, errors.Errorf("timed out after %s waiting for the lock on %s", lockTimeout, path)
+	}
+	// Closing the file releases the lock.
+	return func() { file.Close() }, nil
+}
+
+func load() (db, error) {
+	trusted := db{}
+	data, err := os.ReadFile(dbPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", dbPath())
+	}
+	return trusted, nil
+}
+
+func save(trusted db) error {
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	path := dbPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	// Write to a temporary file and rename it so that concurrent shell hooks
+	// never read a partially written file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp.Name(), path))
+}
+
+func dbPath() string {
+	return xdg.StateSubpath(filepath.FromSlash("devbox/trust.json"))
+}
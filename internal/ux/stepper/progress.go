@@ -0,0 +1,318 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package stepper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+
+	"go.jetpack.io/devbox/internal/envir"
+)
+
+// Mode is how a Progress reports its steps.
+type Mode string
+
+const (
+	// ModeSpinner shows a spinner that is updated in place. It is meant for
+	// terminals.
+	ModeSpinner Mode = "spinner"
+	// ModePlain prints a line when a step starts and ends, and occasional
+	// progress lines, which reads well in CI logs.
+	ModePlain Mode = "plain"
+	// ModeJSON prints one JSON Event per line for other tools to consume.
+	// Each line starts with EventPrefix, so that the events can be told apart
+	// from the other output they share a stream with.
+	ModeJSON Mode = "json"
+)
+
+// EventPrefix starts every line with an Event in ModeJSON, like the "@nix "
+// prefix of nix's structured logs.
+const EventPrefix = "@devbox "
+
+// plainInterval and jsonInterval limit how often progress is printed in the
+// non-interactive modes.
+const (
+	plainInterval = 5 * time.Second
+	jsonInterval  = time.Second
+)
+
+// DetectMode returns the mode set in DEVBOX_PROGRESS, or ModeSpinner if w is
+// a terminal and ModePlain otherwise.
+func DetectMode(w io.Writer) Mode {
+	switch mode := Mode(os.Getenv(envir.DevboxProgress)); mode {
+	case ModeSpinner, ModePlain, ModeJSON:
+		return mode
+	}
+	if f, ok := w.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		return ModeSpinner
+	}
+	return ModePlain
+}
+
+// Event is a change in a step's state. In ModeJSON each event is printed as a
+// line of JSON after EventPrefix.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Type is one of start, progress, log, success or fail.
+	Type  string `json:"type"`
+	Step  string `json:"step"`
+	Index int    `json:"index"`
+	Total int    `json:"total"`
+	// Activity describes what the step is currently doing, such as
+	// "downloading go-1.21.0".
+	Activity      string `json:"activity,omitempty"`
+	BytesDone     int64  `json:"bytes_done,omitempty"`
+	BytesExpected int64  `json:"bytes_expected,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// Progress reports the progress of a sequence of steps, such as installing
+// each package. Steps may run concurrently.
+type Progress struct {
+	w     io.Writer
+	mode  Mode
+	total int
+
+	// mu guards the Progress and all of its steps.
+	mu      sync.Mutex
+	started int
+	// running are the steps that have started but not ended, in the order
+	// they started.
+	running []*Step
+	// spinner is shared by the running steps in ModeSpinner, since a
+	// terminal can only show one spinner at a time.
+	spinner *Stepper
+}
+
+// NewProgress returns a Progress for total steps that reports to w in the
+// mode returned by DetectMode.
+func NewProgress(w io.Writer, total int) *Progress {
+	return NewProgressWithMode(w, total, DetectMode(w))
+}
+
+func NewProgressWithMode(w io.Writer, total int, mode Mode) *Progress {
+	return &Progress{w: w, mode: mode, total: total}
+}
+
+// Start starts the next step.
+func (p *Progress) Start(name string) *Step {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.started++
+	s := &Step{progress: p, name: name, index: p.started}
+	p.running = append(p.running, s)
+	switch p.mode {
+	case ModeSpinner:
+		p.display(s)
+	case ModePlain:
+		fmt.Fprintf(p.w, "%s\n", s.label())
+	case ModeJSON:
+		s.emitLocked(&Event{Type: "start"})
+	}
+	return s
+}
+
+// display shows s in the spinner, starting the spinner if it isn't running.
+func (p *Progress) display(s *Step) {
+	msg := s.label()
+	if status := s.status(); status != "" {
+		msg += ": " + status
+	}
+	if others := len(p.running) - 1; others > 0 {
+		msg += fmt.Sprintf(" (+%d more)", others)
+	}
+	if p.spinner == nil {
+		p.spinner = Start(p.w, "%s", msg)
+		return
+	}
+	p.spinner.Display("%s", msg)
+}
+
+// Step is a single step of a Progress. It's safe to update it from multiple
+// goroutines.
+type Step struct {
+	progress *Progress
+	name     string
+	index    int
+
+	activity      string
+	bytesDone     int64
+	bytesExpected int64
+	lastEmit      time.Time
+	// logs are held back in spinner mode so that they don't get mixed with
+	// the spinner, and printed when the step ends.
+	logs []string
+}
+
+// Update sets what the step is doing and how many bytes it has downloaded.
+func (s *Step) Update(activity string, bytesDone, bytesExpected int64) {
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+
+	s.activity = activity
+	s.bytesDone = bytesDone
+	s.bytesExpected = bytesExpected
+	switch s.progress.mode {
+	case ModeSpinner:
+		s.progress.display(s)
+	case ModePlain:
+		if time.Since(s.lastEmit) >= plainInterval {
+			s.lastEmit = time.Now()
+			fmt.Fprintf(s.progress.w, "%s: %s\n", s.label(), s.status())
+		}
+	case ModeJSON:
+		if time.Since(s.lastEmit) >= jsonInterval {
+			s.lastEmit = time.Now()
+			s.emitLocked(&Event{Type: "progress"})
+		}
+	}
+}
+
+// Bytes returns the bytes the step has downloaded and expects to download.
+func (s *Step) Bytes() (done, expected int64) {
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+	return s.bytesDone, s.bytesExpected
+}
+
+// Log reports a message, such as a warning or error printed by nix.
+func (s *Step) Log(msg string) {
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+
+	switch s.progress.mode {
+	case ModeSpinner:
+		s.logs = append(s.logs, msg)
+	case ModePlain:
+		fmt.Fprintf(s.progress.w, "\t%s\n", msg)
+	case ModeJSON:
+		s.emitLocked(&Event{Type: "log", Message: msg})
+	}
+}
+
+// Success ends the step successfully.
+func (s *Step) Success() {
+	s.end("success", "")
+}
+
+// Fail ends the step with an error message.
+func (s *Step) Fail(msg string) {
+	s.end("fail", msg)
+}
+
+func (s *Step) end(eventType, msg string) {
+	p := s.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idx := -1
+	for i, running := range p.running {
+		if running == s {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		// The step already ended.
+		return
+	}
+	p.running = append(p.running[:idx], p.running[idx+1:]...)
+
+	summary := s.label()
+	if s.bytesDone > 0 {
+		summary += fmt.Sprintf(" (%s downloaded)", FormatBytes(s.bytesDone))
+	}
+	switch p.mode {
+	case ModeSpinner:
+		// Stopping the spinner prints the step's final line. The spinner is
+		// restarted for the steps that are still running.
+		if eventType == "success" {
+			p.spinner.Success("%s", summary)
+		} else {
+			p.spinner.Fail("%s", summary)
+		}
+		p.spinner = nil
+		for _, log := range s.logs {
+			fmt.Fprintf(p.w, "\t%s\n", log)
+		}
+		if msg != "" {
+			fmt.Fprintf(p.w, "\t%s\n", strings.ReplaceAll(msg, "\n", "\n\t"))
+		}
+		if len(p.running) > 0 {
+			p.display(p.running[len(p.running)-1])
+		}
+	case ModePlain:
+		fmt.Fprintf(p.w, "%s: ", summary)
+		if eventType == "success" {
+			color.New(color.FgGreen).Fprintf(p.w, "Success\n")
+		} else {
+			color.New(color.FgRed).Fprintf(p.w, "Fail\n")
+		}
+		if msg != "" {
+			fmt.Fprintf(p.w, "\t%s\n", strings.ReplaceAll(msg, "\n", "\n\t"))
+		}
+	case ModeJSON:
+		s.emitLocked(&Event{Type: eventType, Message: msg})
+	}
+}
+
+// emitLocked prints e as JSON with the step's current state, after
+// EventPrefix. The progress mutex must be held.
+func (s *Step) emitLocked(e *Event) {
+	e.Time = time.Now().UTC()
+	e.Step = s.name
+	e.Index = s.index
+	e.Total = s.progress.total
+	e.Activity = s.activity
+	e.BytesDone = s.bytesDone
+	e.BytesExpected = s.bytesExpected
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(s.progress.w, "%s%s\n", EventPrefix, data)
+}
+
+func (s *Step) label() string {
+	if s.progress.total <= 1 {
+		return s.name
+	}
+	return fmt.Sprintf("[%d/%d] %s", s.index, s.progress.total, s.name)
+}
+
+func (s *Step) status() string {
+	var parts []string
+	if s.activity != "" {
+		parts = append(parts, s.activity)
+	}
+	if s.bytesExpected > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s",
+			FormatBytes(s.bytesDone), FormatBytes(s.bytesExpected)))
+	} else if s.bytesDone > 0 {
+		parts = append(parts, FormatBytes(s.bytesDone))
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatBytes formats n as a human readable size, such as 12.3 MiB.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 Jetpack Technologies Inc and contributors. All rights reserved.
+// Use of this source code is governed by the license in the LICENSE file.
+
+package stepper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressPlain(t *testing.T) {
+	color.NoColor = true
+	out := &bytes.Buffer{}
+	progress := NewProgressWithMode(out, 2, ModePlain)
+
+	step := progress.Start("go@1.21")
+	step.Update("fetching go-1.21.0", 1024, 2048)
+	step.Update("fetching go-1.21.0", 2048, 2048)
+	step.Success()
+
+	step = progress.Start("broken@1.0")
+	step.Log("warning: something")
+	step.Fail("error: build failed")
+
+	require.Equal(t, `[1/2] go@1.21
+[1/2] go@1.21: fetching go-1.21.0 1.0 KiB/2.0 KiB
+[1/2] go@1.21 (2.0 KiB downloaded): Success
+[2/2] broken@1.0
+	warning: something
+[2/2] broken@1.0: Fail
+	error: build failed
+`, out.String())
+}
+
+func TestFormatBytes(t *testing.T) {
+	require.Equal(t, "512 B", FormatBytes(512))
+	require.Equal(t, "1.5 KiB", FormatBytes(1536))
+	require.Equal(t, "12.0 MiB", FormatBytes(12<<20))
+	require.Equal(t, "2.0 GiB", FormatBytes(2<<30))
+}
+
+func TestProgressConcurrentSteps(t *testing.T) {
+	out := &bytes.Buffer{}
+	progress := NewProgressWithMode(out, 8, ModeJSON)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			step := progress.Start(fmt.Sprintf("pkg%d", i))
+			step.Update("fetching", int64(i), 8)
+			step.Success()
+		}(i)
+	}
+	wg.Wait()
+
+	counts := map[string]int{}
+	indexes := map[int]bool{}
+	s := bufio.NewScanner(out)
+	for s.Scan() {
+		data, ok := bytes.CutPrefix(s.Bytes(), []byte(EventPrefix))
+		require.True(t, ok, "event without prefix: %s", s.Text())
+		e := &Event{}
+		require.NoError(t, json.Unmarshal(data, e))
+		counts[e.Type]++
+		indexes[e.Index] = true
+		require.Equal(t, 8, e.Total)
+	}
+	require.Equal(t, 8, counts["start"])
+	require.Equal(t, 8, counts["success"])
+	require.Len(t, indexes, 8)
+	require.Empty(t, progress.running)
+}
+
+func TestProgressJSONSharesStream(t *testing.T) {
+	out := &bytes.Buffer{}
+	progress := NewProgressWithMode(out, 1, ModeJSON)
+
+	fmt.Fprintln(out, "Installing package: go@1.21.")
+	step := progress.Start("go@1.21")
+	fmt.Fprintln(out, `{"not": "an event"}`)
+	step.Fail("error: build failed")
+
+	var events []*Event
+	s := bufio.NewScanner(out)
+	for s.Scan() {
+		data, ok := bytes.CutPrefix(s.Bytes(), []byte(EventPrefix))
+		if !ok {
+			continue
+		}
+		e := &Event{}
+		require.NoError(t, json.Unmarshal(data, e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	require.Equal(t, "start", events[0].Type)
+	require.Equal(t, "fail", events[1].Type)
+	require.Equal(t, "error: build failed", events[1].Message)
+}
+
+func TestProgressEndedStepIgnored(t *testing.T) {
+	color.NoColor = true
+	out := &bytes.Buffer{}
+	progress := NewProgressWithMode(out, 2, ModePlain)
+
+	first := progress.Start("go@1.21")
+	second := progress.Start("nodejs@18")
+	first.Fail("error: collision")
+	for _, step := range []*Step{first, second} {
+		step.Fail("not added")
+	}
+
+	require.Equal(t, `[1/2] go@1.21
+[2/2] nodejs@18
+[1/2] go@1.21: Fail
+	error: collision
+[2/2] nodejs@18: Fail
+	not added
+`, out.String())
+	require.Empty(t, progress.running)
+}
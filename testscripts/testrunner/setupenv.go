@@ -23,6 +23,9 @@ func setupTestEnv(t *testing.T, envs *testscript.Env) error {
 	}
 
 	envs.Setenv(envir.DevboxDebug, os.Getenv(envir.DevboxDebug))
+	// The test projects are written by the tests, not by someone else, so
+	// devbox run and devbox shell don't need to ask whether to trust them.
+	envs.Setenv(envir.DevboxTrustAll, "1")
 	return nil
 }
 